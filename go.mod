@@ -1,14 +1,12 @@
 module github.com/cybergarage/go-sasl
 
-go 1.25
+go 1.25.0
 
 require (
 	github.com/cybergarage/go-safecast v1.3.5
 	github.com/xdg-go/pbkdf2 v1.0.0
 	github.com/xdg-go/scram v1.1.2
+	github.com/xdg-go/stringprep v1.0.4
 )
 
-require (
-	github.com/xdg-go/stringprep v1.0.4 // indirect
-	golang.org/x/text v0.3.8 // indirect
-)
+require golang.org/x/text v0.40.0 // indirect
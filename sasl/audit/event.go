@@ -0,0 +1,67 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"time"
+)
+
+// EventType represents an audit event type.
+type EventType string
+
+const (
+	// MechanismSelected is the event type of a mechanism selected by the client, which starts or resumes an exchange.
+	MechanismSelected EventType = "mechanism-selected"
+	// StepCompleted is the event type of a step completed without an error.
+	StepCompleted EventType = "step-completed"
+	// Succeeded is the event type of an exchange completed successfully.
+	Succeeded EventType = "succeeded"
+	// Failed is the event type of an exchange failed or aborted.
+	Failed EventType = "failed"
+)
+
+// Event represents an audit event of an authentication exchange.
+// The event has no fields for sensitive data such as passwords, proofs, tokens, messages of exchanges or mechanism specific attributes,
+// and only the public message of a failure is included instead of the underlying error.
+type Event struct {
+	// Type is the event type.
+	Type EventType `json:"type"`
+	// Time is the time when the event occurred.
+	Time time.Time `json:"time"`
+	// ID is the exchange identifier to correlate the events of an exchange.
+	ID string `json:"id"`
+	// Mechanism is the mechanism name.
+	Mechanism string `json:"mechanism"`
+	// RemoteAddr is the remote network address of the connection, if known.
+	RemoteAddr string `json:"remote_addr,omitempty"`
+	// Step is the step number of the event.
+	Step int `json:"step"`
+	// Duration is the duration of the step for StepCompleted events, and the duration of the exchange for the other events.
+	Duration time.Duration `json:"duration"`
+	// AuthcID is the authentication identity of Succeeded events.
+	AuthcID string `json:"authcid,omitempty"`
+	// AuthzID is the authorization identity of Succeeded events.
+	AuthzID string `json:"authzid,omitempty"`
+	// Realm is the realm of the authentication identity of Succeeded events.
+	Realm string `json:"realm,omitempty"`
+	// SSF is the security strength factor of the negotiated security layer of Succeeded events.
+	SSF int `json:"ssf,omitempty"`
+	// ChannelBinding is the channel binding type of Succeeded events.
+	ChannelBinding string `json:"channel_binding,omitempty"`
+	// Reason is the failure reason of Failed events.
+	Reason string `json:"reason,omitempty"`
+	// Message is the public failure message of Failed events, which is safe to send to clients.
+	Message string `json:"message,omitempty"`
+}
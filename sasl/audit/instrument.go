@@ -0,0 +1,50 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"github.com/cybergarage/go-sasl/sasl/instrument"
+)
+
+type instrumentationObserver struct {
+	instrumentation instrument.Instrumentation
+}
+
+// NewInstrumentationObserver returns a new observer which passes the measurements of the completed or failed exchanges to the instrumentation.
+// The result of a failed exchange is the failure reason.
+func NewInstrumentationObserver(i instrument.Instrumentation) Observer {
+	return &instrumentationObserver{
+		instrumentation: i,
+	}
+}
+
+// Observe passes the measurement of the exchange to the instrumentation if the event completes the exchange.
+func (observer *instrumentationObserver) Observe(event *Event) {
+	result := instrument.Success
+	switch event.Type {
+	case Succeeded:
+	case Failed:
+		result = event.Reason
+	default:
+		return
+	}
+	observer.instrumentation.Measure(&instrument.Measurement{
+		Operation: instrument.Exchange,
+		Mechanism: event.Mechanism,
+		Start:     event.Time.Add(-event.Duration),
+		Duration:  event.Duration,
+		Result:    result,
+	})
+}
@@ -0,0 +1,60 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// JSONObserver represents an observer which writes the events to a writer as JSON lines.
+type JSONObserver struct {
+	sync.Mutex
+	w   io.Writer
+	err error
+}
+
+// NewJSONObserver returns a new observer which writes each event to the writer as a line of JSON.
+func NewJSONObserver(w io.Writer) *JSONObserver {
+	return &JSONObserver{
+		Mutex: sync.Mutex{},
+		w:     w,
+		err:   nil,
+	}
+}
+
+// Observe writes the event to the writer.
+func (observer *JSONObserver) Observe(event *Event) {
+	b, err := json.Marshal(event)
+	if err == nil {
+		b = append(b, '\n')
+	}
+	observer.Lock()
+	defer observer.Unlock()
+	if err == nil {
+		_, err = observer.w.Write(b)
+	}
+	if err != nil && observer.err == nil {
+		observer.err = err
+	}
+}
+
+// Err returns the first error which occurred while writing the events, or nil.
+func (observer *JSONObserver) Err() error {
+	observer.Lock()
+	defer observer.Unlock()
+	return observer.err
+}
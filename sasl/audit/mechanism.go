@@ -0,0 +1,247 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/cybergarage/go-sasl/sasl/auth"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasl/util/rand"
+)
+
+const idLength = 8
+
+// Mechanism represents a mechanism which reports the audit events of the contexts to an observer.
+// Use mech.MechanismAs or Unwrap to access the mechanism specific methods of the underlying mechanism.
+type Mechanism struct {
+	mech.Mechanism
+	observer Observer
+}
+
+// resumableMechanism represents a mechanism which reports the audit events of a resumable underlying mechanism.
+type resumableMechanism struct {
+	*Mechanism
+}
+
+// NewMechanism returns a new mechanism which reports the audit events of the contexts started by the mechanism to the observer.
+// The returned mechanism implements mech.ResumableMechanism only if the underlying mechanism implements it.
+func NewMechanism(m mech.Mechanism, observer Observer) mech.Mechanism {
+	auditMech := &Mechanism{
+		Mechanism: m,
+		observer:  observer,
+	}
+	if _, ok := m.(mech.ResumableMechanism); ok {
+		return &resumableMechanism{Mechanism: auditMech}
+	}
+	return auditMech
+}
+
+// Unwrap returns the underlying mechanism.
+func (m *Mechanism) Unwrap() mech.Mechanism {
+	return m.Mechanism
+}
+
+// Start returns a new context which reports the audit events.
+func (m *Mechanism) Start(opts ...mech.Option) (mech.Context, error) {
+	ctx, err := m.Mechanism.Start(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return newContext(m, ctx, opts...), nil
+}
+
+// Unwrap returns the audit mechanism of the resumable mechanism.
+func (m *resumableMechanism) Unwrap() mech.Mechanism {
+	return m.Mechanism
+}
+
+// Resume returns the suspended context of the underlying mechanism which reports the audit events.
+func (m *resumableMechanism) Resume(sealer mech.TokenSealer, token []byte, opts ...mech.Option) (mech.Context, error) {
+	resumable, _ := m.Mechanism.Mechanism.(mech.ResumableMechanism)
+	ctx, err := resumable.Resume(sealer, token, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return newContext(m.Mechanism, ctx, opts...), nil
+}
+
+// Context represents a context which reports the audit events to the observer.
+// Use mech.ContextAs or Unwrap to access the mechanism specific methods of the underlying context.
+type Context struct {
+	mech.Context
+	mechanism  *Mechanism
+	id         string
+	remoteAddr string
+	started    time.Time
+	completed  bool
+}
+
+// suspendableContext represents a context which reports the audit events of a suspendable underlying context.
+type suspendableContext struct {
+	*Context
+}
+
+// newContext returns a new context which implements mech.SuspendableContext only if the underlying context implements it.
+func newContext(m *Mechanism, ctx mech.Context, opts ...mech.Option) mech.Context {
+	auditCtx := &Context{
+		Context:    ctx,
+		mechanism:  m,
+		id:         "",
+		remoteAddr: "",
+		started:    time.Now(),
+		completed:  false,
+	}
+	if id, err := rand.NewSalt(idLength); err == nil {
+		auditCtx.id = hex.EncodeToString(id)
+	}
+	for _, opt := range opts {
+		if conn, ok := opt.(auth.Conn); ok && conn != nil {
+			if addr := conn.RemoteAddr(); addr != nil {
+				auditCtx.remoteAddr = addr.String()
+			}
+		}
+	}
+	auditCtx.observe(auditCtx.newEvent(MechanismSelected, auditCtx.started))
+	if _, ok := ctx.(mech.SuspendableContext); ok {
+		return &suspendableContext{Context: auditCtx}
+	}
+	return auditCtx
+}
+
+// Unwrap returns the underlying context to access the mechanism specific methods.
+func (ctx *Context) Unwrap() mech.Context {
+	return ctx.Context
+}
+
+// ID returns the exchange identifier of the audit events.
+func (ctx *Context) ID() string {
+	return ctx.id
+}
+
+// Next returns the next response of the underlying context, and reports the audit events of the step.
+func (ctx *Context) Next(opts ...mech.Parameter) (mech.Response, error) {
+	step := ctx.Step()
+	now := time.Now()
+	res, err := ctx.Context.Next(opts...)
+	if ctx.completed {
+		return res, err
+	}
+	if err != nil {
+		ctx.completed = true
+		event := ctx.newEvent(Failed, ctx.started)
+		event.Step = step
+		event.Reason, event.Message = reasonOf(err)
+		ctx.observe(event)
+		return res, err
+	}
+
+	event := ctx.newEvent(StepCompleted, now)
+	event.Step = step
+	ctx.observe(event)
+
+	if ctx.Done() {
+		ctx.completed = true
+		event := ctx.newEvent(Succeeded, ctx.started)
+		if result, ok := ctx.AuthResult(); ok {
+			event.AuthcID = result.AuthcID()
+			event.AuthzID = result.AuthzID()
+			event.Realm = result.Realm()
+			event.SSF = result.SSF()
+			event.ChannelBinding = result.ChannelBinding()
+		}
+		ctx.observe(event)
+	}
+
+	return res, err
+}
+
+// AuthResult returns the authentication result of the underlying context, or false if the context has no result.
+func (ctx *Context) AuthResult() (mech.AuthResult, bool) {
+	resultCtx, ok := ctx.Context.(mech.AuthResultContext)
+	if !ok {
+		return nil, false
+	}
+	return resultCtx.AuthResult()
+}
+
+// AuthorizedID returns the authorized identity of the underlying context, or an empty string if the context has no identity.
+func (ctx *Context) AuthorizedID() string {
+	authzCtx, ok := ctx.Context.(mech.AuthorizedContext)
+	if !ok {
+		return ""
+	}
+	return authzCtx.AuthorizedID()
+}
+
+// Unwrap returns the audit context of the suspendable context.
+func (ctx *suspendableContext) Unwrap() mech.Context {
+	return ctx.Context
+}
+
+// Suspend returns a token of the underlying context.
+func (ctx *suspendableContext) Suspend(sealer mech.TokenSealer) ([]byte, error) {
+	suspendable, _ := ctx.Context.Context.(mech.SuspendableContext)
+	return suspendable.Suspend(sealer)
+}
+
+// Dispose disposes the underlying context, and reports an aborted exchange if the context is not completed.
+func (ctx *Context) Dispose() error {
+	if !ctx.completed {
+		ctx.completed = true
+		event := ctx.newEvent(Failed, ctx.started)
+		event.Reason = mech.ReasonAborted.String()
+		ctx.observe(event)
+	}
+	return ctx.Context.Dispose()
+}
+
+func (ctx *Context) newEvent(t EventType, since time.Time) *Event {
+	now := time.Now()
+	return &Event{
+		Type:           t,
+		Time:           now,
+		ID:             ctx.id,
+		Mechanism:      ctx.mechanism.Name(),
+		RemoteAddr:     ctx.remoteAddr,
+		Step:           ctx.Step(),
+		Duration:       now.Sub(since),
+		AuthcID:        "",
+		AuthzID:        "",
+		Realm:          "",
+		SSF:            0,
+		ChannelBinding: "",
+		Reason:         "",
+		Message:        "",
+	}
+}
+
+func (ctx *Context) observe(event *Event) {
+	if ctx.mechanism.observer != nil {
+		ctx.mechanism.observer.Observe(event)
+	}
+}
+
+// reasonOf returns the reason and the public message of the error without the underlying error which may include sensitive data.
+func reasonOf(err error) (string, string) {
+	reason, _ := mech.ReasonOf(err)
+	var mechErr *mech.Error
+	if !errors.As(err, &mechErr) {
+		return reason.String(), ""
+	}
+	return reason.String(), mechErr.Message()
+}
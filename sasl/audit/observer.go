@@ -0,0 +1,44 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+// Observer represents an observer which receives the audit events of authentication exchanges.
+// Observe is called synchronously from the exchange, and must be safe for concurrent use.
+type Observer interface {
+	// Observe receives an audit event.
+	Observe(event *Event)
+}
+
+// ObserverFunc is an adapter to use an ordinary function as an observer.
+type ObserverFunc func(event *Event)
+
+// Observe calls the function.
+func (fn ObserverFunc) Observe(event *Event) {
+	fn(event)
+}
+
+type multiObserver []Observer
+
+// NewMultiObserver returns a new observer which passes the events to all the specified observers.
+func NewMultiObserver(observers ...Observer) Observer {
+	return multiObserver(observers)
+}
+
+// Observe passes the event to all the observers.
+func (observers multiObserver) Observe(event *Event) {
+	for _, observer := range observers {
+		observer.Observe(event)
+	}
+}
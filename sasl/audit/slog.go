@@ -0,0 +1,72 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogObserver represents an observer which writes the events to a structured logger.
+type SlogObserver struct {
+	logger *slog.Logger
+}
+
+// NewSlogObserver returns a new observer which writes the events to the logger.
+// The failed events are written at the warning level, and the other events at the info level.
+func NewSlogObserver(logger *slog.Logger) *SlogObserver {
+	return &SlogObserver{
+		logger: logger,
+	}
+}
+
+// Observe writes the event to the logger.
+func (observer *SlogObserver) Observe(event *Event) {
+	level := slog.LevelInfo
+	if event.Type == Failed {
+		level = slog.LevelWarn
+	}
+	attrs := []slog.Attr{
+		slog.String("id", event.ID),
+		slog.String("mechanism", event.Mechanism),
+		slog.Int("step", event.Step),
+		slog.Duration("duration", event.Duration),
+	}
+	if 0 < len(event.RemoteAddr) {
+		attrs = append(attrs, slog.String("remote_addr", event.RemoteAddr))
+	}
+	switch event.Type {
+	case Succeeded:
+		attrs = append(attrs,
+			slog.String("authcid", event.AuthcID),
+			slog.String("authzid", event.AuthzID),
+		)
+		if 0 < len(event.Realm) {
+			attrs = append(attrs, slog.String("realm", event.Realm))
+		}
+		if 0 < event.SSF {
+			attrs = append(attrs, slog.Int("ssf", event.SSF))
+		}
+		if 0 < len(event.ChannelBinding) {
+			attrs = append(attrs, slog.String("channel_binding", event.ChannelBinding))
+		}
+	case Failed:
+		attrs = append(attrs, slog.String("reason", event.Reason))
+		if 0 < len(event.Message) {
+			attrs = append(attrs, slog.String("message", event.Message))
+		}
+	}
+	observer.logger.LogAttrs(context.Background(), level, "sasl "+string(event.Type), attrs...)
+}
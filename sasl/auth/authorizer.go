@@ -0,0 +1,31 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+// Authorizer is the interface for authorizing an authenticated identity to act as an authorization identity.
+type Authorizer interface {
+	// Authorize returns nil if the authenticated identity (authcid) is allowed to act as the authorization identity (authzid).
+	// Authorize is invoked after successful authentication, and an empty authzid means the authcid itself.
+	Authorize(conn Conn, authcid string, authzid string, mech string) error
+}
+
+// AuthorizedID returns the identity which is associated with the connection after successful authorization.
+// The identity is the authorization identity if specified, otherwise the authentication identity.
+func AuthorizedID(authcid string, authzid string) string {
+	if 0 < len(authzid) {
+		return authzid
+	}
+	return authcid
+}
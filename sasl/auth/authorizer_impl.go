@@ -0,0 +1,87 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// AuthorizerRule represents a rule which returns true if the authenticated identity is allowed to act as the authorization identity.
+type AuthorizerRule func(conn Conn, authcid string, authzid string, mech string) bool
+
+type ruleAuthorizer struct {
+	rules []AuthorizerRule
+}
+
+// NewDefaultAuthorizer returns a new default authorizer which denies any authorization identity other than the authentication identity.
+func NewDefaultAuthorizer() Authorizer {
+	return NewRuleAuthorizer()
+}
+
+// NewRuleAuthorizer returns a new authorizer which allows the authorization identity if it is the authentication identity
+// or any of the specified rules allows it, and denies it otherwise.
+func NewRuleAuthorizer(rules ...AuthorizerRule) Authorizer {
+	return &ruleAuthorizer{
+		rules: rules,
+	}
+}
+
+// AllowAdmins returns a rule which allows the specified authentication identities to act as any authorization identity.
+func AllowAdmins(admins ...string) AuthorizerRule {
+	return func(conn Conn, authcid string, authzid string, mech string) bool {
+		return slices.Contains(admins, authcid)
+	}
+}
+
+// AllowAdminGroups returns a rule which allows the authentication identities belonging to any of the specified admin groups
+// to act as any authorization identity. The groups of an authentication identity are returned by the groups function.
+func AllowAdminGroups(groups func(authcid string) []string, adminGroups ...string) AuthorizerRule {
+	return func(conn Conn, authcid string, authzid string, mech string) bool {
+		for _, group := range groups(authcid) {
+			if slices.Contains(adminGroups, group) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AllowRegexpMapping returns a rule which allows an authentication identity matching the pattern to act as
+// the authorization identity produced by expanding the template with the submatches, such as "$1" for "^(.+)@example\.com$".
+func AllowRegexpMapping(pattern *regexp.Regexp, template string) AuthorizerRule {
+	return func(conn Conn, authcid string, authzid string, mech string) bool {
+		submatches := pattern.FindStringSubmatchIndex(authcid)
+		if submatches == nil {
+			return false
+		}
+		mapped := pattern.ExpandString(nil, template, authcid, submatches)
+		return string(mapped) == authzid
+	}
+}
+
+// Authorize returns nil if the authenticated identity (authcid) is allowed to act as the authorization identity (authzid).
+func (authz *ruleAuthorizer) Authorize(conn Conn, authcid string, authzid string, mech string) error {
+	if len(authzid) == 0 || authzid == authcid {
+		return nil
+	}
+	for _, rule := range authz.rules {
+		if rule(conn, authcid, authzid, mech) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w : %s as %s", ErrAuthorizationDenied, authcid, authzid)
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+func TestAuthorizer(t *testing.T) {
+	groups := func(authcid string) []string {
+		if authcid == "carol" {
+			return []string{"staff", "wheel"}
+		}
+		return []string{"staff"}
+	}
+
+	tests := []struct {
+		name    string
+		authz   Authorizer
+		authcid string
+		authzid string
+		allowed bool
+	}{
+		{"default-self", NewDefaultAuthorizer(), "alice", "", true},
+		{"default-same", NewDefaultAuthorizer(), "alice", "alice", true},
+		{"default-other", NewDefaultAuthorizer(), "alice", "bob", false},
+		{"admin", NewRuleAuthorizer(AllowAdmins("root")), "root", "bob", true},
+		{"non-admin", NewRuleAuthorizer(AllowAdmins("root")), "alice", "bob", false},
+		{"admin-group", NewRuleAuthorizer(AllowAdminGroups(groups, "wheel")), "carol", "bob", true},
+		{"non-admin-group", NewRuleAuthorizer(AllowAdminGroups(groups, "wheel")), "alice", "bob", false},
+		{"regexp", NewRuleAuthorizer(AllowRegexpMapping(regexp.MustCompile(`^(.+)@example\.com$`), "$1")), "alice@example.com", "alice", true},
+		{"regexp-other", NewRuleAuthorizer(AllowRegexpMapping(regexp.MustCompile(`^(.+)@example\.com$`), "$1")), "alice@example.com", "bob", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.authz.Authorize(nil, test.authcid, test.authzid, "PLAIN")
+			if test.allowed {
+				if err != nil {
+					t.Error(err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrAuthorizationDenied) {
+				t.Errorf("Authorize(%s, %s) = %v, want %v", test.authcid, test.authzid, err, ErrAuthorizationDenied)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+// CredentialCacheStats represents the statistics of a credential cache.
+type CredentialCacheStats struct {
+	// Hits is the number of lookups answered by a cached credential.
+	Hits uint64
+	// NegativeHits is the number of lookups answered by a cached unknown user.
+	NegativeHits uint64
+	// Misses is the number of lookups forwarded to the underlying credential store.
+	Misses uint64
+	// Shared is the number of lookups which waited for a concurrent lookup of the same key.
+	Shared uint64
+	// Evictions is the number of entries evicted to keep the cache within its size.
+	Evictions uint64
+	// Entries is the number of entries currently in the cache.
+	Entries int
+}
+
+// CredentialCache represents a credential store which caches the credentials of another credential store.
+type CredentialCache interface {
+	// CredentialStore represents the caching credential store.
+	CredentialStore
+	// Invalidate removes the cached entry for the specified query.
+	Invalidate(q Query)
+	// InvalidateAll removes all cached entries.
+	InvalidateAll()
+	// Stats returns the cache statistics.
+	Stats() CredentialCacheStats
+}
@@ -0,0 +1,258 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCredentialCacheTTL is the default lifetime of a cached credential.
+	DefaultCredentialCacheTTL = 5 * time.Minute
+	// DefaultCredentialCacheNegativeTTL is the default lifetime of a cached unknown user.
+	DefaultCredentialCacheNegativeTTL = 30 * time.Second
+	// DefaultCredentialCacheSize is the default maximum number of cached entries.
+	DefaultCredentialCacheSize = 1024
+)
+
+// CredentialCacheKeyFunc represents a function which returns the cache key of a query.
+type CredentialCacheKeyFunc func(q Query) string
+
+type credCacheEntry struct {
+	key       string
+	cred      Credential
+	found     bool
+	err       error
+	expiresAt time.Time
+}
+
+type credCacheCall struct {
+	wg    sync.WaitGroup
+	cred  Credential
+	found bool
+	err   error
+	stale bool
+}
+
+type credCache struct {
+	sync.Mutex
+	store       CredentialStore
+	ttl         time.Duration
+	negativeTTL time.Duration
+	size        int
+	keyFunc     CredentialCacheKeyFunc
+	clock       func() time.Time
+	entries     map[string]*list.Element
+	lru         *list.List
+	calls       map[string]*credCacheCall
+	stats       CredentialCacheStats
+}
+
+// CredentialCacheOptionFn represents an option for a credential cache.
+type CredentialCacheOptionFn func(*credCache)
+
+// NewCredentialCache returns a new credential cache for the specified credential store with options.
+func NewCredentialCache(store CredentialStore, opts ...CredentialCacheOptionFn) CredentialCache {
+	cache := &credCache{
+		Mutex:       sync.Mutex{},
+		store:       store,
+		ttl:         DefaultCredentialCacheTTL,
+		negativeTTL: DefaultCredentialCacheNegativeTTL,
+		size:        DefaultCredentialCacheSize,
+		keyFunc:     DefaultCredentialCacheKey,
+		clock:       time.Now,
+		entries:     map[string]*list.Element{},
+		lru:         list.New(),
+		calls:       map[string]*credCacheCall{},
+		stats:       CredentialCacheStats{},
+	}
+	for _, opt := range opts {
+		opt(cache)
+	}
+	return cache
+}
+
+// WithCredentialCacheTTL returns an option to set the lifetime of cached credentials.
+// A non-positive duration disables caching of found credentials.
+func WithCredentialCacheTTL(ttl time.Duration) CredentialCacheOptionFn {
+	return func(cache *credCache) {
+		cache.ttl = ttl
+	}
+}
+
+// WithCredentialCacheNegativeTTL returns an option to set the lifetime of cached unknown users.
+// A non-positive duration disables negative caching.
+func WithCredentialCacheNegativeTTL(ttl time.Duration) CredentialCacheOptionFn {
+	return func(cache *credCache) {
+		cache.negativeTTL = ttl
+	}
+}
+
+// WithCredentialCacheSize returns an option to set the maximum number of cached entries.
+// The least recently used entry is evicted when the cache is full.
+func WithCredentialCacheSize(size int) CredentialCacheOptionFn {
+	return func(cache *credCache) {
+		cache.size = size
+	}
+}
+
+// WithCredentialCacheKeyFunc returns an option to set the function which returns the cache key of a query.
+func WithCredentialCacheKeyFunc(fn CredentialCacheKeyFunc) CredentialCacheOptionFn {
+	return func(cache *credCache) {
+		cache.keyFunc = fn
+	}
+}
+
+// WithCredentialCacheClock returns an option to set the clock used to expire cached entries.
+func WithCredentialCacheClock(clock func() time.Time) CredentialCacheOptionFn {
+	return func(cache *credCache) {
+		cache.clock = clock
+	}
+}
+
+// DefaultCredentialCacheKey returns the default cache key of the query which consists of the group, username and mechanism.
+func DefaultCredentialCacheKey(q Query) string {
+	return q.Group() + "\x00" + q.Username() + "\x00" + q.Mechanism()
+}
+
+// LookupCredential looks up a credential by the given query from the cache,
+// or from the underlying credential store if the query is not cached.
+// Concurrent lookups of the same key share a single lookup of the underlying credential store.
+func (cache *credCache) LookupCredential(q Query) (Credential, bool, error) {
+	key := cache.keyFunc(q)
+
+	cache.Lock()
+	if elem, ok := cache.entries[key]; ok {
+		entry, _ := elem.Value.(*credCacheEntry)
+		if cache.clock().Before(entry.expiresAt) {
+			cache.lru.MoveToFront(elem)
+			if entry.found {
+				cache.stats.Hits++
+			} else {
+				cache.stats.NegativeHits++
+			}
+			cache.Unlock()
+			return entry.cred, entry.found, entry.err
+		}
+		cache.removeElement(elem)
+	}
+	if call, ok := cache.calls[key]; ok {
+		cache.stats.Shared++
+		cache.Unlock()
+		call.wg.Wait()
+		return call.cred, call.found, call.err
+	}
+	call := &credCacheCall{}
+	call.wg.Add(1)
+	cache.calls[key] = call
+	cache.stats.Misses++
+	cache.Unlock()
+
+	cache.lookup(key, call, q)
+	return call.cred, call.found, call.err
+}
+
+// lookup looks up a credential from the underlying credential store and releases the waiters of the call,
+// even if the credential store panics.
+func (cache *credCache) lookup(key string, call *credCacheCall, q Query) {
+	completed := false
+	defer func() {
+		if !completed {
+			call.cred, call.found, call.err = nil, false, ErrCredentialLookupPanic
+			call.stale = true
+		}
+		cache.Lock()
+		delete(cache.calls, key)
+		if !call.stale {
+			cache.add(key, call.cred, call.found, call.err)
+		}
+		cache.Unlock()
+		call.wg.Done()
+	}()
+	call.cred, call.found, call.err = cache.store.LookupCredential(q)
+	completed = true
+}
+
+// Invalidate removes the cached entry for the specified query.
+func (cache *credCache) Invalidate(q Query) {
+	key := cache.keyFunc(q)
+	cache.Lock()
+	defer cache.Unlock()
+	if call, ok := cache.calls[key]; ok {
+		call.stale = true
+	}
+	if elem, ok := cache.entries[key]; ok {
+		cache.removeElement(elem)
+	}
+}
+
+// InvalidateAll removes all cached entries.
+func (cache *credCache) InvalidateAll() {
+	cache.Lock()
+	defer cache.Unlock()
+	for _, call := range cache.calls {
+		call.stale = true
+	}
+	cache.entries = map[string]*list.Element{}
+	cache.lru.Init()
+}
+
+// Stats returns the cache statistics.
+func (cache *credCache) Stats() CredentialCacheStats {
+	cache.Lock()
+	defer cache.Unlock()
+	stats := cache.stats
+	stats.Entries = cache.lru.Len()
+	return stats
+}
+
+func (cache *credCache) add(key string, cred Credential, found bool, err error) {
+	var ttl time.Duration
+	switch {
+	case found:
+		ttl = cache.ttl
+	case err == nil || errors.Is(err, ErrNoCredential):
+		ttl = cache.negativeTTL
+	}
+	if ttl <= 0 || cache.size <= 0 {
+		return
+	}
+	entry := &credCacheEntry{
+		key:       key,
+		cred:      cred,
+		found:     found,
+		err:       err,
+		expiresAt: cache.clock().Add(ttl),
+	}
+	if elem, ok := cache.entries[key]; ok {
+		elem.Value = entry
+		cache.lru.MoveToFront(elem)
+		return
+	}
+	cache.entries[key] = cache.lru.PushFront(entry)
+	for cache.size < cache.lru.Len() {
+		cache.removeElement(cache.lru.Back())
+		cache.stats.Evictions++
+	}
+}
+
+func (cache *credCache) removeElement(elem *list.Element) {
+	entry, _ := elem.Value.(*credCacheEntry)
+	delete(cache.entries, entry.key)
+	cache.lru.Remove(elem)
+}
@@ -0,0 +1,227 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testCredStore struct {
+	lookups atomic.Int64
+	delay   time.Duration
+	err     error
+	panics  bool
+}
+
+func (store *testCredStore) LookupCredential(q Query) (Credential, bool, error) {
+	store.lookups.Add(1)
+	time.Sleep(store.delay)
+	if store.panics {
+		panic("store panicked")
+	}
+	if store.err != nil {
+		return nil, false, store.err
+	}
+	if q.Username() != "user" {
+		return nil, false, ErrNoCredential
+	}
+	return NewCredential(
+		WithCredentialUsername(q.Username()),
+		WithCredentialPassword("password"),
+	), true, nil
+}
+
+func newTestQuery(t *testing.T, username string) Query {
+	t.Helper()
+	q, err := NewQuery(WithQueryUsername(username))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+func TestCredentialCache(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+
+	store := &testCredStore{}
+	cache := NewCredentialCache(store,
+		WithCredentialCacheTTL(time.Minute),
+		WithCredentialCacheNegativeTTL(time.Second),
+		WithCredentialCacheClock(clock),
+	)
+
+	t.Run("hit", func(t *testing.T) {
+		for range 3 {
+			cred, ok, err := cache.LookupCredential(newTestQuery(t, "user"))
+			if !ok || err != nil {
+				t.Fatalf("LookupCredential() = %v, %v", ok, err)
+			}
+			if cred.Username() != "user" {
+				t.Errorf("username = %s, want user", cred.Username())
+			}
+		}
+		if n := store.lookups.Load(); n != 1 {
+			t.Errorf("lookups = %d, want 1", n)
+		}
+		stats := cache.Stats()
+		if stats.Hits != 2 || stats.Misses != 1 {
+			t.Errorf("stats = %+v", stats)
+		}
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		store.lookups.Store(0)
+		for range 3 {
+			_, ok, err := cache.LookupCredential(newTestQuery(t, "unknown"))
+			if ok || !errors.Is(err, ErrNoCredential) {
+				t.Fatalf("LookupCredential() = %v, %v", ok, err)
+			}
+		}
+		if n := store.lookups.Load(); n != 1 {
+			t.Errorf("lookups = %d, want 1", n)
+		}
+		if stats := cache.Stats(); stats.NegativeHits != 2 {
+			t.Errorf("stats = %+v", stats)
+		}
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		store.lookups.Store(0)
+		now = now.Add(2 * time.Second)
+		cache.LookupCredential(newTestQuery(t, "user"))
+		cache.LookupCredential(newTestQuery(t, "unknown"))
+		if n := store.lookups.Load(); n != 1 {
+			t.Errorf("lookups = %d, want 1", n)
+		}
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		store.lookups.Store(0)
+		cache.Invalidate(newTestQuery(t, "user"))
+		cache.LookupCredential(newTestQuery(t, "user"))
+		cache.InvalidateAll()
+		cache.LookupCredential(newTestQuery(t, "user"))
+		if n := store.lookups.Load(); n != 2 {
+			t.Errorf("lookups = %d, want 2", n)
+		}
+	})
+}
+
+func TestCredentialCacheErrors(t *testing.T) {
+	errStore := errors.New("store unavailable")
+	store := &testCredStore{err: errStore}
+	cache := NewCredentialCache(store)
+	for range 2 {
+		_, ok, err := cache.LookupCredential(newTestQuery(t, "user"))
+		if ok || !errors.Is(err, errStore) {
+			t.Fatalf("LookupCredential() = %v, %v", ok, err)
+		}
+	}
+	if n := store.lookups.Load(); n != 2 {
+		t.Errorf("lookups = %d, want 2", n)
+	}
+}
+
+func TestCredentialCacheEviction(t *testing.T) {
+	store := &testCredStore{}
+	cache := NewCredentialCache(store, WithCredentialCacheSize(2))
+	for _, username := range []string{"a", "b", "c", "a"} {
+		cache.LookupCredential(newTestQuery(t, username))
+	}
+	stats := cache.Stats()
+	if stats.Entries != 2 || stats.Evictions != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if n := store.lookups.Load(); n != 4 {
+		t.Errorf("lookups = %d, want 4", n)
+	}
+}
+
+func TestCredentialCacheSingleflight(t *testing.T) {
+	store := &testCredStore{delay: 50 * time.Millisecond}
+	cache := NewCredentialCache(store)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			_, ok, err := cache.LookupCredential(newTestQuery(t, "user"))
+			if !ok || err != nil {
+				t.Errorf("LookupCredential() = %v, %v", ok, err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if n := store.lookups.Load(); n != 1 {
+		t.Errorf("lookups = %d, want 1", n)
+	}
+}
+
+func TestCredentialCachePanic(t *testing.T) {
+	store := &testCredStore{delay: 50 * time.Millisecond, panics: true}
+	cache := NewCredentialCache(store)
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Go(func() {
+			defer func() {
+				_ = recover()
+			}()
+			_, ok, err := cache.LookupCredential(newTestQuery(t, "user"))
+			if ok || !errors.Is(err, ErrCredentialLookupPanic) {
+				t.Errorf("LookupCredential() = %v, %v", ok, err)
+			}
+		})
+	}
+	wg.Wait()
+
+	store.panics = false
+	if _, ok, err := cache.LookupCredential(newTestQuery(t, "user")); !ok || err != nil {
+		t.Fatalf("LookupCredential() = %v, %v", ok, err)
+	}
+}
+
+func TestCredentialCacheInvalidateInFlight(t *testing.T) {
+	for _, tt := range []struct {
+		username string
+		lookups  int64
+	}{
+		{"user", 2},
+		{"other", 1},
+	} {
+		t.Run(tt.username, func(t *testing.T) {
+			store := &testCredStore{delay: 50 * time.Millisecond}
+			cache := NewCredentialCache(store)
+
+			var wg sync.WaitGroup
+			wg.Go(func() {
+				cache.LookupCredential(newTestQuery(t, "user"))
+			})
+			time.Sleep(10 * time.Millisecond)
+			cache.Invalidate(newTestQuery(t, tt.username))
+			wg.Wait()
+
+			cache.LookupCredential(newTestQuery(t, "user"))
+			if n := store.lookups.Load(); n != tt.lookups {
+				t.Errorf("lookups = %d, want %d", n, tt.lookups)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+// CredentialStoreMatcher represents a function which returns true if a credential store handles the query.
+type CredentialStoreMatcher func(q Query) bool
+
+// CredentialSource is the interface for credentials which report the credential store they were looked up from.
+type CredentialSource interface {
+	// Source returns the name of the credential store which produced the credential.
+	Source() string
+}
+
+// CompositeCredentialStore represents a credential store which delegates lookups to other named credential stores.
+type CompositeCredentialStore interface {
+	// CredentialStore represents the composite credential store.
+	CredentialStore
+	// AddCredentialStore adds a named credential store which is consulted for the queries matched by any of the matchers.
+	// If no matcher is specified, the credential store is consulted for all queries.
+	AddCredentialStore(name string, store CredentialStore, matchers ...CredentialStoreMatcher)
+}
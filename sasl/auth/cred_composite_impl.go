@@ -0,0 +1,188 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// RealmSeparator is the separator between the user name and the realm in a username such as "user@realm".
+const RealmSeparator = "@"
+
+type credStoreRoute struct {
+	name     string
+	store    CredentialStore
+	matchers []CredentialStoreMatcher
+}
+
+type compositeCredStore struct {
+	sync.RWMutex
+	routes      []*credStoreRoute
+	fallThrough bool
+	stopOnError bool
+}
+
+// CompositeCredentialStoreOptionFn represents an option for a composite credential store.
+type CompositeCredentialStoreOptionFn func(*compositeCredStore)
+
+// NewCompositeCredentialStore returns a new composite credential store with options.
+// By default, the matched credential stores are tried in the added order until a credential is found,
+// and the lookup stops at the first credential store which returns an error.
+func NewCompositeCredentialStore(opts ...CompositeCredentialStoreOptionFn) CompositeCredentialStore {
+	store := &compositeCredStore{
+		RWMutex:     sync.RWMutex{},
+		routes:      []*credStoreRoute{},
+		fallThrough: true,
+		stopOnError: true,
+	}
+	for _, opt := range opts {
+		opt(store)
+	}
+	return store
+}
+
+// WithCompositeCredentialStore returns an option to add a named credential store with the matchers.
+func WithCompositeCredentialStore(name string, store CredentialStore, matchers ...CredentialStoreMatcher) CompositeCredentialStoreOptionFn {
+	return func(cs *compositeCredStore) {
+		cs.AddCredentialStore(name, store, matchers...)
+	}
+}
+
+// WithCompositeFallthrough returns an option to set whether the next matched credential store is tried
+// when a credential store does not find the credential. If disabled, only the first matched credential store is consulted.
+func WithCompositeFallthrough(enabled bool) CompositeCredentialStoreOptionFn {
+	return func(cs *compositeCredStore) {
+		cs.fallThrough = enabled
+	}
+}
+
+// WithCompositeStopOnError returns an option to set whether the lookup stops at the first credential store which returns an error.
+// If disabled, the error is recorded and the next matched credential store is tried.
+func WithCompositeStopOnError(enabled bool) CompositeCredentialStoreOptionFn {
+	return func(cs *compositeCredStore) {
+		cs.stopOnError = enabled
+	}
+}
+
+// MatchGroup returns a matcher which matches the queries with any of the specified groups.
+func MatchGroup(groups ...string) CredentialStoreMatcher {
+	return func(q Query) bool {
+		return slices.Contains(groups, q.Group())
+	}
+}
+
+// MatchRealm returns a matcher which matches the queries whose username has any of the specified realms such as "user@realm".
+func MatchRealm(realms ...string) CredentialStoreMatcher {
+	return func(q Query) bool {
+		realm := Realm(q.Username())
+		if len(realm) == 0 {
+			return false
+		}
+		return slices.Contains(realms, realm)
+	}
+}
+
+// Realm returns the realm part of the specified username such as "user@realm", or an empty string if the username has no realm.
+func Realm(username string) string {
+	idx := strings.LastIndex(username, RealmSeparator)
+	if idx < 0 {
+		return ""
+	}
+	return username[idx+len(RealmSeparator):]
+}
+
+// MatchUsernameSuffix returns a matcher which matches the queries whose username has the specified suffix.
+func MatchUsernameSuffix(suffix string) CredentialStoreMatcher {
+	return func(q Query) bool {
+		return strings.HasSuffix(q.Username(), suffix)
+	}
+}
+
+// MatchUsernamePattern returns a matcher which matches the queries whose username matches the specified pattern.
+func MatchUsernamePattern(pattern *regexp.Regexp) CredentialStoreMatcher {
+	return func(q Query) bool {
+		return pattern.MatchString(q.Username())
+	}
+}
+
+// AddCredentialStore adds a named credential store which is consulted for the queries matched by any of the matchers.
+// If no matcher is specified, the credential store is consulted for all queries.
+func (cs *compositeCredStore) AddCredentialStore(name string, store CredentialStore, matchers ...CredentialStoreMatcher) {
+	cs.Lock()
+	defer cs.Unlock()
+	cs.routes = append(cs.routes, &credStoreRoute{
+		name:     name,
+		store:    store,
+		matchers: matchers,
+	})
+}
+
+// LookupCredential looks up a credential by the given query from the matched credential stores.
+// The found credential implements CredentialSource to report the name of the credential store which produced it.
+func (cs *compositeCredStore) LookupCredential(q Query) (Credential, bool, error) {
+	cs.RLock()
+	routes := slices.Clone(cs.routes)
+	cs.RUnlock()
+
+	var errs error
+	for _, route := range routes {
+		if !route.match(q) {
+			continue
+		}
+		cred, ok, err := route.store.LookupCredential(q)
+		if ok {
+			return &sourceCred{Credential: cred, source: route.name}, true, nil
+		}
+		if err != nil && !errors.Is(err, ErrNoCredential) {
+			err = fmt.Errorf("%w : %s", err, route.name)
+			if cs.stopOnError {
+				return nil, false, err
+			}
+			errs = errors.Join(errs, err)
+		}
+		if !cs.fallThrough {
+			break
+		}
+	}
+	return nil, false, errs
+}
+
+func (route *credStoreRoute) match(q Query) bool {
+	if len(route.matchers) == 0 {
+		return true
+	}
+	for _, matcher := range route.matchers {
+		if matcher(q) {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceCred represents a credential with the name of the credential store which produced it.
+type sourceCred struct {
+	Credential
+	source string
+}
+
+// Source returns the name of the credential store which produced the credential.
+func (cred *sourceCred) Source() string {
+	return cred.source
+}
@@ -0,0 +1,127 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+type testUserStore struct {
+	users map[string]string
+	err   error
+}
+
+func (store *testUserStore) LookupCredential(q Query) (Credential, bool, error) {
+	if store.err != nil {
+		return nil, false, store.err
+	}
+	passwd, ok := store.users[q.Username()]
+	if !ok {
+		return nil, false, ErrNoCredential
+	}
+	return NewCredential(
+		WithCredentialUsername(q.Username()),
+		WithCredentialPassword(passwd),
+	), true, nil
+}
+
+func TestCompositeCredentialStore(t *testing.T) {
+	errUnavailable := errors.New("unavailable")
+
+	admin := &testUserStore{users: map[string]string{"admin": "a"}}
+	customers := &testUserStore{users: map[string]string{"alice": "b"}}
+	employees := &testUserStore{users: map[string]string{"bob@corp.example": "c"}}
+	broken := &testUserStore{err: errUnavailable}
+
+	lookup := func(store CredentialStore, group, username string) (string, bool, error) {
+		q, err := NewQuery(WithQueryGroup(group), WithQueryUsername(username))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cred, ok, err := store.LookupCredential(q)
+		if !ok {
+			return "", false, err
+		}
+		src, ok := cred.(CredentialSource)
+		if !ok {
+			t.Fatalf("%T is not a credential source", cred)
+		}
+		return src.Source(), true, err
+	}
+
+	t.Run("route", func(t *testing.T) {
+		store := NewCompositeCredentialStore(
+			WithCompositeCredentialStore("ldap", employees, MatchRealm("corp.example")),
+			WithCompositeCredentialStore("file", admin, MatchGroup("admin"), MatchUsernamePattern(regexp.MustCompile("^admin$"))),
+			WithCompositeCredentialStore("sql", customers),
+		)
+		tests := []struct {
+			group    string
+			username string
+			source   string
+		}{
+			{"", "bob@corp.example", "ldap"},
+			{"", "admin", "file"},
+			{"admin", "admin", "file"},
+			{"", "alice", "sql"},
+		}
+		for _, test := range tests {
+			source, ok, err := lookup(store, test.group, test.username)
+			if !ok || err != nil || source != test.source {
+				t.Errorf("%s = (%s, %v, %v), want %s", test.username, source, ok, err, test.source)
+			}
+		}
+		if _, ok, err := lookup(store, "", "unknown"); ok || err != nil {
+			t.Errorf("unknown = (%v, %v)", ok, err)
+		}
+	})
+
+	t.Run("fallthrough", func(t *testing.T) {
+		store := NewCompositeCredentialStore(
+			WithCompositeFallthrough(false),
+			WithCompositeCredentialStore("file", admin),
+			WithCompositeCredentialStore("sql", customers),
+		)
+		if _, ok, _ := lookup(store, "", "alice"); ok {
+			t.Errorf("alice is found in the second store")
+		}
+	})
+
+	t.Run("stop-on-error", func(t *testing.T) {
+		store := NewCompositeCredentialStore(
+			WithCompositeCredentialStore("broken", broken),
+			WithCompositeCredentialStore("sql", customers),
+		)
+		if _, ok, err := lookup(store, "", "alice"); ok || !errors.Is(err, errUnavailable) {
+			t.Errorf("alice = (%v, %v)", ok, err)
+		}
+	})
+
+	t.Run("continue-on-error", func(t *testing.T) {
+		store := NewCompositeCredentialStore(
+			WithCompositeStopOnError(false),
+			WithCompositeCredentialStore("broken", broken),
+			WithCompositeCredentialStore("sql", customers),
+		)
+		if source, ok, err := lookup(store, "", "alice"); !ok || err != nil || source != "sql" {
+			t.Errorf("alice = (%s, %v, %v)", source, ok, err)
+		}
+		if _, ok, err := lookup(store, "", "unknown"); ok || !errors.Is(err, errUnavailable) {
+			t.Errorf("unknown = (%v, %v)", ok, err)
+		}
+	})
+}
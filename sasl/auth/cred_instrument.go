@@ -0,0 +1,53 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"errors"
+
+	"github.com/cybergarage/go-sasl/sasl/instrument"
+)
+
+type instrumentedCredStore struct {
+	store           CredentialStore
+	instrumentation instrument.Instrumentation
+}
+
+// NewInstrumentedCredentialStore returns a new credential store which measures the lookups of the specified credential store.
+// The store is returned as it is if the instrumentation is nil.
+func NewInstrumentedCredentialStore(store CredentialStore, i instrument.Instrumentation) CredentialStore {
+	if i == nil || store == nil {
+		return store
+	}
+	return &instrumentedCredStore{
+		store:           store,
+		instrumentation: i,
+	}
+}
+
+// LookupCredential looks up a credential from the underlying credential store, and measures the lookup.
+func (store *instrumentedCredStore) LookupCredential(q Query) (Credential, bool, error) {
+	timer := instrument.Start(store.instrumentation, instrument.CredentialLookup, q.Mechanism())
+	cred, ok, err := store.store.LookupCredential(q)
+	switch {
+	case err != nil && !errors.Is(err, ErrNoCredential):
+		timer.Stop(instrument.Error)
+	case !ok:
+		timer.Stop(instrument.Failure)
+	default:
+		timer.Stop(instrument.Success)
+	}
+	return cred, ok, err
+}
@@ -23,3 +23,15 @@ var ErrNoCredentialStore = errors.New("no credential store")
 
 // ErrNoCredential is the error that is returned when no credential is found.
 var ErrNoCredential = errors.New("no credential")
+
+// ErrAuthorizationDenied is the error that is returned when the authentication identity is not allowed to act as the authorization identity.
+var ErrAuthorizationDenied = errors.New("authorization denied")
+
+// ErrTooManyAttempts is the error that is returned when authentication attempts are limited.
+var ErrTooManyAttempts = errors.New("too many attempts")
+
+// ErrInvalidCredential is the error that is returned when the client credential does not match.
+var ErrInvalidCredential = errors.New("invalid credential")
+
+// ErrCredentialLookupPanic is the error that is returned to concurrent lookups when the credential store panics.
+var ErrCredentialLookupPanic = errors.New("credential lookup panicked")
@@ -0,0 +1,95 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"fmt"
+	"time"
+)
+
+// Limiter is the interface for limiting authentication attempts.
+type Limiter interface {
+	// Allow returns nil if an authentication attempt for the user from the connection is allowed,
+	// otherwise it returns a LimitError.
+	Allow(conn Conn, username string) error
+	// Succeeded records a successful authentication for the user from the connection.
+	Succeeded(conn Conn, username string)
+	// Failed records a failed authentication for the user from the connection.
+	Failed(conn Conn, username string)
+}
+
+// LimitReason represents the reason why an authentication attempt is limited.
+type LimitReason int
+
+const (
+	// LimitRateExceeded means that the attempt rate of the user or the remote address is exceeded.
+	LimitRateExceeded LimitReason = iota
+	// LimitBackoff means that the user must wait after the previous failed attempt.
+	LimitBackoff
+	// LimitLockout means that the user is temporarily locked out after too many failed attempts.
+	LimitLockout
+	// LimitCapacity means that the limiter cannot track another user or address because all tracked entries record failures or lockouts.
+	LimitCapacity
+)
+
+// String returns the string representation of the reason.
+func (reason LimitReason) String() string {
+	switch reason {
+	case LimitRateExceeded:
+		return "rate-exceeded"
+	case LimitBackoff:
+		return "backoff"
+	case LimitLockout:
+		return "lockout"
+	case LimitCapacity:
+		return "capacity"
+	}
+	return ""
+}
+
+// LimitError represents an error that is returned when an authentication attempt is limited.
+// LimitError wraps ErrTooManyAttempts, and protocol adapters should map it to a temporary failure such as "try again later".
+type LimitError struct {
+	reason     LimitReason
+	retryAfter time.Duration
+}
+
+// NewLimitError returns a new limit error with the reason and the duration until the next attempt may be allowed.
+func NewLimitError(reason LimitReason, retryAfter time.Duration) *LimitError {
+	return &LimitError{
+		reason:     reason,
+		retryAfter: retryAfter,
+	}
+}
+
+// Reason returns the reason why the attempt is limited.
+func (err *LimitError) Reason() LimitReason {
+	return err.reason
+}
+
+// RetryAfter returns the duration until the next attempt may be allowed.
+func (err *LimitError) RetryAfter() time.Duration {
+	return err.retryAfter
+}
+
+// Error returns the error message.
+func (err *LimitError) Error() string {
+	return fmt.Sprintf("%s : %s (retry after %s)", ErrTooManyAttempts, err.reason, err.retryAfter)
+}
+
+// Unwrap returns ErrTooManyAttempts.
+func (err *LimitError) Unwrap() error {
+	return ErrTooManyAttempts
+}
@@ -0,0 +1,396 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"container/list"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultLimiterMaxEntries is the default maximum number of users and addresses tracked by a memory limiter.
+	DefaultLimiterMaxEntries = 65536
+	// DefaultLimiterBackoffMax is the default maximum backoff delay of a memory limiter.
+	DefaultLimiterBackoffMax = 15 * time.Minute
+	// DefaultLimiterFailureWindow is the default duration after the last failure until the failures of a user are forgotten.
+	DefaultLimiterFailureWindow = time.Hour
+	// limiterEvictionScan is the maximum number of least recently used entries which are checked to evict one.
+	limiterEvictionScan = 16
+)
+
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+type limitBucket struct {
+	bucket *tokenBucket
+	rate   float64
+	burst  int
+}
+
+type userLimit struct {
+	bucket      *tokenBucket
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+type limiterEntry struct {
+	key  string
+	user *userLimit
+	addr *tokenBucket
+}
+
+type memoryLimiter struct {
+	sync.Mutex
+	userRate         float64
+	userBurst        int
+	addrRate         float64
+	addrBurst        int
+	backoffBase      time.Duration
+	backoffMax       time.Duration
+	lockoutThreshold int
+	lockoutDuration  time.Duration
+	failureWindow    time.Duration
+	maxEntries       int
+	clock            func() time.Time
+	users            map[string]*list.Element
+	addrs            map[string]*list.Element
+	lru              *list.List
+}
+
+// MemoryLimiterOptionFn represents an option for a memory limiter.
+type MemoryLimiterOptionFn func(*memoryLimiter)
+
+// NewMemoryLimiter returns a new in-memory limiter with options. Each limit is disabled unless it is enabled by the options.
+func NewMemoryLimiter(opts ...MemoryLimiterOptionFn) Limiter {
+	limiter := &memoryLimiter{
+		Mutex:            sync.Mutex{},
+		userRate:         0,
+		userBurst:        0,
+		addrRate:         0,
+		addrBurst:        0,
+		backoffBase:      0,
+		backoffMax:       DefaultLimiterBackoffMax,
+		lockoutThreshold: 0,
+		lockoutDuration:  0,
+		failureWindow:    DefaultLimiterFailureWindow,
+		maxEntries:       DefaultLimiterMaxEntries,
+		clock:            time.Now,
+		users:            map[string]*list.Element{},
+		addrs:            map[string]*list.Element{},
+		lru:              list.New(),
+	}
+	for _, opt := range opts {
+		opt(limiter)
+	}
+	return limiter
+}
+
+// WithLimiterUserRate returns an option to limit the attempts per user with a token bucket
+// which is refilled at the rate per second up to the burst.
+func WithLimiterUserRate(rate float64, burst int) MemoryLimiterOptionFn {
+	return func(limiter *memoryLimiter) {
+		limiter.userRate = rate
+		limiter.userBurst = burst
+	}
+}
+
+// WithLimiterAddrRate returns an option to limit the attempts per remote IP address with a token bucket
+// which is refilled at the rate per second up to the burst.
+func WithLimiterAddrRate(rate float64, burst int) MemoryLimiterOptionFn {
+	return func(limiter *memoryLimiter) {
+		limiter.addrRate = rate
+		limiter.addrBurst = burst
+	}
+}
+
+// WithLimiterBackoff returns an option to delay the next attempt of a user after consecutive failures.
+// The delay starts at the base and doubles on each failure up to the max, or DefaultLimiterBackoffMax if the max is not positive.
+func WithLimiterBackoff(base time.Duration, maxDelay time.Duration) MemoryLimiterOptionFn {
+	return func(limiter *memoryLimiter) {
+		limiter.backoffBase = base
+		limiter.backoffMax = maxDelay
+		if maxDelay <= 0 {
+			limiter.backoffMax = DefaultLimiterBackoffMax
+		}
+	}
+}
+
+// WithLimiterLockout returns an option to lock out a user for the duration after the threshold of consecutive failures.
+func WithLimiterLockout(threshold int, duration time.Duration) MemoryLimiterOptionFn {
+	return func(limiter *memoryLimiter) {
+		limiter.lockoutThreshold = threshold
+		limiter.lockoutDuration = duration
+	}
+}
+
+// WithLimiterFailureWindow returns an option to set the duration after the last failure until the failures of a user are forgotten.
+// A non-positive duration keeps the failures until a successful authentication.
+func WithLimiterFailureWindow(d time.Duration) MemoryLimiterOptionFn {
+	return func(limiter *memoryLimiter) {
+		limiter.failureWindow = d
+	}
+}
+
+// WithLimiterMaxEntries returns an option to set the maximum number of tracked users and addresses.
+// The least recently used entry without failures or lockouts is evicted when the limiter is full,
+// and new users and addresses are limited with LimitCapacity if no entry can be evicted. A non-positive number disables the limit.
+func WithLimiterMaxEntries(n int) MemoryLimiterOptionFn {
+	return func(limiter *memoryLimiter) {
+		limiter.maxEntries = n
+	}
+}
+
+// WithLimiterClock returns an option to set the clock of the limiter.
+func WithLimiterClock(clock func() time.Time) MemoryLimiterOptionFn {
+	return func(limiter *memoryLimiter) {
+		limiter.clock = clock
+	}
+}
+
+// Allow returns nil if an authentication attempt for the user from the connection is allowed, otherwise it returns a LimitError.
+func (limiter *memoryLimiter) Allow(conn Conn, username string) error {
+	limiter.Lock()
+	defer limiter.Unlock()
+
+	now := limiter.clock()
+
+	// The user entry is only looked up, and is created only for the user rate, so that attempts with unknown usernames
+	// do not evict the failures of other users. Failed creates the user entry to record the failure.
+
+	var user *userLimit
+	if 0 < len(username) {
+		user = limiter.lookupUser(username, now)
+		if user == nil {
+			ok, retryAfter := limiter.reserve(now)
+			if !ok {
+				return NewLimitError(LimitCapacity, retryAfter)
+			}
+			if 0 < limiter.userRate {
+				user = limiter.newUser(username, now)
+			}
+		}
+	}
+
+	if user != nil {
+		if now.Before(user.lockedUntil) {
+			return NewLimitError(LimitLockout, user.lockedUntil.Sub(now))
+		}
+		if 0 < user.failures && 0 < limiter.backoffBase {
+			next := user.lastFailure.Add(limiter.backoffDelay(user.failures))
+			if now.Before(next) {
+				return NewLimitError(LimitBackoff, next.Sub(now))
+			}
+		}
+	}
+
+	buckets := []limitBucket{}
+	if user != nil && 0 < limiter.userRate {
+		buckets = append(buckets, limitBucket{user.bucket, limiter.userRate, limiter.userBurst})
+	}
+	if addr := remoteHost(conn); 0 < len(addr) && 0 < limiter.addrRate {
+		bucket, retryAfter := limiter.addr(addr, now)
+		if bucket == nil {
+			return NewLimitError(LimitCapacity, retryAfter)
+		}
+		buckets = append(buckets, limitBucket{bucket, limiter.addrRate, limiter.addrBurst})
+	}
+
+	for _, b := range buckets {
+		b.bucket.refill(now, b.rate, b.burst)
+		if b.bucket.tokens < 1 {
+			wait := time.Duration((1 - b.bucket.tokens) / b.rate * float64(time.Second))
+			return NewLimitError(LimitRateExceeded, wait)
+		}
+	}
+	for _, b := range buckets {
+		b.bucket.tokens--
+	}
+
+	return nil
+}
+
+// Succeeded records a successful authentication for the user from the connection.
+func (limiter *memoryLimiter) Succeeded(conn Conn, username string) {
+	if len(username) == 0 {
+		return
+	}
+	limiter.Lock()
+	defer limiter.Unlock()
+	user := limiter.lookupUser(username, limiter.clock())
+	if user == nil {
+		return
+	}
+	user.failures = 0
+	user.lockedUntil = time.Time{}
+}
+
+// Failed records a failed authentication for the user from the connection.
+// The failure is always recorded even if no entry can be evicted, so that the limiter fails closed.
+func (limiter *memoryLimiter) Failed(conn Conn, username string) {
+	if len(username) == 0 {
+		return
+	}
+	limiter.Lock()
+	defer limiter.Unlock()
+	now := limiter.clock()
+	user := limiter.lookupUser(username, now)
+	if user == nil {
+		limiter.reserve(now)
+		user = limiter.newUser(username, now)
+	}
+	user.failures++
+	user.lastFailure = now
+	if 0 < limiter.lockoutThreshold && limiter.lockoutThreshold <= user.failures {
+		user.lockedUntil = now.Add(limiter.lockoutDuration)
+		user.failures = 0
+	}
+}
+
+// lookupUser returns the tracked user, or nil if the user is not tracked.
+func (limiter *memoryLimiter) lookupUser(username string, now time.Time) *userLimit {
+	elem, ok := limiter.users[username]
+	if !ok {
+		return nil
+	}
+	limiter.lru.MoveToFront(elem)
+	entry, _ := elem.Value.(*limiterEntry)
+	user := entry.user
+	if 0 < user.failures && 0 < limiter.failureWindow && !now.Before(user.lastFailure.Add(limiter.failureWindow)) {
+		user.failures = 0
+	}
+	return user
+}
+
+func (limiter *memoryLimiter) newUser(username string, now time.Time) *userLimit {
+	user := &userLimit{
+		bucket:      &tokenBucket{tokens: float64(limiter.userBurst), updatedAt: now},
+		failures:    0,
+		lastFailure: time.Time{},
+		lockedUntil: time.Time{},
+	}
+	limiter.users[username] = limiter.lru.PushFront(&limiterEntry{key: username, user: user, addr: nil})
+	return user
+}
+
+// addr returns the bucket of the address, or nil and the duration until an entry may be evictable if the address cannot be tracked.
+func (limiter *memoryLimiter) addr(addr string, now time.Time) (*tokenBucket, time.Duration) {
+	if elem, ok := limiter.addrs[addr]; ok {
+		limiter.lru.MoveToFront(elem)
+		entry, _ := elem.Value.(*limiterEntry)
+		return entry.addr, 0
+	}
+	if ok, retryAfter := limiter.reserve(now); !ok {
+		return nil, retryAfter
+	}
+	bucket := &tokenBucket{tokens: float64(limiter.addrBurst), updatedAt: now}
+	limiter.addrs[addr] = limiter.lru.PushFront(&limiterEntry{key: addr, user: nil, addr: bucket})
+	return bucket, 0
+}
+
+// reserve returns true if another entry can be tracked, and evicts the least recently used entries without failures or lockouts
+// when too many entries are tracked. Otherwise, it returns false and the duration until a checked entry may be evictable.
+// The checked entries with failures or lockouts are moved to the front so that the next call checks other entries.
+func (limiter *memoryLimiter) reserve(now time.Time) (bool, time.Duration) {
+	retryAfter := time.Duration(0)
+	for n := 0; 0 < limiter.maxEntries && limiter.maxEntries <= limiter.lru.Len(); n++ {
+		if limiterEvictionScan <= n {
+			return false, retryAfter
+		}
+		elem := limiter.lru.Back()
+		entry, _ := elem.Value.(*limiterEntry)
+		until, protected := limiter.protection(entry, now)
+		if !protected {
+			limiter.lru.Remove(elem)
+			if entry.user != nil {
+				delete(limiter.users, entry.key)
+			} else {
+				delete(limiter.addrs, entry.key)
+			}
+			continue
+		}
+		if !until.IsZero() && (retryAfter == 0 || until.Sub(now) < retryAfter) {
+			retryAfter = until.Sub(now)
+		}
+		limiter.lru.MoveToFront(elem)
+	}
+	return true, 0
+}
+
+// protection returns true if the entry records a lockout or failures of a user which must not be evicted,
+// and the time when the protection expires, which is zero if the failures are kept until a successful authentication.
+func (limiter *memoryLimiter) protection(entry *limiterEntry, now time.Time) (time.Time, bool) {
+	user := entry.user
+	if user == nil {
+		return time.Time{}, false
+	}
+	var until time.Time
+	if now.Before(user.lockedUntil) {
+		until = user.lockedUntil
+	}
+	if 0 < user.failures {
+		if limiter.failureWindow <= 0 {
+			return time.Time{}, true
+		}
+		if expiry := user.lastFailure.Add(limiter.failureWindow); now.Before(expiry) && until.Before(expiry) {
+			until = expiry
+		}
+	}
+	return until, !until.IsZero()
+}
+
+func (limiter *memoryLimiter) backoffDelay(failures int) time.Duration {
+	delay := limiter.backoffBase
+	for n := 1; n < failures; n++ {
+		if limiter.backoffMax/2 < delay {
+			return limiter.backoffMax
+		}
+		delay *= 2
+	}
+	return min(delay, limiter.backoffMax)
+}
+
+func (bucket *tokenBucket) refill(now time.Time, rate float64, burst int) {
+	elapsed := now.Sub(bucket.updatedAt).Seconds()
+	if 0 < elapsed {
+		bucket.tokens = min(float64(burst), bucket.tokens+elapsed*rate)
+	}
+	bucket.updatedAt = now
+}
+
+func remoteHost(conn Conn) string {
+	if conn == nil {
+		return ""
+	}
+	addr := conn.RemoteAddr()
+	if addr == nil {
+		return ""
+	}
+	switch v := addr.(type) {
+	case *net.TCPAddr:
+		return v.IP.String()
+	case *net.UDPAddr:
+		return v.IP.String()
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
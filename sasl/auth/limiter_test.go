@@ -0,0 +1,239 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"testing"
+	"time"
+)
+
+type testConn struct {
+	addr net.Addr
+}
+
+func (conn *testConn) RemoteAddr() net.Addr {
+	return conn.addr
+}
+
+func newTestConn(ip string) Conn {
+	return &testConn{addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 12345}}
+}
+
+func expectLimitReason(t *testing.T, err error, reason LimitReason) {
+	t.Helper()
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || !errors.Is(err, ErrTooManyAttempts) {
+		t.Fatalf("err = %v, want %s", err, reason)
+	}
+	if limitErr.Reason() != reason {
+		t.Fatalf("reason = %s, want %s", limitErr.Reason(), reason)
+	}
+}
+
+func TestMemoryLimiter(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+
+	t.Run("user-rate", func(t *testing.T) {
+		limiter := NewMemoryLimiter(WithLimiterUserRate(1, 2), WithLimiterClock(clock))
+		conn := newTestConn("192.0.2.1")
+		for range 2 {
+			if err := limiter.Allow(conn, "alice"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		expectLimitReason(t, limiter.Allow(conn, "alice"), LimitRateExceeded)
+		if err := limiter.Allow(conn, "bob"); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(time.Second)
+		if err := limiter.Allow(conn, "alice"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("addr-rate", func(t *testing.T) {
+		limiter := NewMemoryLimiter(WithLimiterAddrRate(1, 1), WithLimiterClock(clock))
+		if err := limiter.Allow(newTestConn("192.0.2.1"), "alice"); err != nil {
+			t.Fatal(err)
+		}
+		expectLimitReason(t, limiter.Allow(newTestConn("192.0.2.1"), "bob"), LimitRateExceeded)
+		if err := limiter.Allow(newTestConn("192.0.2.2"), "bob"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("backoff", func(t *testing.T) {
+		limiter := NewMemoryLimiter(WithLimiterBackoff(time.Second, 4*time.Second), WithLimiterClock(clock))
+		conn := newTestConn("192.0.2.1")
+		delays := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second}
+		for _, delay := range delays {
+			if err := limiter.Allow(conn, "alice"); err != nil {
+				t.Fatal(err)
+			}
+			limiter.Failed(conn, "alice")
+			err := limiter.Allow(conn, "alice")
+			expectLimitReason(t, err, LimitBackoff)
+			var limitErr *LimitError
+			if errors.As(err, &limitErr) && limitErr.RetryAfter() != delay {
+				t.Errorf("retry after = %s, want %s", limitErr.RetryAfter(), delay)
+			}
+			now = now.Add(delay)
+		}
+		limiter.Succeeded(conn, "alice")
+		limiter.Failed(conn, "alice")
+		now = now.Add(time.Second)
+		if err := limiter.Allow(conn, "alice"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("backoff-max", func(t *testing.T) {
+		for _, maxDelay := range []time.Duration{0, time.Duration(math.MaxInt64)} {
+			limiter := NewMemoryLimiter(WithLimiterBackoff(time.Second, maxDelay), WithLimiterClock(clock))
+			conn := newTestConn("192.0.2.1")
+			for range 100 {
+				limiter.Failed(conn, "alice")
+			}
+			err := limiter.Allow(conn, "alice")
+			expectLimitReason(t, err, LimitBackoff)
+			var limitErr *LimitError
+			if errors.As(err, &limitErr) && limitErr.RetryAfter() <= 0 {
+				t.Errorf("retry after = %s", limitErr.RetryAfter())
+			}
+			if maxDelay == 0 && limitErr.RetryAfter() != DefaultLimiterBackoffMax {
+				t.Errorf("retry after = %s, want %s", limitErr.RetryAfter(), DefaultLimiterBackoffMax)
+			}
+		}
+	})
+
+	t.Run("failure-window", func(t *testing.T) {
+		limiter := NewMemoryLimiter(
+			WithLimiterLockout(3, time.Minute),
+			WithLimiterFailureWindow(10*time.Minute),
+			WithLimiterClock(clock),
+		)
+		conn := newTestConn("192.0.2.1")
+		for range 2 {
+			limiter.Failed(conn, "alice")
+		}
+		now = now.Add(10 * time.Minute)
+		limiter.Failed(conn, "alice")
+		if err := limiter.Allow(conn, "alice"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("max-entries", func(t *testing.T) {
+		const maxEntries = 4
+		limiter := NewMemoryLimiter(
+			WithLimiterUserRate(100, 100),
+			WithLimiterLockout(3, time.Minute),
+			WithLimiterMaxEntries(maxEntries),
+			WithLimiterClock(clock),
+		)
+		impl, _ := limiter.(*memoryLimiter)
+		conn := newTestConn("192.0.2.1")
+
+		// Clean entries are evicted by other users.
+
+		for i := range 2 * maxEntries {
+			if err := limiter.Allow(conn, fmt.Sprintf("clean%d", i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if n := impl.lru.Len(); n != maxEntries {
+			t.Errorf("entries = %d, want %d", n, maxEntries)
+		}
+
+		// Sprayed usernames never evict the lockout of a victim.
+
+		for range 3 {
+			if err := limiter.Allow(conn, "alice"); err != nil {
+				t.Fatal(err)
+			}
+			limiter.Failed(conn, "alice")
+		}
+		for i := range 1000 {
+			username := fmt.Sprintf("user%d", i)
+			err := limiter.Allow(conn, username)
+			if err == nil {
+				limiter.Failed(conn, username)
+				continue
+			}
+			expectLimitReason(t, err, LimitCapacity)
+		}
+		if n := impl.lru.Len(); maxEntries < n {
+			t.Errorf("entries = %d, want %d or less", n, maxEntries)
+		}
+		expectLimitReason(t, limiter.Allow(conn, "alice"), LimitLockout)
+
+		// The entries are evictable after the failures and the lockout expire.
+
+		now = now.Add(DefaultLimiterFailureWindow)
+		if err := limiter.Allow(conn, "bob"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("lockout", func(t *testing.T) {
+		limiter := NewMemoryLimiter(WithLimiterLockout(3, time.Minute), WithLimiterClock(clock))
+		conn := newTestConn("192.0.2.1")
+		for range 3 {
+			if err := limiter.Allow(conn, "alice"); err != nil {
+				t.Fatal(err)
+			}
+			limiter.Failed(conn, "alice")
+		}
+		expectLimitReason(t, limiter.Allow(conn, "alice"), LimitLockout)
+		now = now.Add(time.Minute)
+		if err := limiter.Allow(conn, "alice"); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestManagerLimiter(t *testing.T) {
+	mgr := NewManager()
+	mgr.SetCredentialStore(&testCredStore{})
+	limiter := NewMemoryLimiter(WithLimiterLockout(2, time.Minute))
+	mgr.SetLimiter(limiter)
+	if mgr.Limiter() != limiter {
+		t.Errorf("limiter = %v, want %v", mgr.Limiter(), limiter)
+	}
+
+	verify := func(password string) (bool, error) {
+		q, err := NewQuery(WithQueryUsername("user"), WithQueryPassword(password))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return mgr.VerifyCredential(nil, q)
+	}
+
+	for range 2 {
+		if ok, _ := verify("invalid"); ok {
+			t.Fatal("invalid password is verified")
+		}
+	}
+	ok, err := verify("password")
+	if ok {
+		t.Fatal("locked out user is verified")
+	}
+	expectLimitReason(t, err, LimitLockout)
+}
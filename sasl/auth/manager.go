@@ -14,6 +14,10 @@
 
 package auth
 
+import (
+	"github.com/cybergarage/go-sasl/sasl/instrument"
+)
+
 // Manager represents a  auth manager interface.
 type Manager interface {
 	// SetCredentialAuthenticator sets the credential authenticator.
@@ -24,4 +28,16 @@ type Manager interface {
 	CredentialStore() CredentialStore
 	// VerifyCredential verifies the client credential.
 	VerifyCredential(conn Conn, q Query) (bool, error)
+	// SetAuthorizer sets the authorizer.
+	SetAuthorizer(authz Authorizer)
+	// Authorize returns nil if the authenticated identity is allowed to act as the authorization identity.
+	Authorize(conn Conn, authcid string, authzid string, mech string) error
+	// SetLimiter sets the limiter for authentication attempts.
+	SetLimiter(limiter Limiter)
+	// Limiter returns the limiter, or nil if the limiter is not set.
+	Limiter() Limiter
+	// SetInstrumentation sets the instrumentation which measures the credential lookups, verifications and authorizations.
+	SetInstrumentation(i instrument.Instrumentation)
+	// Instrumentation returns the instrumentation, or nil if the instrumentation is not set.
+	Instrumentation() instrument.Instrumentation
 }
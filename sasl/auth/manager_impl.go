@@ -14,9 +14,18 @@
 
 package auth
 
+import (
+	"errors"
+
+	"github.com/cybergarage/go-sasl/sasl/instrument"
+)
+
 type manager struct {
 	credAuthenticator CredentialAuthenticator
 	credStore         CredentialStore
+	authorizer        Authorizer
+	limiter           Limiter
+	instrumentation   instrument.Instrumentation
 }
 
 // NewManager returns a new auth manager instance.
@@ -24,6 +33,9 @@ func NewManager() Manager {
 	mgr := &manager{
 		credStore:         nil,
 		credAuthenticator: NewDefaultCredentialAuthenticator(),
+		authorizer:        NewDefaultAuthorizer(),
+		limiter:           nil,
+		instrumentation:   nil,
 	}
 	return mgr
 }
@@ -36,15 +48,22 @@ func (mgr *manager) SetCredentialAuthenticator(auth CredentialAuthenticator) {
 // SetCredentialStore sets the credential store.
 func (mgr *manager) SetCredentialStore(credStore CredentialStore) {
 	mgr.credStore = credStore
-	if mgr.credAuthenticator != nil {
-		csReg, ok := mgr.credAuthenticator.(CredentialStoreRegistrar)
-		if ok {
-			csReg.SetCredentialStore(credStore)
-		}
+	mgr.registerCredentialStore()
+}
+
+// registerCredentialStore registers the credential store, which measures the lookups if the instrumentation is set, to the credential authenticator.
+func (mgr *manager) registerCredentialStore() {
+	if mgr.credAuthenticator == nil {
+		return
+	}
+	csReg, ok := mgr.credAuthenticator.(CredentialStoreRegistrar)
+	if ok {
+		csReg.SetCredentialStore(NewInstrumentedCredentialStore(mgr.credStore, mgr.instrumentation))
 	}
 }
 
-// CredentialStore returns the credential store.
+// CredentialStore returns the credential store as it is set, so that the optional interfaces such as CredentialCache are available.
+// Use NewInstrumentedCredentialStore with Instrumentation to measure the lookups of the returned store.
 func (mgr *manager) CredentialStore() CredentialStore {
 	if mgr.credStore != nil {
 		return mgr.credStore
@@ -59,9 +78,98 @@ func (mgr *manager) CredentialStore() CredentialStore {
 }
 
 // VerifyCredential verifies the client credential query.
+// If the limiter does not allow the attempt, the function returns false and a LimitError.
 // If the credential store is nil, the function returns true and no error.
 // If the query is valid, the function returns true and no error.
 // Otherwise, it returns false and an error if an error occurs during the verification process.
 func (mgr *manager) VerifyCredential(conn Conn, q Query) (bool, error) {
-	return mgr.credAuthenticator.VerifyCredential(conn, q)
+	if err := mgr.allow(conn, q.Username()); err != nil {
+		return false, err
+	}
+	timer := instrument.Start(mgr.instrumentation, instrument.CredentialVerification, q.Mechanism())
+	ok, err := mgr.credAuthenticator.VerifyCredential(conn, q)
+	switch {
+	case ok:
+		timer.Stop(instrument.Success)
+		mgr.succeeded(conn, q.Username())
+	case err == nil || errors.Is(err, ErrNoCredential):
+		timer.Stop(instrument.Failure)
+		mgr.failed(conn, q.Username())
+	default:
+		timer.Stop(instrument.Error)
+	}
+	return ok, err
+}
+
+// SetAuthorizer sets the authorizer.
+func (mgr *manager) SetAuthorizer(authz Authorizer) {
+	mgr.authorizer = authz
+}
+
+// Authorize returns nil if the authenticated identity is allowed to act as the authorization identity.
+// If the authorizer is nil, the function denies any authorization identity other than the authentication identity.
+func (mgr *manager) Authorize(conn Conn, authcid string, authzid string, mech string) error {
+	authz := mgr.authorizer
+	if authz == nil {
+		authz = NewDefaultAuthorizer()
+	}
+	timer := instrument.Start(mgr.instrumentation, instrument.Authorization, mech)
+	err := authz.Authorize(conn, authcid, authzid, mech)
+	switch {
+	case err == nil:
+		timer.Stop(instrument.Success)
+	case errors.Is(err, ErrAuthorizationDenied):
+		timer.Stop(instrument.Failure)
+	default:
+		timer.Stop(instrument.Error)
+	}
+	return err
+}
+
+// SetLimiter sets the limiter for authentication attempts.
+func (mgr *manager) SetLimiter(limiter Limiter) {
+	mgr.limiter = limiter
+}
+
+// Limiter returns the limiter, or nil if the limiter is not set.
+func (mgr *manager) Limiter() Limiter {
+	return mgr.limiter
+}
+
+// allow returns nil if an authentication attempt for the user from the connection is allowed by the limiter.
+// If the limiter is nil, the function always returns nil.
+func (mgr *manager) allow(conn Conn, username string) error {
+	if mgr.limiter == nil {
+		return nil
+	}
+	return mgr.limiter.Allow(conn, username)
+}
+
+// succeeded records a successful authentication to the limiter.
+func (mgr *manager) succeeded(conn Conn, username string) {
+	if mgr.limiter == nil {
+		return
+	}
+	mgr.limiter.Succeeded(conn, username)
+}
+
+// failed records a failed authentication to the limiter.
+func (mgr *manager) failed(conn Conn, username string) {
+	if mgr.limiter == nil {
+		return
+	}
+	mgr.limiter.Failed(conn, username)
+}
+
+// SetInstrumentation sets the instrumentation which measures the credential lookups, verifications and authorizations.
+func (mgr *manager) SetInstrumentation(i instrument.Instrumentation) {
+	mgr.instrumentation = i
+	if mgr.credStore != nil {
+		mgr.registerCredentialStore()
+	}
+}
+
+// Instrumentation returns the instrumentation, or nil if the instrumentation is not set.
+func (mgr *manager) Instrumentation() instrument.Instrumentation {
+	return mgr.instrumentation
 }
@@ -18,6 +18,10 @@ package sasl
 type Client interface {
 	// Version returns the version.
 	Version() string
+	// AddMechanism adds a mechanism to the client.
+	AddMechanism(mech Mechanism)
+	// AddMechanisms adds mechanisms to the client.
+	AddMechanisms(mech ...Mechanism)
 	// Mechanisms returns the mechanisms.
 	Mechanisms() []Mechanism
 	// Mechanism returns a mechanism by name.
@@ -16,11 +16,53 @@ package sasl
 
 import (
 	"errors"
-	"fmt"
+
+	"github.com/cybergarage/go-sasl/sasl/mech"
 )
 
+// ErrUnsupportedMechanism is the error that is returned when a mechanism is not supported.
 var ErrUnsupportedMechanism = errors.New("unsupported mechanism")
 
+// ErrInvalidToken is the error that is returned when a token is malformed, tampered or sealed for another mechanism.
+var ErrInvalidToken = mech.ErrInvalidToken
+
+// ErrTokenExpired is the error that is returned when a token is expired.
+var ErrTokenExpired = mech.ErrTokenExpired
+
+// ErrTokenReplayed is the error that is returned when a token is opened more than once.
+var ErrTokenReplayed = mech.ErrTokenReplayed
+
+// Error represents an authentication failure with the reason, the mechanism name, the step and a public message.
+type Error = mech.Error
+
+// ErrorReason represents a protocol independent reason of an authentication failure.
+type ErrorReason = mech.ErrorReason
+
+const (
+	// ReasonMalformed represents a malformed or unexpected message.
+	ReasonMalformed = mech.ReasonMalformed
+	// ReasonUnsupportedMechanism represents an unsupported mechanism.
+	ReasonUnsupportedMechanism = mech.ReasonUnsupportedMechanism
+	// ReasonInvalidCredentials represents invalid credentials or an unknown user.
+	ReasonInvalidCredentials = mech.ReasonInvalidCredentials
+	// ReasonTemporaryFailure represents a temporary failure such as an unavailable credential store.
+	ReasonTemporaryFailure = mech.ReasonTemporaryFailure
+	// ReasonAuthorizationDenied represents an authorization identity which the authenticated identity is not allowed to act as.
+	ReasonAuthorizationDenied = mech.ReasonAuthorizationDenied
+	// ReasonAborted represents an exchange aborted by the client.
+	ReasonAborted = mech.ReasonAborted
+	// ReasonEncryptionRequired represents a mechanism which is not allowed without an encrypted connection.
+	ReasonEncryptionRequired = mech.ReasonEncryptionRequired
+)
+
+// ReasonOf returns the reason of the specified error if the error is or wraps an Error.
+func ReasonOf(err error) (ErrorReason, bool) {
+	return mech.ReasonOf(err)
+}
+
 func newErrUnsupportedMechanism(name string) error {
-	return fmt.Errorf("%w : %s", ErrUnsupportedMechanism, name)
+	return mech.NewError(mech.ReasonUnsupportedMechanism,
+		mech.WithErrorMechanism(name),
+		mech.WithErrorCause(ErrUnsupportedMechanism),
+	)
 }
@@ -0,0 +1,269 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// DefaultMechanism is the default mechanism of clients.
+const DefaultMechanism = "SCRAM-SHA-256"
+
+// ErrMultiStepMechanism is returned when a multi-step mechanism is used for the per-RPC credentials.
+var ErrMultiStepMechanism = errors.New("multi-step mechanism")
+
+// ErrUnexpectedMessage is returned when the server sends a message after the exchange is completed.
+var ErrUnexpectedMessage = errors.New("unexpected message")
+
+// clientConnState represents the authentication state of a client connection.
+type clientConnState struct {
+	sync.Mutex
+	result mech.AuthResult
+}
+
+type client struct {
+	saslClient        sasl.Client
+	mechName          string
+	opts              []mech.Option
+	transportSecurity bool
+	conns             sync.Map
+}
+
+// ClientOptionFn represents an option for a client authenticator or per-RPC credentials.
+type ClientOptionFn func(*client)
+
+// NewClient returns a new gRPC client authenticator of the SASL client.
+// The client authenticates each connection with the authentication stream before the first call,
+// and authenticates again once if a unary call is rejected as unauthenticated after a reconnection.
+func NewClient(saslClient sasl.Client, opts ...ClientOptionFn) Client {
+	return newClient(saslClient, opts...)
+}
+
+// NewPerRPCCredentials returns new per-RPC credentials which send the initial client response of a single-step mechanism such as PLAIN
+// in the metadata of every call. The credentials require transport security by default.
+func NewPerRPCCredentials(saslClient sasl.Client, opts ...ClientOptionFn) credentials.PerRPCCredentials {
+	return newClient(saslClient, opts...)
+}
+
+func newClient(saslClient sasl.Client, opts ...ClientOptionFn) *client {
+	c := &client{
+		saslClient:        saslClient,
+		mechName:          DefaultMechanism,
+		opts:              []mech.Option{},
+		transportSecurity: true,
+		conns:             sync.Map{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithClientMechanism returns an option to set the mechanism name.
+func WithClientMechanism(name string) ClientOptionFn {
+	return func(c *client) {
+		c.mechName = name
+	}
+}
+
+// WithClientOptions returns an option to set the mechanism options such as the username and password.
+func WithClientOptions(opts ...mech.Option) ClientOptionFn {
+	return func(c *client) {
+		c.opts = append(c.opts, opts...)
+	}
+}
+
+// WithClientTransportSecurity returns an option to set whether the per-RPC credentials require transport security.
+func WithClientTransportSecurity(required bool) ClientOptionFn {
+	return func(c *client) {
+		c.transportSecurity = required
+	}
+}
+
+// DialOptions returns the gRPC dial options to install the interceptors.
+func (c *client) DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(c.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(c.StreamClientInterceptor()),
+	}
+}
+
+// Authenticate runs the exchange on the connection with the authentication service.
+func (c *client) Authenticate(ctx context.Context, cc grpc.ClientConnInterface) (mech.AuthResult, error) {
+	m, err := c.saslClient.Mechanism(c.mechName)
+	if err != nil {
+		return nil, err
+	}
+	cctx, err := m.Start(c.opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cctx.Dispose()
+
+	ctx, cancel := context.WithCancel(metadata.AppendToOutgoingContext(ctx, MechMetadataKey, m.Name()))
+	defer cancel()
+	stream, err := cc.NewStream(ctx, &serviceDesc.Streams[0], AuthenticateMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := cctx.Next()
+	for err == nil {
+		req := wrapperspb.Bytes(nil)
+		if out != nil {
+			req.Value = out.Bytes()
+		}
+		if err := stream.SendMsg(req); err != nil {
+			return nil, err
+		}
+		var res wrapperspb.BytesValue
+		if err := stream.RecvMsg(&res); err != nil {
+			return nil, err
+		}
+		if cctx.Done() {
+			break
+		}
+		out, err = cctx.Next(res.GetValue())
+		if cctx.Done() {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The server closes the stream after the final message if the exchange is completed successfully.
+
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	var tail wrapperspb.BytesValue
+	if err := stream.RecvMsg(&tail); !errors.Is(err, io.EOF) {
+		if err == nil {
+			err = ErrUnexpectedMessage
+		}
+		return nil, err
+	}
+
+	// Clients of mechanisms without a server outcome, such as PLAIN, learn the success from the closed stream.
+
+	if resultCtx, ok := cctx.(mech.AuthResultContext); ok {
+		if result, ok := resultCtx.AuthResult(); ok {
+			return result, nil
+		}
+	}
+	return mech.NewAuthResult(mech.WithAuthResultMechanism(m.Name())), nil
+}
+
+// UnaryClientInterceptor returns the unary interceptor which authenticates the connection before the first call.
+func (c *client) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := c.ensureAuthenticated(ctx, cc); err != nil {
+			return err
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if status.Code(err) != codes.Unauthenticated {
+			return err
+		}
+		// The server forgets the identity when the connection is re-established.
+		c.conns.Delete(cc)
+		if err := c.ensureAuthenticated(ctx, cc); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor returns the stream interceptor which authenticates the connection before the first call.
+func (c *client) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if method != AuthenticateMethod {
+			if err := c.ensureAuthenticated(ctx, cc); err != nil {
+				return nil, err
+			}
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+// ensureAuthenticated authenticates the connection if the connection is not authenticated yet.
+func (c *client) ensureAuthenticated(ctx context.Context, cc *grpc.ClientConn) error {
+	c.conns.Range(func(key, _ any) bool {
+		if conn, ok := key.(*grpc.ClientConn); ok && conn.GetState() == connectivity.Shutdown {
+			c.conns.Delete(key)
+		}
+		return true
+	})
+	v, _ := c.conns.LoadOrStore(cc, &clientConnState{Mutex: sync.Mutex{}, result: nil})
+	state, _ := v.(*clientConnState)
+	state.Lock()
+	defer state.Unlock()
+	if state.result != nil {
+		return nil
+	}
+	result, err := c.Authenticate(ctx, cc)
+	if err != nil {
+		return err
+	}
+	state.result = result
+	return nil
+}
+
+// GetRequestMetadata returns the metadata of the mechanism name and the initial client response.
+func (c *client) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	m, err := c.saslClient.Mechanism(c.mechName)
+	if err != nil {
+		return nil, err
+	}
+	cctx, err := m.Start(c.opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cctx.Dispose()
+	out, err := cctx.Next()
+	if err != nil {
+		return nil, err
+	}
+	if !cctx.Done() {
+		return nil, fmt.Errorf("%w : %s", ErrMultiStepMechanism, m.Name())
+	}
+	md := map[string]string{
+		MechMetadataKey:  m.Name(),
+		TokenMetadataKey: "",
+	}
+	if out != nil {
+		md[TokenMetadataKey] = string(out.Bytes())
+	}
+	return md, nil
+}
+
+// RequireTransportSecurity returns true if the credentials require transport security.
+func (c *client) RequireTransportSecurity() bool {
+	return c.transportSecurity
+}
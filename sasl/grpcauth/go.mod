@@ -0,0 +1,20 @@
+module github.com/cybergarage/go-sasl/sasl/grpcauth
+
+go 1.25.0
+
+require (
+	github.com/cybergarage/go-sasl v0.0.0-00010101000000-000000000000
+	google.golang.org/grpc v1.84.0
+	google.golang.org/protobuf v1.36.11
+)
+
+require (
+	github.com/cybergarage/go-safecast v1.3.5 // indirect
+	github.com/xdg-go/stringprep v1.0.4 // indirect
+	golang.org/x/net v0.57.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+	golang.org/x/text v0.40.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260706201446-f0a921348800 // indirect
+)
+
+replace github.com/cybergarage/go-sasl => ../..
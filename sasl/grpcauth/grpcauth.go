@@ -0,0 +1,70 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcauth
+
+import (
+	"context"
+
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+const (
+	// ServiceName is the name of the authentication service.
+	ServiceName = "sasl.SASL"
+	// AuthenticateMethod is the full method name of the bidirectional authentication stream.
+	// The client sends the mechanism name in the MechMetadataKey header, and the client and server exchange
+	// the mechanism messages as google.protobuf.BytesValue messages until the server closes the stream.
+	AuthenticateMethod = "/" + ServiceName + "/Authenticate"
+	// MechMetadataKey is the metadata key of the mechanism name.
+	MechMetadataKey = "sasl-mech"
+	// TokenMetadataKey is the binary metadata key of the initial client response for the per-RPC credentials.
+	TokenMetadataKey = "sasl-token-bin"
+)
+
+// Server represents a gRPC server authenticator which caches the authenticated identity per connection.
+// The server must be installed as the stats handler of the gRPC server to track the connections.
+type Server interface {
+	stats.Handler
+	// ServerOptions returns the gRPC server options to install the stats handler and the interceptors.
+	ServerOptions() []grpc.ServerOption
+	// UnaryServerInterceptor returns the unary interceptor which rejects unauthenticated calls.
+	UnaryServerInterceptor() grpc.UnaryServerInterceptor
+	// StreamServerInterceptor returns the stream interceptor which rejects unauthenticated calls.
+	StreamServerInterceptor() grpc.StreamServerInterceptor
+	// RegisterService registers the authentication service to the gRPC server.
+	RegisterService(s grpc.ServiceRegistrar)
+}
+
+// Client represents a gRPC client authenticator which authenticates each connection once before the calls.
+type Client interface {
+	// DialOptions returns the gRPC dial options to install the interceptors.
+	DialOptions() []grpc.DialOption
+	// Authenticate runs the exchange on the connection with the authentication service.
+	Authenticate(ctx context.Context, cc grpc.ClientConnInterface) (mech.AuthResult, error)
+	// UnaryClientInterceptor returns the unary interceptor which authenticates the connection before the first call.
+	UnaryClientInterceptor() grpc.UnaryClientInterceptor
+	// StreamClientInterceptor returns the stream interceptor which authenticates the connection before the first call.
+	StreamClientInterceptor() grpc.StreamClientInterceptor
+}
+
+type authResultKey struct{}
+
+// AuthResultFrom returns the authentication result of the call authenticated by the server interceptors.
+func AuthResultFrom(ctx context.Context) (mech.AuthResult, bool) {
+	result, ok := ctx.Value(authResultKey{}).(mech.AuthResult)
+	return result, ok
+}
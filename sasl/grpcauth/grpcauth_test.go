@@ -0,0 +1,218 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcauth_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/grpcauth"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasltest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testServer represents a health server behind the authenticator which records the authenticated identities.
+type testServer struct {
+	listener        *bufconn.Listener
+	server          *grpc.Server
+	mutex           sync.Mutex
+	authcIDs        []string
+	authentications atomic.Int64
+}
+
+func newTestServer(t *testing.T, opts ...grpcauth.ServerOptionFn) *testServer {
+	t.Helper()
+	authn, err := grpcauth.NewServer(sasltest.NewServer(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := &testServer{
+		listener: bufconn.Listen(1024 * 1024),
+		server:   nil,
+		mutex:    sync.Mutex{},
+		authcIDs: []string{},
+	}
+	record := func(ctx context.Context) {
+		ts.mutex.Lock()
+		defer ts.mutex.Unlock()
+		if result, ok := grpcauth.AuthResultFrom(ctx); ok {
+			ts.authcIDs = append(ts.authcIDs, result.AuthcID())
+		}
+	}
+	serverOpts := append(authn.ServerOptions(),
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			record(ctx)
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if info.FullMethod == grpcauth.AuthenticateMethod {
+				ts.authentications.Add(1)
+			} else {
+				record(ss.Context())
+			}
+			return handler(srv, ss)
+		}),
+	)
+	ts.server = grpc.NewServer(serverOpts...)
+	authn.RegisterService(ts.server)
+	healthpb.RegisterHealthServer(ts.server, health.NewServer())
+	go ts.server.Serve(ts.listener)
+	t.Cleanup(ts.server.Stop)
+	return ts
+}
+
+func (ts *testServer) dial(t *testing.T, opts ...grpc.DialOption) healthpb.HealthClient {
+	t.Helper()
+	dialOpts := append([]grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return ts.listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}, opts...)
+	conn, err := grpc.NewClient("passthrough:///bufnet", dialOpts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func (ts *testServer) lastAuthcID() string {
+	ts.mutex.Lock()
+	defer ts.mutex.Unlock()
+	if len(ts.authcIDs) == 0 {
+		return ""
+	}
+	return ts.authcIDs[len(ts.authcIDs)-1]
+}
+
+func TestGRPCAuthentication(t *testing.T) {
+	ctx := context.Background()
+	req := &healthpb.HealthCheckRequest{}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		ts := newTestServer(t)
+		_, err := ts.dial(t).Check(ctx, req)
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("error = %v", err)
+		}
+	})
+
+	for _, mechName := range []string{"SCRAM-SHA-256", "SCRAM-SHA-512", "PLAIN"} {
+		t.Run(mechName, func(t *testing.T) {
+			ts := newTestServer(t)
+			authn := grpcauth.NewClient(sasl.NewClient(),
+				grpcauth.WithClientMechanism(mechName),
+				grpcauth.WithClientOptions(mech.Username(sasltest.Username), mech.Password(sasltest.Password)),
+			)
+			client := ts.dial(t, authn.DialOptions()...)
+			for range 3 {
+				if _, err := client.Check(ctx, req); err != nil {
+					t.Fatal(err)
+				}
+			}
+			watch, err := client.Watch(ctx, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := watch.Recv(); err != nil {
+				t.Fatal(err)
+			}
+			if n := ts.authentications.Load(); n != 1 {
+				t.Errorf("authentications = %d, want 1", n)
+			}
+			if authcID := ts.lastAuthcID(); authcID != sasltest.Username {
+				t.Errorf("authcid = %q, want %q", authcID, sasltest.Username)
+			}
+		})
+	}
+
+	t.Run("invalid-password", func(t *testing.T) {
+		ts := newTestServer(t)
+		authn := grpcauth.NewClient(sasl.NewClient(),
+			grpcauth.WithClientOptions(mech.Username(sasltest.Username), mech.Password("invalid")),
+		)
+		_, err := ts.dial(t, authn.DialOptions()...).Check(ctx, req)
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("error = %v", err)
+		}
+	})
+
+	t.Run("unsupported-mechanism", func(t *testing.T) {
+		ts := newTestServer(t, grpcauth.WithServerMechanisms("SCRAM-SHA-256"))
+		authn := grpcauth.NewClient(sasl.NewClient(),
+			grpcauth.WithClientMechanism("PLAIN"),
+			grpcauth.WithClientOptions(mech.Username(sasltest.Username), mech.Password(sasltest.Password)),
+		)
+		_, err := ts.dial(t, authn.DialOptions()...).Check(ctx, req)
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("error = %v", err)
+		}
+	})
+
+	t.Run("per-rpc-credentials", func(t *testing.T) {
+		ts := newTestServer(t)
+		creds := grpcauth.NewPerRPCCredentials(sasl.NewClient(),
+			grpcauth.WithClientMechanism("PLAIN"),
+			grpcauth.WithClientOptions(mech.Username(sasltest.Username), mech.Password(sasltest.Password)),
+			grpcauth.WithClientTransportSecurity(false),
+		)
+		if _, err := ts.dial(t, grpc.WithPerRPCCredentials(creds)).Check(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+		if authcID := ts.lastAuthcID(); authcID != sasltest.Username {
+			t.Errorf("authcid = %q, want %q", authcID, sasltest.Username)
+		}
+		if n := ts.authentications.Load(); n != 0 {
+			t.Errorf("authentications = %d, want 0", n)
+		}
+
+		invalid := grpcauth.NewPerRPCCredentials(sasl.NewClient(),
+			grpcauth.WithClientMechanism("PLAIN"),
+			grpcauth.WithClientOptions(mech.Username(sasltest.Username), mech.Password("invalid")),
+			grpcauth.WithClientTransportSecurity(false),
+		)
+		_, err := ts.dial(t, grpc.WithPerRPCCredentials(invalid)).Check(ctx, req)
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("error = %v", err)
+		}
+
+		multiStep := grpcauth.NewPerRPCCredentials(sasl.NewClient(),
+			grpcauth.WithClientOptions(mech.Username(sasltest.Username), mech.Password(sasltest.Password)),
+			grpcauth.WithClientTransportSecurity(false),
+		)
+		if _, err := ts.dial(t, grpc.WithPerRPCCredentials(multiStep)).Check(ctx, req); err == nil {
+			t.Error("multi-step mechanism is accepted for per-RPC credentials")
+		}
+	})
+
+	t.Run("public-methods", func(t *testing.T) {
+		ts := newTestServer(t, grpcauth.WithServerPublicMethods(healthpb.Health_Check_FullMethodName))
+		if _, err := ts.dial(t).Check(ctx, req); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,332 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcauth
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// connState represents the authentication state of a connection.
+type connState struct {
+	sync.Mutex
+	addr   net.Addr
+	result mech.AuthResult
+}
+
+type connStateKey struct{}
+
+// RemoteAddr returns the remote address of the connection.
+func (state *connState) RemoteAddr() net.Addr {
+	return state.addr
+}
+
+func (state *connState) authResult() mech.AuthResult {
+	state.Lock()
+	defer state.Unlock()
+	return state.result
+}
+
+func (state *connState) setAuthResult(result mech.AuthResult) {
+	state.Lock()
+	defer state.Unlock()
+	state.result = result
+}
+
+type server struct {
+	mechs         []mech.Mechanism
+	mechNames     []string
+	opts          []mech.Option
+	publicMethods []string
+}
+
+// ServerOptionFn represents an option for a server authenticator.
+type ServerOptionFn func(*server)
+
+// NewServer returns a new gRPC server authenticator of the SASL server.
+func NewServer(saslServer sasl.Server, opts ...ServerOptionFn) (Server, error) {
+	s := &server{
+		mechs:         []mech.Mechanism{},
+		mechNames:     nil,
+		opts:          []mech.Option{},
+		publicMethods: []string{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	// The mechanisms are resolved once, because the SASL server sets the options to the shared mechanisms.
+
+	if s.mechNames == nil {
+		s.mechs = append(s.mechs, saslServer.Mechanisms()...)
+	} else {
+		for _, name := range s.mechNames {
+			m, err := saslServer.Mechanism(name)
+			if err != nil {
+				return nil, err
+			}
+			s.mechs = append(s.mechs, m)
+		}
+	}
+
+	return s, nil
+}
+
+// WithServerMechanisms returns an option to set the acceptable mechanisms. All mechanisms of the SASL server are accepted by default.
+func WithServerMechanisms(names ...string) ServerOptionFn {
+	return func(s *server) {
+		s.mechNames = names
+	}
+}
+
+// WithServerOptions returns an option to set the mechanism options to start the exchanges.
+func WithServerOptions(opts ...mech.Option) ServerOptionFn {
+	return func(s *server) {
+		s.opts = append(s.opts, opts...)
+	}
+}
+
+// WithServerPublicMethods returns an option to set the full method names which are called without authentication.
+func WithServerPublicMethods(methods ...string) ServerOptionFn {
+	return func(s *server) {
+		s.publicMethods = append(s.publicMethods, methods...)
+	}
+}
+
+// ServerOptions returns the gRPC server options to install the stats handler and the interceptors.
+func (s *server) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.StatsHandler(s),
+		grpc.ChainUnaryInterceptor(s.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(s.StreamServerInterceptor()),
+	}
+}
+
+// TagConn attaches the authentication state to the connection.
+func (s *server) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return context.WithValue(ctx, connStateKey{}, &connState{
+		Mutex:  sync.Mutex{},
+		addr:   info.RemoteAddr,
+		result: nil,
+	})
+}
+
+// HandleConn does nothing because the authentication state is released with the connection context.
+func (s *server) HandleConn(context.Context, stats.ConnStats) {}
+
+// TagRPC returns the context as is.
+func (s *server) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+// HandleRPC does nothing.
+func (s *server) HandleRPC(context.Context, stats.RPCStats) {}
+
+// UnaryServerInterceptor returns the unary interceptor which rejects unauthenticated calls.
+func (s *server) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := s.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns the stream interceptor which rejects unauthenticated calls.
+func (s *server) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := s.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// RegisterService registers the authentication service to the gRPC server.
+func (s *server) RegisterService(registrar grpc.ServiceRegistrar) {
+	registrar.RegisterService(&serviceDesc, s)
+}
+
+// authorize returns the context with the authentication result of the connection or the per-RPC credentials.
+func (s *server) authorize(ctx context.Context, method string) (context.Context, error) {
+	if method == AuthenticateMethod || slices.Contains(s.publicMethods, method) {
+		return ctx, nil
+	}
+	state, ok := ctx.Value(connStateKey{}).(*connState)
+	if !ok {
+		return nil, status.Error(codes.Internal, "stats handler is not installed")
+	}
+	if result := state.authResult(); result != nil {
+		return context.WithValue(ctx, authResultKey{}, result), nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	names := md.Get(MechMetadataKey)
+	tokens := md.Get(TokenMetadataKey)
+	if len(names) == 0 || len(tokens) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+	result, err := s.authenticateToken(state, names[0], []byte(tokens[0]))
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, authResultKey{}, result), nil
+}
+
+// authenticateToken verifies the initial client response of the per-RPC credentials, which must complete the exchange.
+func (s *server) authenticateToken(state *connState, name string, token []byte) (mech.AuthResult, error) {
+	sctx, err := s.start(state, name)
+	if err != nil {
+		return nil, err
+	}
+	defer sctx.Dispose()
+	if _, err := sctx.Next(token); err != nil {
+		return nil, statusError(err)
+	}
+	if !sctx.Done() {
+		return nil, status.Errorf(codes.Unauthenticated, "%s requires the authentication stream", name)
+	}
+	return authResultOf(sctx)
+}
+
+// authenticate runs the exchange of the authentication stream, and caches the result for the connection.
+func (s *server) authenticate(stream grpc.ServerStream) error {
+	ctx := stream.Context()
+	state, ok := ctx.Value(connStateKey{}).(*connState)
+	if !ok {
+		return status.Error(codes.Internal, "stats handler is not installed")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	names := md.Get(MechMetadataKey)
+	if len(names) == 0 {
+		return status.Error(codes.InvalidArgument, "no mechanism")
+	}
+	sctx, err := s.start(state, names[0])
+	if err != nil {
+		return err
+	}
+	defer sctx.Dispose()
+
+	for {
+		var in wrapperspb.BytesValue
+		if err := stream.RecvMsg(&in); err != nil {
+			return err
+		}
+		res, err := sctx.Next(in.GetValue())
+		if err != nil {
+			return statusError(err)
+		}
+		out := wrapperspb.Bytes(nil)
+		if res != nil {
+			out.Value = res.Bytes()
+		}
+		if sctx.Done() {
+			result, err := authResultOf(sctx)
+			if err != nil {
+				return err
+			}
+			state.setAuthResult(result)
+		}
+		if err := stream.SendMsg(out); err != nil {
+			return err
+		}
+		if sctx.Done() {
+			return nil
+		}
+	}
+}
+
+func (s *server) start(state *connState, name string) (mech.Context, error) {
+	idx := slices.IndexFunc(s.mechs, func(m mech.Mechanism) bool { return strings.EqualFold(m.Name(), name) })
+	if idx < 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "unsupported mechanism : %s", name)
+	}
+	sctx, err := s.mechs[idx].Start(append([]mech.Option{state}, s.opts...)...)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return sctx, nil
+}
+
+func authResultOf(ctx mech.Context) (mech.AuthResult, error) {
+	if resultCtx, ok := ctx.(mech.AuthResultContext); ok {
+		if result, ok := resultCtx.AuthResult(); ok {
+			return result, nil
+		}
+	}
+	return nil, status.Error(codes.Internal, "no authentication result")
+}
+
+// statusError returns the status error of the failure reason with the public message.
+func statusError(err error) error {
+	code := codes.Unauthenticated
+	msg := "authentication failed"
+	var saslErr *mech.Error
+	if errors.As(err, &saslErr) {
+		msg = saslErr.Message()
+		switch saslErr.Reason() {
+		case mech.ReasonAuthorizationDenied:
+			code = codes.PermissionDenied
+		case mech.ReasonTemporaryFailure:
+			code = codes.Unavailable
+		}
+	}
+	return status.Error(code, msg)
+}
+
+// serverStream represents a server stream with the authenticated context.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the authenticated context.
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*any)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Authenticate",
+			Handler:       authenticateHandler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "",
+}
+
+func authenticateHandler(srv any, stream grpc.ServerStream) error {
+	return srv.(*server).authenticate(stream)
+}
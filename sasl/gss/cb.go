@@ -14,6 +14,10 @@
 
 package gss
 
+import (
+	"strings"
+)
+
 // CBFlag represents a channel binding flag.
 type CBFlag rune
 
@@ -32,6 +36,26 @@ func (flag CBFlag) IsValid() bool {
 	return false
 }
 
+// isCBFlagProperty returns true if the property is a valid gs2-cb-flag.
+// gs2-cb-flag = ("p=" cb-name) / "n" / "y"
+// cb-name = 1*(ALPHA / DIGIT / "." / "-").
+func isCBFlagProperty(prop string) bool {
+	switch prop {
+	case string(ClientDoesNotSupportCBSFlag), string(ClientSupportsCBSFlag):
+		return true
+	}
+	name, ok := strings.CutPrefix(prop, string(ClientSupportsUsedCBSFlag)+"=")
+	if !ok || len(name) == 0 {
+		return false
+	}
+	for _, c := range name {
+		if !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && !('0' <= c && c <= '9') && c != '.' && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
 // String returns a string representation of the flag.
 func (flag CBFlag) String() string {
 	return string(flag)
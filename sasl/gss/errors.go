@@ -20,3 +20,15 @@ import (
 
 // ErrInvalidHeader is returned when the header is invalid.
 var ErrInvalidHeader = errors.New("invalid header")
+
+// ErrNoChannelBinding is returned when channel binding is required but the channel binding data is not available.
+var ErrNoChannelBinding = errors.New("no channel binding")
+
+// ErrChannelBindingDowngrade is returned when the client does not use channel binding although both peers support it.
+var ErrChannelBindingDowngrade = errors.New("channel binding downgrade")
+
+// ErrUnsupportedChannelBindingType is returned when the channel binding type is not supported.
+var ErrUnsupportedChannelBindingType = errors.New("unsupported channel binding type")
+
+// ErrContextNotEstablished is returned when the security context is not established.
+var ErrContextNotEstablished = errors.New("context not established")
@@ -0,0 +1,85 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gss
+
+import (
+	"errors"
+	"testing"
+)
+
+func FuzzHeader(f *testing.F) {
+	seeds := []string{
+		"",
+		"n,,",
+		"y,a=admin,",
+		"p=tls-unique,,",
+		"F,n,,",
+		"F",
+		"F,",
+		"F,n",
+		"p,,",
+		"p=,,",
+		"x,,",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, str string) {
+		header, err := NewHeaderFromString(str)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidHeader) {
+				t.Fatalf("%q: untyped error %v", str, err)
+			}
+			return
+		}
+		if !header.CBFlag().IsValid() {
+			t.Fatalf("%q: invalid flag %v", str, header.CBFlag())
+		}
+		header.CBName()
+		header.AuthzID()
+		header.ChannelBindingHeader()
+		reHeader, err := NewHeaderFromString(header.String())
+		if err != nil {
+			t.Fatalf("%q: %v", header.String(), err)
+		}
+		if !header.Equals(reHeader) {
+			t.Fatalf("%q != %q", header.String(), reHeader.String())
+		}
+	})
+}
+
+func FuzzSplitHeader(f *testing.F) {
+	seeds := [][]byte{
+		{},
+		[]byte("n,,token"),
+		[]byte("F,p=tls-exporter,a=user,\x00\x01"),
+		[]byte("F,n"),
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, msg []byte) {
+		header, data, err := SplitHeader(msg)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidHeader) {
+				t.Fatalf("%q: untyped error %v", msg, err)
+			}
+			return
+		}
+		if header.String()+string(data) != string(msg) {
+			t.Fatalf("%q != %q + %q", msg, header.String(), data)
+		}
+	})
+}
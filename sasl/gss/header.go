@@ -15,6 +15,7 @@
 package gss
 
 import (
+	"bytes"
 	"strings"
 
 	"github.com/cybergarage/go-sasl/sasl/util"
@@ -44,28 +45,56 @@ func NewHeaderFromStrings(props []string) (*Header, error) {
 	return header, header.ParseStrings(props)
 }
 
+// SplitHeader splits the message into the GS2 header and the following data.
+func SplitHeader(msg []byte) (*Header, []byte, error) {
+	n := GS2PropertyMaxCount - 1
+	if strings.HasPrefix(string(msg), GS2NonStdFlag+",") {
+		n = GS2PropertyMaxCount
+	}
+	end := 0
+	for range n {
+		idx := bytes.IndexByte(msg[end:], ',')
+		if idx < 0 {
+			return nil, nil, ErrInvalidHeader
+		}
+		end += idx + 1
+	}
+	header, err := NewHeaderFromString(string(msg[:end]))
+	if err != nil {
+		return nil, nil, err
+	}
+	return header, msg[end:], nil
+}
+
 // ParseString parses the header string.
 func (header *Header) ParseString(str string) error {
 	return header.ParseStrings(strings.Split(str, ","))
 }
 
-// ParseStrings parses the header property strings.
+// ParseStrings parses the header property strings which are split from the header string ending with ",".
+// The header is not changed if the properties are invalid.
 func (header *Header) ParseStrings(props []string) error {
-	header.props = []string{}
-	if len(props) < 2 {
+	if len(props) < GS2PropertyMaxCount {
 		return ErrInvalidHeader
 	}
+	headerProps := []string{""}
 	if props[0] != GS2NonStdFlag {
-		header.props = append(header.props, "")
-		header.props = append(header.props, props[:2]...)
+		headerProps = append(headerProps, props[:2]...)
 	} else {
-		header.props = append(header.props, props[:3]...)
+		if len(props) < GS2PropertyMaxCount+1 {
+			return ErrInvalidHeader
+		}
+		headerProps = append(headerProps[:0], props[:3]...)
+	}
+	if !isCBFlagProperty(headerProps[1]) {
+		return ErrInvalidHeader
 	}
-	if 0 < len(header.props[2]) {
-		if !strings.HasPrefix(header.props[2], GS2AuthzidPrefix) {
+	if 0 < len(headerProps[2]) {
+		if !strings.HasPrefix(headerProps[2], GS2AuthzidPrefix) {
 			return ErrInvalidHeader
 		}
 	}
+	header.props = headerProps
 	return nil
 }
 
@@ -134,6 +163,11 @@ func (header *Header) Equals(other *Header) bool {
 	return true
 }
 
+// ChannelBindingHeader returns the header without the non-standard flag, which is bound to the security context.
+func (header *Header) ChannelBindingHeader() string {
+	return header.props[1] + "," + header.props[2] + ","
+}
+
 // String returns the header properties.
 func (header *Header) String() string {
 	var str strings.Builder
@@ -121,3 +121,39 @@ func TestHeader(t *testing.T) {
 		}
 	}
 }
+
+func TestSplitHeader(t *testing.T) {
+	tests := []struct {
+		msg       string
+		headerStr string
+		cbHeader  string
+		data      string
+	}{
+		{"n,,token", "n,,", "n,,", "token"},
+		{"p=tls-exporter,a=user,\x00,token", "p=tls-exporter,a=user,", "p=tls-exporter,a=user,", "\x00,token"},
+		{"F,y,,", "F,y,,", "y,,", ""},
+	}
+
+	for _, test := range tests {
+		header, data, err := SplitHeader([]byte(test.msg))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if header.String() != test.headerStr {
+			t.Errorf("expected %v, got %v", test.headerStr, header.String())
+		}
+		if header.ChannelBindingHeader() != test.cbHeader {
+			t.Errorf("expected %v, got %v", test.cbHeader, header.ChannelBindingHeader())
+		}
+		if string(data) != test.data {
+			t.Errorf("expected %q, got %q", test.data, data)
+		}
+	}
+
+	for _, msg := range []string{"", "n", "n,", "F,n,"} {
+		if _, _, err := SplitHeader([]byte(msg)); err == nil {
+			t.Errorf("expected error for %q", msg)
+		}
+	}
+}
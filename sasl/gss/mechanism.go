@@ -0,0 +1,59 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gss
+
+// RFC 5801 - Using Generic Security Service Application Program Interface (GSS-API) Mechanisms
+// in Simple Authentication and Security Layer (SASL): The GS2 Mechanism Family
+// https://datatracker.ietf.org/doc/html/rfc5801
+
+// Mechanism represents a GSS-API mechanism which is bridged into SASL by the GS2 mechanism family.
+type Mechanism interface {
+	// Name returns the GS2 mechanism name without the "GS2-" prefix and the "-PLUS" suffix, such as "KRB5".
+	Name() string
+	// NewInitiator returns a new security context of the initiator with the mechanism options such as credentials and target names.
+	NewInitiator(opts ...any) (InitiatorContext, error)
+	// NewAcceptor returns a new security context of the acceptor with the mechanism options such as credential stores.
+	NewAcceptor(opts ...any) (AcceptorContext, error)
+}
+
+// SecContext represents a GSS-API security context.
+type SecContext interface {
+	// Wrap protects the message with the established context (GSS_Wrap).
+	// The message is encrypted if confidential is true (conf_req_flag), and is only integrity protected otherwise.
+	Wrap(msg []byte, confidential bool) ([]byte, error)
+	// Unwrap verifies the token and returns the message with the established context (GSS_Unwrap).
+	// It also returns true if the message was encrypted (conf_state).
+	Unwrap(token []byte) ([]byte, bool, error)
+	// Delete deletes the security context (GSS_Delete_sec_context).
+	Delete() error
+}
+
+// InitiatorContext represents a GSS-API security context of the initiator.
+type InitiatorContext interface {
+	SecContext
+	// InitSecContext processes the input token from the acceptor, which is nil at first, and returns the output token for the acceptor (GSS_Init_sec_context).
+	// The channel bindings are the application data to be bound to the context. It returns true when the context is established.
+	InitSecContext(input []byte, channelBindings []byte) ([]byte, bool, error)
+}
+
+// AcceptorContext represents a GSS-API security context of the acceptor.
+type AcceptorContext interface {
+	SecContext
+	// AcceptSecContext processes the input token from the initiator, and returns the output token for the initiator (GSS_Accept_sec_context).
+	// It must fail if the channel bindings of the initiator do not match. It returns true when the context is established.
+	AcceptSecContext(input []byte, channelBindings []byte) ([]byte, bool, error)
+	// SourceName returns the authenticated name of the initiator after the context is established.
+	SourceName() string
+}
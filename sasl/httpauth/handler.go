@@ -0,0 +1,326 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpauth
+
+import (
+	"context"
+	"crypto/rand"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+type authResultKey struct{}
+
+// AuthResultFrom returns the authentication result of the request authenticated by the handler.
+func AuthResultFrom(ctx context.Context) (mech.AuthResult, bool) {
+	result, ok := ctx.Value(authResultKey{}).(mech.AuthResult)
+	return result, ok
+}
+
+type handler struct {
+	next      http.Handler
+	server    sasl.Server
+	realm     string
+	mechNames []string
+	mechs     []mech.Mechanism
+	sealer    mech.TokenSealer
+	opts      []mech.Option
+}
+
+// HandlerOptionFn represents an option for a handler.
+type HandlerOptionFn func(*handler)
+
+// NewHandler returns a new handler which authenticates requests with the SASL authentication scheme before passing them to the next handler.
+// Multi-step mechanisms are suspended between requests into the s2s parameter, which is sealed by the token sealer.
+// If no token sealer is specified, a sealer with a random key is used, and the exchanges must be completed on the same handler.
+func NewHandler(server sasl.Server, next http.Handler, opts ...HandlerOptionFn) (http.Handler, error) {
+	h := &handler{
+		next:      next,
+		server:    server,
+		realm:     "",
+		mechNames: nil,
+		mechs:     []mech.Mechanism{},
+		sealer:    nil,
+		opts:      []mech.Option{},
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	// The mechanisms are resolved once, because the server sets the options to the shared mechanisms.
+
+	if h.mechNames == nil {
+		h.mechs = append(h.mechs, server.Mechanisms()...)
+		sortMechanisms(h.mechs)
+	} else {
+		for _, name := range h.mechNames {
+			m, err := server.Mechanism(name)
+			if err != nil {
+				return nil, err
+			}
+			h.mechs = append(h.mechs, m)
+		}
+	}
+
+	if h.sealer == nil {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		sealer, err := mech.NewTokenSealer(key)
+		if err != nil {
+			return nil, err
+		}
+		h.sealer = sealer
+	}
+
+	return h, nil
+}
+
+// mechanismRanks lists the well-known mechanisms from the strongest.
+var mechanismRanks = []string{
+	"SCRAM-SHA-512-PLUS",
+	"SCRAM-SHA-512",
+	"SCRAM-SHA-256-PLUS",
+	"SCRAM-SHA-256",
+	"SCRAM-SHA-1-PLUS",
+	"SCRAM-SHA-1",
+}
+
+// mechanismRank returns the rank of the mechanism to offer, where a lower rank is stronger.
+// The other mechanisms rank after the SCRAM mechanisms, followed by PLAIN and ANONYMOUS.
+func mechanismRank(name string) int {
+	if n := slices.Index(mechanismRanks, name); 0 <= n {
+		return n
+	}
+	switch name {
+	case "PLAIN":
+		return len(mechanismRanks) + 1
+	case "ANONYMOUS":
+		return len(mechanismRanks) + 2
+	}
+	return len(mechanismRanks)
+}
+
+// sortMechanisms sorts the mechanisms from the strongest, and the mechanisms of the same rank by name.
+func sortMechanisms(mechs []mech.Mechanism) {
+	slices.SortFunc(mechs, func(a, b mech.Mechanism) int {
+		if ra, rb := mechanismRank(a.Name()), mechanismRank(b.Name()); ra != rb {
+			return ra - rb
+		}
+		return strings.Compare(a.Name(), b.Name())
+	})
+}
+
+// WithHandlerRealm returns an option to set the realm of the challenges.
+func WithHandlerRealm(realm string) HandlerOptionFn {
+	return func(h *handler) {
+		h.realm = realm
+	}
+}
+
+// WithHandlerMechanisms returns an option to set the mechanisms offered to clients in the preferred order.
+// All mechanisms of the server are offered by default from the strongest, SCRAM first and PLAIN and ANONYMOUS last.
+func WithHandlerMechanisms(names ...string) HandlerOptionFn {
+	return func(h *handler) {
+		h.mechNames = names
+	}
+}
+
+// WithHandlerTokenSealer returns an option to set the token sealer of the s2s parameter.
+// The handlers behind a load balancer must share the same sealer key, and should share the replay cache of the sealer
+// because each s2s parameter can be resumed only once.
+func WithHandlerTokenSealer(sealer mech.TokenSealer) HandlerOptionFn {
+	return func(h *handler) {
+		h.sealer = sealer
+	}
+}
+
+// WithHandlerOptions returns an option to set the mechanism options to start or resume the exchanges.
+func WithHandlerOptions(opts ...mech.Option) HandlerOptionFn {
+	return func(h *handler) {
+		h.opts = append(h.opts, opts...)
+	}
+}
+
+// ServeHTTP authenticates the request, and passes it to the next handler if the exchange is completed successfully.
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	params, ok := headerParams(r.Header, authorizationHeader)
+	if !ok {
+		h.challenge(w)
+		return
+	}
+
+	m := h.mechanism(params[MechParam])
+	if m == nil {
+		h.challenge(w)
+		return
+	}
+
+	ctx, err := h.context(m, params, r)
+	if err != nil {
+		h.fail(w, nil, err)
+		return
+	}
+
+	c2s, hasC2S, err := params.Token(C2SParam)
+	if err != nil {
+		h.challenge(w)
+		return
+	}
+	var res mech.Response
+	if hasC2S {
+		res, err = ctx.Next(c2s)
+	} else {
+		res, err = ctx.Next()
+	}
+	if err != nil {
+		h.fail(w, res, err)
+		return
+	}
+
+	if !ctx.Done() {
+		h.continueChallenge(w, m, ctx, res)
+		return
+	}
+
+	if res != nil {
+		info := Params{}
+		info.SetToken(S2CParam, res.Bytes())
+		w.Header().Set(authenticationInfoHeader, info.String())
+	}
+	if resultCtx, ok := ctx.(mech.AuthResultContext); ok {
+		if result, ok := resultCtx.AuthResult(); ok {
+			r = r.WithContext(context.WithValue(r.Context(), authResultKey{}, result))
+		}
+	}
+	h.next.ServeHTTP(w, r)
+}
+
+// context starts a new exchange, or resumes the exchange from the s2s parameter.
+func (h *handler) context(m mech.Mechanism, params Params, r *http.Request) (mech.Context, error) {
+	opts := append([]mech.Option{newRequestConn(r)}, h.opts...)
+	s2s, hasS2S, err := params.Token(S2SParam)
+	if err != nil {
+		return nil, mech.ErrInvalidToken
+	}
+	if !hasS2S {
+		return m.Start(opts...)
+	}
+	resumable, ok := m.(mech.ResumableMechanism)
+	if !ok {
+		return nil, mech.ErrInvalidToken
+	}
+	return resumable.Resume(h.sealer, s2s, opts...)
+}
+
+func (h *handler) mechanism(name string) mech.Mechanism {
+	for _, m := range h.mechs {
+		if strings.EqualFold(m.Name(), name) {
+			return m
+		}
+	}
+	return nil
+}
+
+// challenge responds with a new challenge which offers the mechanisms.
+func (h *handler) challenge(w http.ResponseWriter) {
+	h.failChallenge(w, nil)
+}
+
+// failChallenge responds with a new challenge which offers the mechanisms,
+// and carries the final server message of the failed exchange, such as the SCRAM server-error, in the s2c parameter.
+func (h *handler) failChallenge(w http.ResponseWriter, res mech.Response) {
+	names := make([]string, len(h.mechs))
+	for n, m := range h.mechs {
+		names[n] = m.Name()
+	}
+	params := h.newParams()
+	params[MechParam] = strings.Join(names, " ")
+	if res != nil {
+		params.SetToken(S2CParam, res.Bytes())
+	}
+	w.Header().Set(authenticateHeader, params.String())
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+// continueChallenge responds with the next challenge of the exchange which carries the suspended context.
+func (h *handler) continueChallenge(w http.ResponseWriter, m mech.Mechanism, ctx mech.Context, res mech.Response) {
+	suspendable, ok := ctx.(mech.SuspendableContext)
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	token, err := suspendable.Suspend(h.sealer)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	params := h.newParams()
+	params[MechParam] = m.Name()
+	params.SetToken(S2SParam, token)
+	if res != nil {
+		params.SetToken(S2CParam, res.Bytes())
+	}
+	w.Header().Set(authenticateHeader, params.String())
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+// fail responds with the status of the failure reason, and the final server message of the exchange if any.
+func (h *handler) fail(w http.ResponseWriter, res mech.Response, err error) {
+	reason, _ := mech.ReasonOf(err)
+	switch {
+	case reason == mech.ReasonAuthorizationDenied:
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	case reason == mech.ReasonTemporaryFailure:
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+	default:
+		h.failChallenge(w, res)
+	}
+}
+
+func (h *handler) newParams() Params {
+	params := Params{}
+	if 0 < len(h.realm) {
+		params[RealmParam] = h.realm
+	}
+	return params
+}
+
+// requestConn represents the connection of a request for limiters and authorizers.
+type requestConn struct {
+	addr net.Addr
+}
+
+func newRequestConn(r *http.Request) *requestConn {
+	conn := &requestConn{
+		addr: nil,
+	}
+	if addr, err := netip.ParseAddrPort(r.RemoteAddr); err == nil {
+		conn.addr = net.TCPAddrFromAddrPort(addr)
+	}
+	return conn
+}
+
+// RemoteAddr returns the remote address of the request.
+func (conn *requestConn) RemoteAddr() net.Addr {
+	return conn.addr
+}
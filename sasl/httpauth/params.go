@@ -0,0 +1,161 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpauth
+
+import (
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// draft-vanrein-httpauth-sasl - HTTP Authentication with SASL
+// https://datatracker.ietf.org/doc/draft-vanrein-httpauth-sasl/
+
+const (
+	// Scheme is the HTTP authentication scheme name.
+	Scheme = "SASL"
+	// RealmParam is the realm parameter.
+	RealmParam = "realm"
+	// MechParam is the parameter of the mechanism list in challenges, or the selected mechanism in credentials.
+	MechParam = "mech"
+	// C2SParam is the parameter of the base64-encoded client-to-server token.
+	C2SParam = "c2s"
+	// S2CParam is the parameter of the base64-encoded server-to-client token.
+	S2CParam = "s2c"
+	// S2SParam is the parameter of the opaque server-to-server state, which the client returns as is.
+	S2SParam = "s2s"
+)
+
+const (
+	authenticateHeader       = "WWW-Authenticate"
+	authorizationHeader      = "Authorization"
+	authenticationInfoHeader = "Authentication-Info"
+)
+
+// Params represents the parameters of the SASL authentication scheme.
+type Params map[string]string
+
+// ParseParams parses the header value of the SASL authentication scheme.
+// It returns false if the header value is not of the SASL authentication scheme.
+func ParseParams(header string) (Params, bool) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	if !strings.EqualFold(scheme, Scheme) {
+		return nil, false
+	}
+	params := Params{}
+	for {
+		rest = strings.TrimLeft(rest, " \t,")
+		if len(rest) == 0 {
+			return params, true
+		}
+		idx := strings.IndexByte(rest, '=')
+		if idx <= 0 {
+			return nil, false
+		}
+		name := strings.ToLower(strings.TrimSpace(rest[:idx]))
+		rest = strings.TrimLeft(rest[idx+1:], " \t")
+		var value string
+		var ok bool
+		value, rest, ok = parseParamValue(rest)
+		if !ok {
+			return nil, false
+		}
+		params[name] = value
+	}
+}
+
+// parseParamValue parses a token or a quoted-string, and returns the value and the rest.
+func parseParamValue(str string) (string, string, bool) {
+	if !strings.HasPrefix(str, `"`) {
+		end := strings.IndexAny(str, ", \t")
+		if end < 0 {
+			return str, "", true
+		}
+		return str[:end], str[end:], true
+	}
+	var value strings.Builder
+	for i := 1; i < len(str); i++ {
+		switch c := str[i]; c {
+		case '\\':
+			i++
+			if len(str) <= i {
+				return "", "", false
+			}
+			value.WriteByte(str[i])
+		case '"':
+			return value.String(), str[i+1:], true
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", "", false
+}
+
+// String returns the header value of the SASL authentication scheme with the parameters.
+func (params Params) String() string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString(Scheme)
+	for n, name := range names {
+		if n == 0 {
+			b.WriteString(" ")
+		} else {
+			b.WriteString(", ")
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(params[name]))
+		b.WriteString(`"`)
+	}
+	return b.String()
+}
+
+// Token returns the base64-decoded value of the parameter, or false if the parameter is not specified.
+func (params Params) Token(name string) ([]byte, bool, error) {
+	value, ok := params[name]
+	if !ok {
+		return nil, false, nil
+	}
+	token, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, true, err
+	}
+	return token, true, nil
+}
+
+// SetToken sets the base64-encoded value of the parameter.
+func (params Params) SetToken(name string, token []byte) {
+	params[name] = base64.StdEncoding.EncodeToString(token)
+}
+
+// Mechanisms returns the mechanism names of the mech parameter.
+func (params Params) Mechanisms() []string {
+	return strings.Fields(params[MechParam])
+}
+
+// headerParams returns the parameters of the first header value of the SASL authentication scheme.
+func headerParams(header http.Header, name string) (Params, bool) {
+	for _, value := range header.Values(name) {
+		if params, ok := ParseParams(value); ok {
+			return params, true
+		}
+	}
+	return nil, false
+}
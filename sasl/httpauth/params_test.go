@@ -0,0 +1,72 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpauth
+
+import (
+	"testing"
+)
+
+func TestParams(t *testing.T) {
+	params, ok := ParseParams(`SASL realm="example.com", mech="SCRAM-SHA-256 PLAIN",s2s=abc+/=, c2s="a\"b\\c"`)
+	if !ok {
+		t.Fatal("SASL parameters are not parsed")
+	}
+	expected := Params{
+		RealmParam: "example.com",
+		MechParam:  "SCRAM-SHA-256 PLAIN",
+		S2SParam:   "abc+/=",
+		C2SParam:   `a"b\c`,
+	}
+	if len(params) != len(expected) {
+		t.Errorf("params = %v, want %v", params, expected)
+	}
+	for name, value := range expected {
+		if params[name] != value {
+			t.Errorf("%s = %q, want %q", name, params[name], value)
+		}
+	}
+	if mechs := params.Mechanisms(); len(mechs) != 2 || mechs[0] != "SCRAM-SHA-256" || mechs[1] != "PLAIN" {
+		t.Errorf("mechanisms = %v", mechs)
+	}
+
+	reparsed, ok := ParseParams(params.String())
+	if !ok {
+		t.Fatalf("%s is not parsed", params.String())
+	}
+	for name, value := range expected {
+		if reparsed[name] != value {
+			t.Errorf("%s = %q, want %q", name, reparsed[name], value)
+		}
+	}
+
+	for _, header := range []string{`Basic realm="example.com"`, `SASL realm="unterminated`, `SASL =value`, ``} {
+		if _, ok := ParseParams(header); ok {
+			t.Errorf("%q is parsed", header)
+		}
+	}
+	if params, ok := ParseParams("sasl"); !ok || len(params) != 0 {
+		t.Errorf("scheme without parameters : %v, %t", params, ok)
+	}
+
+	token := []byte{0x00, 0xff, 0x10}
+	params.SetToken(C2SParam, token)
+	decoded, ok, err := params.Token(C2SParam)
+	if err != nil || !ok || string(decoded) != string(token) {
+		t.Errorf("token = %x, %t, %v", decoded, ok, err)
+	}
+	if _, ok, _ := params.Token(S2CParam); ok {
+		t.Error("missing token is found")
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpauth
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+// DefaultMaxRounds is the default maximum number of authentication requests of an exchange.
+const DefaultMaxRounds = 8
+
+// ErrTooManyRounds is returned when the exchange is not completed within the maximum number of requests.
+var ErrTooManyRounds = errors.New("too many rounds")
+
+// ErrIncompleteExchange is returned when the server accepts the request before the client completes the exchange,
+// such as when the server does not send the final token to be verified by the client.
+var ErrIncompleteExchange = errors.New("incomplete exchange")
+
+type transport struct {
+	base      http.RoundTripper
+	client    sasl.Client
+	mechNames []string
+	opts      []mech.Option
+	maxRounds int
+}
+
+// TransportOptionFn represents an option for a transport.
+type TransportOptionFn func(*transport)
+
+// NewTransport returns a new round tripper which authenticates requests with the SASL authentication scheme
+// when the server responds with a SASL challenge.
+// Requests with a body are retried only if the body can be obtained again by GetBody.
+func NewTransport(client sasl.Client, opts ...TransportOptionFn) http.RoundTripper {
+	t := &transport{
+		base:      http.DefaultTransport,
+		client:    client,
+		mechNames: nil,
+		opts:      []mech.Option{},
+		maxRounds: DefaultMaxRounds,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// WithTransportBase returns an option to set the underlying round tripper.
+func WithTransportBase(base http.RoundTripper) TransportOptionFn {
+	return func(t *transport) {
+		t.base = base
+	}
+}
+
+// WithTransportMechanisms returns an option to set the acceptable mechanisms in the preferred order.
+// The first mechanism offered by the server is used by default.
+func WithTransportMechanisms(names ...string) TransportOptionFn {
+	return func(t *transport) {
+		t.mechNames = names
+	}
+}
+
+// WithTransportOptions returns an option to set the mechanism options such as the username and password.
+func WithTransportOptions(opts ...mech.Option) TransportOptionFn {
+	return func(t *transport) {
+		t.opts = append(t.opts, opts...)
+	}
+}
+
+// WithTransportMaxRounds returns an option to set the maximum number of authentication requests of an exchange.
+func WithTransportMaxRounds(n int) TransportOptionFn {
+	return func(t *transport) {
+		t.maxRounds = n
+	}
+}
+
+// RoundTrip sends the request, and runs the exchange if the server responds with a SASL challenge.
+// The response of the last request is returned as is if the exchange fails on the server.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.base.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return res, nil
+	}
+
+	params, ok := headerParams(res.Header, authenticateHeader)
+	if !ok {
+		return res, nil
+	}
+	m := t.mechanism(params.Mechanisms())
+	if m == nil {
+		return res, nil
+	}
+	ctx, err := m.Start(t.opts...)
+	if err != nil {
+		closeBody(res)
+		return nil, err
+	}
+	defer ctx.Dispose()
+
+	realm, hasRealm := params[RealmParam]
+
+	for range t.maxRounds {
+		s2c, hasS2C, err := params.Token(S2CParam)
+		if err != nil {
+			closeBody(res)
+			return nil, err
+		}
+		var out mech.Response
+		if hasS2C {
+			out, err = ctx.Next(s2c)
+		} else {
+			out, err = ctx.Next()
+		}
+		if err != nil {
+			closeBody(res)
+			return nil, err
+		}
+
+		cred := Params{
+			MechParam: m.Name(),
+		}
+		if hasRealm {
+			cred[RealmParam] = realm
+		}
+		if out != nil {
+			cred.SetToken(C2SParam, out.Bytes())
+		}
+		if s2s, ok := params[S2SParam]; ok {
+			cred[S2SParam] = s2s
+		}
+
+		authReq, err := rewindRequest(req)
+		if err != nil {
+			closeBody(res)
+			return nil, err
+		}
+		authReq.Header.Set(authorizationHeader, cred.String())
+
+		closeBody(res)
+		res, err = t.base.RoundTrip(authReq)
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode != http.StatusUnauthorized {
+			return t.complete(ctx, res)
+		}
+
+		// The server responds with a new challenge without the s2s parameter if the exchange fails.
+
+		params, ok = headerParams(res.Header, authenticateHeader)
+		if !ok {
+			return res, nil
+		}
+		if _, ok := params[S2SParam]; !ok {
+			return res, nil
+		}
+	}
+
+	closeBody(res)
+	return nil, ErrTooManyRounds
+}
+
+// complete validates the final server token in the Authentication-Info header if the exchange is not completed.
+// The successful response is rejected if the client cannot complete the exchange such as verifying the server signature.
+func (t *transport) complete(ctx mech.Context, res *http.Response) (*http.Response, error) {
+	if ctx.Done() || res.StatusCode < 200 || 299 < res.StatusCode {
+		return res, nil
+	}
+	info, _ := headerParams(res.Header, authenticationInfoHeader)
+	s2c, hasS2C, err := info.Token(S2CParam)
+	if err != nil || !hasS2C {
+		closeBody(res)
+		return nil, ErrIncompleteExchange
+	}
+	if _, err := ctx.Next(s2c); err != nil {
+		closeBody(res)
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *transport) mechanism(offered []string) mech.Mechanism {
+	names := offered
+	if t.mechNames != nil {
+		names = slices.DeleteFunc(slices.Clone(t.mechNames), func(name string) bool {
+			return !slices.ContainsFunc(offered, func(o string) bool { return strings.EqualFold(o, name) })
+		})
+	}
+	for _, name := range names {
+		if m, err := t.client.Mechanism(name); err == nil {
+			return m
+		}
+	}
+	return nil
+}
+
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	authReq := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		authReq.Body = body
+	}
+	return authReq, nil
+}
+
+func closeBody(res *http.Response) {
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+}
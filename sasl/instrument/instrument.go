@@ -0,0 +1,122 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instrument
+
+import (
+	"time"
+)
+
+// Operation represents an instrumented operation.
+type Operation string
+
+const (
+	// Exchange is the operation of an authentication exchange from the start to the completion or failure.
+	Exchange Operation = "exchange"
+	// CredentialLookup is the operation of a credential store lookup.
+	CredentialLookup Operation = "credential_lookup"
+	// CredentialVerification is the operation of a credential verification.
+	CredentialVerification Operation = "credential_verification"
+	// Authorization is the operation of an authorization.
+	Authorization Operation = "authorization"
+	// Hi is the operation of the SCRAM salted password computation (RFC 5802), whose cost is the iteration count.
+	Hi Operation = "hi"
+)
+
+const (
+	// Success is the result of an operation which succeeded.
+	Success = "success"
+	// Failure is the result of an operation which failed without an error, such as a lookup of an unknown user or an invalid password.
+	Failure = "failure"
+	// Error is the result of an operation which failed with an error.
+	Error = "error"
+)
+
+// Measurement represents a measurement of a completed operation.
+type Measurement struct {
+	// Operation is the operation.
+	Operation Operation
+	// Mechanism is the mechanism name, or an empty string if the operation is not specific to a mechanism.
+	Mechanism string
+	// Start is the time when the operation started.
+	Start time.Time
+	// Duration is the duration of the operation.
+	Duration time.Duration
+	// Result is Success, Failure, Error, or the failure reason of Exchange operations such as "invalid-credentials".
+	Result string
+}
+
+// Instrumentation represents an instrumentation which receives the measurements of operations.
+// Measure is called synchronously from the operation, and must be safe for concurrent use.
+type Instrumentation interface {
+	// Measure receives a measurement of a completed operation.
+	Measure(m *Measurement)
+}
+
+// InstrumentationFunc is an adapter to use an ordinary function as an instrumentation.
+type InstrumentationFunc func(m *Measurement)
+
+// Measure calls the function.
+func (fn InstrumentationFunc) Measure(m *Measurement) {
+	fn(m)
+}
+
+// Timer represents a timer of an operation.
+type Timer struct {
+	instrumentation Instrumentation
+	operation       Operation
+	mechanism       string
+	start           time.Time
+}
+
+// Start returns a new timer of the operation. If the instrumentation is nil, the timer does nothing without reading the clock.
+func Start(i Instrumentation, op Operation, mechanism string) Timer {
+	if i == nil {
+		return Timer{}
+	}
+	return Timer{
+		instrumentation: i,
+		operation:       op,
+		mechanism:       mechanism,
+		start:           time.Now(),
+	}
+}
+
+// Stop passes the measurement of the operation with the result to the instrumentation.
+func (timer Timer) Stop(result string) {
+	if timer.instrumentation == nil {
+		return
+	}
+	timer.instrumentation.Measure(&Measurement{
+		Operation: timer.operation,
+		Mechanism: timer.mechanism,
+		Start:     timer.start,
+		Duration:  time.Since(timer.start),
+		Result:    result,
+	})
+}
+
+type multiInstrumentation []Instrumentation
+
+// NewMultiInstrumentation returns a new instrumentation which passes the measurements to all the specified instrumentations.
+func NewMultiInstrumentation(instrumentations ...Instrumentation) Instrumentation {
+	return multiInstrumentation(instrumentations)
+}
+
+// Measure passes the measurement to all the instrumentations.
+func (instrumentations multiInstrumentation) Measure(m *Measurement) {
+	for _, i := range instrumentations {
+		i.Measure(m)
+	}
+}
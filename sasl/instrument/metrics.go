@@ -0,0 +1,202 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instrument
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultMetricsNamespace is the default namespace of the metric names.
+const DefaultMetricsNamespace = "sasl"
+
+// DefaultBuckets are the default upper bounds of the duration histogram buckets in seconds.
+var DefaultBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5}
+
+type seriesKey struct {
+	operation Operation
+	mechanism string
+	result    string
+}
+
+type series struct {
+	count   uint64
+	sum     float64
+	buckets []uint64
+}
+
+// Metrics represents an instrumentation which counts the operations and records the durations as histograms
+// in the Prometheus style, labeled with the operation, the mechanism and the result.
+type Metrics struct {
+	sync.Mutex
+	namespace string
+	buckets   []float64
+	series    map[seriesKey]*series
+}
+
+// MetricsOption represents a metrics option.
+type MetricsOption func(*Metrics)
+
+// NewMetrics returns a new metrics instrumentation.
+func NewMetrics(opts ...MetricsOption) *Metrics {
+	metrics := &Metrics{
+		Mutex:     sync.Mutex{},
+		namespace: DefaultMetricsNamespace,
+		buckets:   DefaultBuckets,
+		series:    map[seriesKey]*series{},
+	}
+	for _, opt := range opts {
+		opt(metrics)
+	}
+	return metrics
+}
+
+// WithMetricsNamespace returns a metrics option to set the namespace of the metric names.
+func WithMetricsNamespace(namespace string) MetricsOption {
+	return func(metrics *Metrics) {
+		metrics.namespace = namespace
+	}
+}
+
+// WithMetricsBuckets returns a metrics option to set the upper bounds of the duration histogram buckets in seconds.
+func WithMetricsBuckets(buckets ...float64) MetricsOption {
+	return func(metrics *Metrics) {
+		metrics.buckets = slices.Sorted(slices.Values(buckets))
+	}
+}
+
+// Measure counts the operation and records the duration.
+func (metrics *Metrics) Measure(m *Measurement) {
+	key := seriesKey{
+		operation: m.Operation,
+		mechanism: m.Mechanism,
+		result:    m.Result,
+	}
+	seconds := m.Duration.Seconds()
+
+	metrics.Lock()
+	defer metrics.Unlock()
+
+	s, ok := metrics.series[key]
+	if !ok {
+		s = &series{
+			count:   0,
+			sum:     0,
+			buckets: make([]uint64, len(metrics.buckets)),
+		}
+		metrics.series[key] = s
+	}
+	s.count++
+	s.sum += seconds
+	for n, bound := range metrics.buckets {
+		if seconds <= bound {
+			s.buckets[n]++
+		}
+	}
+}
+
+// Count returns the number of the operations of the mechanism with the result.
+func (metrics *Metrics) Count(op Operation, mechanism string, result string) uint64 {
+	metrics.Lock()
+	defer metrics.Unlock()
+	s, ok := metrics.series[seriesKey{operation: op, mechanism: mechanism, result: result}]
+	if !ok {
+		return 0
+	}
+	return s.count
+}
+
+// WriteTo writes the metrics to the writer in the Prometheus text exposition format.
+func (metrics *Metrics) WriteTo(w io.Writer) (int64, error) {
+	metrics.Lock()
+	defer metrics.Unlock()
+
+	keys := make([]seriesKey, 0, len(metrics.series))
+	for key := range metrics.series {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b seriesKey) int {
+		return cmp.Or(
+			cmp.Compare(a.operation, b.operation),
+			cmp.Compare(a.mechanism, b.mechanism),
+			cmp.Compare(a.result, b.result),
+		)
+	})
+
+	cw := &countWriter{w: w, n: 0}
+	bw := bufio.NewWriter(cw)
+
+	total := metrics.namespace + "_operations_total"
+	fmt.Fprintf(bw, "# HELP %s Total number of SASL operations.\n", total)
+	fmt.Fprintf(bw, "# TYPE %s counter\n", total)
+	for _, key := range keys {
+		fmt.Fprintf(bw, "%s{%s} %d\n", total, key.labels(), metrics.series[key].count)
+	}
+
+	duration := metrics.namespace + "_operation_duration_seconds"
+	fmt.Fprintf(bw, "# HELP %s Duration of SASL operations in seconds.\n", duration)
+	fmt.Fprintf(bw, "# TYPE %s histogram\n", duration)
+	for _, key := range keys {
+		s := metrics.series[key]
+		labels := key.labels()
+		for n, bound := range metrics.buckets {
+			fmt.Fprintf(bw, "%s_bucket{%s,le=\"%s\"} %d\n", duration, labels, formatFloat(bound), s.buckets[n])
+		}
+		fmt.Fprintf(bw, "%s_bucket{%s,le=\"+Inf\"} %d\n", duration, labels, s.count)
+		fmt.Fprintf(bw, "%s_sum{%s} %s\n", duration, labels, formatFloat(s.sum))
+		fmt.Fprintf(bw, "%s_count{%s} %d\n", duration, labels, s.count)
+	}
+
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (metrics *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = metrics.WriteTo(w)
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func (key seriesKey) labels() string {
+	return fmt.Sprintf(`operation="%s",mechanism="%s",result="%s"`,
+		labelValueReplacer.Replace(string(key.operation)),
+		labelValueReplacer.Replace(key.mechanism),
+		labelValueReplacer.Replace(key.result),
+	)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countWriter) Write(b []byte) (int, error) {
+	n, err := cw.w.Write(b)
+	cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,81 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instrument
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics(WithMetricsBuckets(0.01, 0.001))
+
+	measurements := []*Measurement{
+		{Operation: Exchange, Mechanism: "PLAIN", Start: time.Now(), Duration: 500 * time.Microsecond, Result: Success},
+		{Operation: Exchange, Mechanism: "PLAIN", Start: time.Now(), Duration: 5 * time.Millisecond, Result: Success},
+		{Operation: Exchange, Mechanism: "PLAIN", Start: time.Now(), Duration: time.Second, Result: "invalid-credentials"},
+		{Operation: Hi, Mechanism: "SCRAM-SHA-256", Start: time.Now(), Duration: 2 * time.Millisecond, Result: Success},
+	}
+	for _, m := range measurements {
+		metrics.Measure(m)
+	}
+
+	if n := metrics.Count(Exchange, "PLAIN", Success); n != 2 {
+		t.Errorf("Count() = %d, want 2", n)
+	}
+	if n := metrics.Count(Exchange, "PLAIN", Failure); n != 0 {
+		t.Errorf("Count() = %d, want 0", n)
+	}
+
+	var out bytes.Buffer
+	n, err := metrics.WriteTo(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(out.Len()) {
+		t.Errorf("WriteTo() = %d, want %d", n, out.Len())
+	}
+
+	expected := []string{
+		"# TYPE sasl_operations_total counter",
+		`sasl_operations_total{operation="exchange",mechanism="PLAIN",result="success"} 2`,
+		`sasl_operations_total{operation="exchange",mechanism="PLAIN",result="invalid-credentials"} 1`,
+		`sasl_operations_total{operation="hi",mechanism="SCRAM-SHA-256",result="success"} 1`,
+		"# TYPE sasl_operation_duration_seconds histogram",
+		`sasl_operation_duration_seconds_bucket{operation="exchange",mechanism="PLAIN",result="success",le="0.001"} 1`,
+		`sasl_operation_duration_seconds_bucket{operation="exchange",mechanism="PLAIN",result="success",le="0.01"} 2`,
+		`sasl_operation_duration_seconds_bucket{operation="exchange",mechanism="PLAIN",result="invalid-credentials",le="0.01"} 0`,
+		`sasl_operation_duration_seconds_bucket{operation="exchange",mechanism="PLAIN",result="invalid-credentials",le="+Inf"} 1`,
+		`sasl_operation_duration_seconds_sum{operation="exchange",mechanism="PLAIN",result="invalid-credentials"} 1`,
+		`sasl_operation_duration_seconds_count{operation="hi",mechanism="SCRAM-SHA-256",result="success"} 1`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("%q is not included :\n%s", line, out.String())
+		}
+	}
+}
+
+func TestNoInstrumentation(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		timer := Start(nil, Exchange, "PLAIN")
+		timer.Stop(Success)
+	})
+	if allocs != 0 {
+		t.Errorf("allocs = %f, want 0", allocs)
+	}
+}
@@ -0,0 +1,13 @@
+module github.com/cybergarage/go-sasl/sasl/instrument/oteltrace
+
+go 1.25.0
+
+require (
+	github.com/cybergarage/go-sasl v0.0.0-00010101000000-000000000000
+	go.opentelemetry.io/otel v1.44.0
+	go.opentelemetry.io/otel/trace v1.44.0
+)
+
+require github.com/cespare/xxhash/v2 v2.3.0 // indirect
+
+replace github.com/cybergarage/go-sasl => ../../..
@@ -0,0 +1,79 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oteltrace
+
+import (
+	"context"
+
+	"github.com/cybergarage/go-sasl/sasl/instrument"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// SpanNamePrefix is the prefix of the span names followed by the operation such as "sasl.exchange".
+	SpanNamePrefix = "sasl."
+	// MechanismKey is the span attribute key of the mechanism name.
+	MechanismKey = attribute.Key("sasl.mechanism")
+	// ResultKey is the span attribute key of the result.
+	ResultKey = attribute.Key("sasl.result")
+)
+
+// Tracer represents an instrumentation which records the measurements as OpenTelemetry spans.
+type Tracer struct {
+	tracer trace.Tracer
+	ctx    context.Context
+}
+
+// TracerOption represents a tracer option.
+type TracerOption func(*Tracer)
+
+// NewTracer returns a new instrumentation which records the measurements as spans of the tracer.
+func NewTracer(tracer trace.Tracer, opts ...TracerOption) *Tracer {
+	t := &Tracer{
+		tracer: tracer,
+		ctx:    context.Background(),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// WithTracerContext returns a tracer option to set the parent context of the spans.
+// The spans are root spans by default because the exchanges are not bound to contexts.
+func WithTracerContext(ctx context.Context) TracerOption {
+	return func(t *Tracer) {
+		t.ctx = ctx
+	}
+}
+
+// Measure records the measurement as a span which starts and ends at the measured times.
+// The span status is an error unless the operation succeeded.
+func (t *Tracer) Measure(m *instrument.Measurement) {
+	_, span := t.tracer.Start(t.ctx, SpanNamePrefix+string(m.Operation),
+		trace.WithTimestamp(m.Start),
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			MechanismKey.String(m.Mechanism),
+			ResultKey.String(m.Result),
+		),
+	)
+	if m.Result != instrument.Success {
+		span.SetStatus(codes.Error, m.Result)
+	}
+	span.End(trace.WithTimestamp(m.Start.Add(m.Duration)))
+}
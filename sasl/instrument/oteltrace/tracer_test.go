@@ -0,0 +1,93 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oteltrace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cybergarage/go-sasl/sasl/instrument"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type testSpan struct {
+	noop.Span
+	name   string
+	start  trace.SpanConfig
+	end    trace.SpanConfig
+	status codes.Code
+	desc   string
+}
+
+func (span *testSpan) SetStatus(code codes.Code, desc string) {
+	span.status = code
+	span.desc = desc
+}
+
+func (span *testSpan) End(opts ...trace.SpanEndOption) {
+	span.end = trace.NewSpanEndConfig(opts...)
+}
+
+type testTracer struct {
+	noop.Tracer
+	spans []*testSpan
+}
+
+func (tracer *testTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	span := &testSpan{name: name, start: trace.NewSpanStartConfig(opts...)}
+	tracer.spans = append(tracer.spans, span)
+	return ctx, span
+}
+
+func TestTracer(t *testing.T) {
+	tracer := &testTracer{}
+	instrumentation := NewTracer(tracer)
+
+	start := time.Now()
+	tests := []struct {
+		m      *instrument.Measurement
+		status codes.Code
+	}{
+		{&instrument.Measurement{Operation: instrument.Exchange, Mechanism: "PLAIN", Start: start, Duration: time.Millisecond, Result: instrument.Success}, codes.Unset},
+		{&instrument.Measurement{Operation: instrument.Hi, Mechanism: "SCRAM-SHA-1", Start: start, Duration: time.Second, Result: instrument.Error}, codes.Error},
+	}
+
+	for n, test := range tests {
+		instrumentation.Measure(test.m)
+		span := tracer.spans[n]
+		if span.name != SpanNamePrefix+string(test.m.Operation) {
+			t.Errorf("name = %s", span.name)
+		}
+		if !span.start.Timestamp().Equal(test.m.Start) {
+			t.Errorf("start = %v, want %v", span.start.Timestamp(), test.m.Start)
+		}
+		if !span.end.Timestamp().Equal(test.m.Start.Add(test.m.Duration)) {
+			t.Errorf("end = %v, want %v", span.end.Timestamp(), test.m.Start.Add(test.m.Duration))
+		}
+		attrs := map[string]string{}
+		for _, attr := range span.start.Attributes() {
+			attrs[string(attr.Key)] = attr.Value.AsString()
+		}
+		if attrs[string(MechanismKey)] != test.m.Mechanism || attrs[string(ResultKey)] != test.m.Result {
+			t.Errorf("attributes = %v", attrs)
+		}
+		if span.status != test.status {
+			t.Errorf("status = %v, want %v", span.status, test.status)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"errors"
+)
+
+// ErrorReason represents a protocol independent reason of an authentication failure.
+// Protocol adapters map the reasons to their own failure codes such as SMTP reply codes or LDAP result codes.
+type ErrorReason int
+
+const (
+	// ReasonMalformed represents a malformed or unexpected message.
+	ReasonMalformed ErrorReason = iota + 1
+	// ReasonUnsupportedMechanism represents an unsupported mechanism.
+	ReasonUnsupportedMechanism
+	// ReasonInvalidCredentials represents invalid credentials or an unknown user.
+	ReasonInvalidCredentials
+	// ReasonTemporaryFailure represents a temporary failure such as an unavailable credential store.
+	ReasonTemporaryFailure
+	// ReasonAuthorizationDenied represents an authorization identity which the authenticated identity is not allowed to act as.
+	ReasonAuthorizationDenied
+	// ReasonAborted represents an exchange aborted by the client.
+	ReasonAborted
+	// ReasonEncryptionRequired represents a mechanism which is not allowed without an encrypted connection.
+	ReasonEncryptionRequired
+)
+
+// ErrMalformed is the error that is returned when a message is malformed or unexpected.
+var ErrMalformed = errors.New("malformed")
+
+// ErrUnsupportedMechanism is the error that is returned when a mechanism is not supported.
+var ErrUnsupportedMechanism = errors.New("unsupported-mechanism")
+
+// ErrInvalidCredentials is the error that is returned when credentials are invalid.
+var ErrInvalidCredentials = errors.New("invalid-credentials")
+
+// ErrTemporaryFailure is the error that is returned when authentication fails temporarily.
+var ErrTemporaryFailure = errors.New("temporary-failure")
+
+// ErrAuthorizationDenied is the error that is returned when the authorization identity is not allowed.
+var ErrAuthorizationDenied = errors.New("authorization-denied")
+
+// ErrAborted is the error that is returned when an exchange is aborted.
+var ErrAborted = errors.New("aborted")
+
+// ErrEncryptionRequired is the error that is returned when an encrypted connection is required.
+var ErrEncryptionRequired = errors.New("encryption-required")
+
+var reasonErrors = map[ErrorReason]error{
+	ReasonMalformed:            ErrMalformed,
+	ReasonUnsupportedMechanism: ErrUnsupportedMechanism,
+	ReasonInvalidCredentials:   ErrInvalidCredentials,
+	ReasonTemporaryFailure:     ErrTemporaryFailure,
+	ReasonAuthorizationDenied:  ErrAuthorizationDenied,
+	ReasonAborted:              ErrAborted,
+	ReasonEncryptionRequired:   ErrEncryptionRequired,
+}
+
+var reasonMessages = map[ErrorReason]string{
+	ReasonMalformed:            "malformed message",
+	ReasonUnsupportedMechanism: "unsupported mechanism",
+	ReasonInvalidCredentials:   "authentication failed",
+	ReasonTemporaryFailure:     "temporary authentication failure",
+	ReasonAuthorizationDenied:  "authorization denied",
+	ReasonAborted:              "authentication aborted",
+	ReasonEncryptionRequired:   "encryption required",
+}
+
+// String returns the string representation of the reason.
+func (reason ErrorReason) String() string {
+	if err, ok := reasonErrors[reason]; ok {
+		return err.Error()
+	}
+	return "unknown"
+}
+
+// Err returns the sentinel error of the reason, or nil if the reason is unknown.
+func (reason ErrorReason) Err() error {
+	return reasonErrors[reason]
+}
+
+// ReasonOf returns the reason of the specified error if the error is or wraps an Error.
+func ReasonOf(err error) (ErrorReason, bool) {
+	var saslErr *Error
+	if !errors.As(err, &saslErr) {
+		return 0, false
+	}
+	return saslErr.reason, true
+}
@@ -0,0 +1,120 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"strings"
+)
+
+// Error represents an authentication failure with the reason, the mechanism name, the step and a public message.
+// The public message is safe to send to clients, and the wrapped error keeps the internal details for logging.
+type Error struct {
+	reason    ErrorReason
+	mechanism string
+	step      int
+	message   string
+	err       error
+}
+
+// ErrorOption represents an option for an error.
+type ErrorOption func(*Error)
+
+// NewError returns a new error with the specified reason and options.
+func NewError(reason ErrorReason, opts ...ErrorOption) *Error {
+	err := &Error{
+		reason:    reason,
+		mechanism: "",
+		step:      0,
+		message:   reasonMessages[reason],
+		err:       nil,
+	}
+	for _, opt := range opts {
+		opt(err)
+	}
+	return err
+}
+
+// WithErrorMechanism returns an option to set the mechanism name.
+func WithErrorMechanism(name string) ErrorOption {
+	return func(err *Error) {
+		err.mechanism = name
+	}
+}
+
+// WithErrorStep returns an option to set the step number where the error occurred.
+func WithErrorStep(step int) ErrorOption {
+	return func(err *Error) {
+		err.step = step
+	}
+}
+
+// WithErrorMessage returns an option to set the public message which is safe to send to clients.
+func WithErrorMessage(msg string) ErrorOption {
+	return func(err *Error) {
+		err.message = msg
+	}
+}
+
+// WithErrorCause returns an option to set the underlying error.
+func WithErrorCause(cause error) ErrorOption {
+	return func(err *Error) {
+		err.err = cause
+	}
+}
+
+// Reason returns the failure reason.
+func (err *Error) Reason() ErrorReason {
+	return err.reason
+}
+
+// Mechanism returns the mechanism name, or an empty string if the error is not specific to a mechanism.
+func (err *Error) Mechanism() string {
+	return err.mechanism
+}
+
+// Step returns the step number where the error occurred.
+func (err *Error) Step() int {
+	return err.step
+}
+
+// Message returns the public message which is safe to send to clients.
+func (err *Error) Message() string {
+	return err.message
+}
+
+// Error returns the error message including the underlying error.
+func (err *Error) Error() string {
+	var b strings.Builder
+	if 0 < len(err.mechanism) {
+		b.WriteString(err.mechanism)
+		b.WriteString(" : ")
+	}
+	b.WriteString(err.message)
+	if err.err != nil && err.err.Error() != err.message {
+		b.WriteString(" : ")
+		b.WriteString(err.err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the underlying error.
+func (err *Error) Unwrap() error {
+	return err.err
+}
+
+// Is returns true if the target is the sentinel error of the reason.
+func (err *Error) Is(target error) bool {
+	return target != nil && target == err.reason.Err()
+}
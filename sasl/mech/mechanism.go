@@ -51,6 +51,12 @@ type Context interface {
 	Dispose() error
 }
 
+// AuthorizedContext represents a SASL mechanism context which exposes the authorized identity.
+type AuthorizedContext interface {
+	// AuthorizedID returns the identity associated with the connection after the context is completed successfully.
+	AuthorizedID() string
+}
+
 // Mechanism represents a SASL mechanism.
 type Mechanism interface {
 	// Name returns the mechanism name.
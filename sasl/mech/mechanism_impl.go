@@ -0,0 +1,49 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+// ContextAs returns the context as T if the context or any context wrapped by it implements T.
+// Context wrappers such as audit contexts return the wrapped context with Unwrap() Context.
+func ContextAs[T any](ctx Context) (T, bool) {
+	for ctx != nil {
+		if v, ok := ctx.(T); ok {
+			return v, true
+		}
+		wrapper, ok := ctx.(interface{ Unwrap() Context })
+		if !ok {
+			break
+		}
+		ctx = wrapper.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+// MechanismAs returns the mechanism as T if the mechanism or any mechanism wrapped by it implements T.
+// Mechanism wrappers such as audit mechanisms return the wrapped mechanism with Unwrap() Mechanism.
+func MechanismAs[T any](m Mechanism) (T, bool) {
+	for m != nil {
+		if v, ok := m.(T); ok {
+			return v, true
+		}
+		wrapper, ok := m.(interface{ Unwrap() Mechanism })
+		if !ok {
+			break
+		}
+		m = wrapper.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
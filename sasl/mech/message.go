@@ -0,0 +1,25 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"errors"
+)
+
+// DefaultMaxMessageSize is the default maximum size in bytes of a mechanism message which carries opaque tokens.
+const DefaultMaxMessageSize = 65536
+
+// ErrMessageTooLarge is returned when a message exceeds the maximum size of the mechanism.
+var ErrMessageTooLarge = errors.New("message too large")
@@ -56,3 +56,15 @@ type Challenge []byte
 
 // Salt represents a salt.
 type Salt []byte
+
+// SecondFactor represents a second factor such as a one-time password.
+type SecondFactor string
+
+// ChannelBinding represents the channel binding type and data of the underlying secure channel such as "tls-exporter".
+type ChannelBinding struct {
+	Type string
+	Data []byte
+}
+
+// MockSecret represents a server secret to continue exchanges for unknown users not to disclose which users exist.
+type MockSecret []byte
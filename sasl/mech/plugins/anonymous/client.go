@@ -40,7 +40,7 @@ func NewClientContext(m mech.Mechanism, opts ...mech.Option) (*ClientContext, er
 	}
 
 	if err := ctx.setOptions(opts...); err != nil {
-		return nil, err
+		return nil, newError(mech.ReasonMalformed, Type, ctx.step, err)
 	}
 
 	return ctx, nil
@@ -78,16 +78,19 @@ func (ctx *ClientContext) Next(opts ...mech.Parameter) (mech.Response, error) {
 	switch ctx.step {
 	case 0:
 		if err := ctx.setOptions(opts...); err != nil {
-			return nil, err
+			return nil, newError(mech.ReasonMalformed, Type, ctx.step, err)
 		}
 		msg, err := NewMessageFrom(ctx.msg)
 		if err != nil {
-			return nil, err
+			return nil, newError(mech.ReasonMalformed, Type, ctx.step, err)
+		}
+		if err := ValidateTrace(msg.String(), AnyTrace); err != nil {
+			return nil, newError(mech.ReasonMalformed, Type, ctx.step, err)
 		}
 		ctx.step++
 		return msg, nil
 	}
-	return nil, fmt.Errorf("invalid step : %d", ctx.step)
+	return nil, newError(mech.ReasonMalformed, Type, ctx.step, fmt.Errorf("invalid step : %d", ctx.step))
 }
 
 // Dispose disposes the context.
@@ -0,0 +1,32 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anonymous
+
+import (
+	"errors"
+
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+// ErrInvalidTrace is returned when the trace information is invalid.
+var ErrInvalidTrace = errors.New("invalid trace")
+
+func newError(reason mech.ErrorReason, name string, step int, err error) error {
+	return mech.NewError(reason,
+		mech.WithErrorMechanism(name),
+		mech.WithErrorStep(step),
+		mech.WithErrorCause(err),
+	)
+}
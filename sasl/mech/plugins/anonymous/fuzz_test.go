@@ -0,0 +1,50 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anonymous
+
+import (
+	"errors"
+	"testing"
+	"unicode/utf8"
+)
+
+func FuzzValidateTrace(f *testing.F) {
+	seeds := []string{
+		"",
+		"sirhc",
+		"sirhc@example.com",
+		"User <user@example.com>",
+		"\xff\xfe",
+	}
+	for _, seed := range seeds {
+		f.Add(seed, false)
+	}
+	f.Fuzz(func(t *testing.T, trace string, email bool) {
+		format := AnyTrace
+		if email {
+			format = EmailTrace
+		}
+		err := ValidateTrace(trace, format)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidTrace) {
+				t.Fatalf("%q: untyped error %v", trace, err)
+			}
+			return
+		}
+		if !utf8.ValidString(trace) || MaxTraceLength < utf8.RuneCountInString(trace) {
+			t.Fatalf("%q: invalid trace is accepted", trace)
+		}
+	})
+}
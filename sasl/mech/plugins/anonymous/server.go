@@ -15,26 +15,50 @@
 package anonymous
 
 import (
+	"errors"
 	"fmt"
 	"slices"
 
+	"github.com/cybergarage/go-sasl/sasl/auth"
 	"github.com/cybergarage/go-sasl/sasl/mech"
 )
 
-// ServerContext represents a PLAIN server context.
+// ServerContext represents an ANONYMOUS server context.
 type ServerContext struct {
 	mechanism mech.Mechanism
 	mech.Store
-	step int
+	format TraceFormat
+	policy Policy
+	conn   auth.Conn
+	trace  string
+	step   int
+	result mech.AuthResult
 }
 
-// NewServerContext returns a new PLAIN server context.
+// NewServerContext returns a new ANONYMOUS server context.
 func NewServerContext(m mech.Mechanism, opts ...mech.Option) (*ServerContext, error) {
 	ctx := &ServerContext{
 		mechanism: m,
 		Store:     mech.NewStore(),
+		format:    AnyTrace,
+		policy:    nil,
+		conn:      nil,
+		trace:     "",
 		step:      0,
+		result:    nil,
 	}
+
+	for _, opt := range opts {
+		switch v := opt.(type) {
+		case TraceFormat:
+			ctx.format = v
+		case Policy:
+			ctx.policy = v
+		case auth.Conn:
+			ctx.conn = v
+		}
+	}
+
 	return ctx, nil
 }
 
@@ -58,17 +82,49 @@ func (ctx *ServerContext) Next(opts ...mech.Parameter) (mech.Response, error) {
 	switch ctx.step {
 	case 0:
 		if len(opts) == 0 {
-			return nil, fmt.Errorf("no message")
+			return nil, newError(mech.ReasonMalformed, Type, ctx.step, errors.New("no message"))
 		}
-		_, err := NewMessageFrom(opts[0])
+		msg, err := NewMessageFrom(opts[0])
 		if err != nil {
-			return nil, err
+			return nil, newError(mech.ReasonMalformed, Type, ctx.step, err)
 		}
+		if err := ValidateTrace(msg.String(), ctx.format); err != nil {
+			return nil, newError(mech.ReasonMalformed, Type, ctx.step, err)
+		}
+		if ctx.policy != nil {
+			if err := ctx.policy(ctx.conn, msg.String()); err != nil {
+				return nil, newError(mech.ReasonAuthorizationDenied, Type, ctx.step, err)
+			}
+		}
+		ctx.trace = msg.String()
+		ctx.result = mech.NewAuthResult(
+			mech.WithAuthResultAuthzID(Identity),
+			mech.WithAuthResultMechanism(Type),
+			mech.WithAuthResultAttribute(TraceAttr, msg.String()),
+		)
 		ctx.step++
 		return nil, nil
 	}
 
-	return nil, fmt.Errorf("invalid step : %d", ctx.step)
+	return nil, newError(mech.ReasonMalformed, Type, ctx.step, fmt.Errorf("invalid step : %d", ctx.step))
+}
+
+// Trace returns the trace information sent by the client after the context is completed successfully.
+func (ctx *ServerContext) Trace() string {
+	return ctx.trace
+}
+
+// AuthorizedID returns the identity associated with the connection after the context is completed successfully.
+func (ctx *ServerContext) AuthorizedID() string {
+	if ctx.result == nil {
+		return ""
+	}
+	return ctx.result.AuthzID()
+}
+
+// AuthResult returns the authentication result, or false if the context is not completed successfully.
+func (ctx *ServerContext) AuthResult() (mech.AuthResult, bool) {
+	return ctx.result, ctx.result != nil
 }
 
 // Dispose disposes the context.
@@ -76,7 +132,7 @@ func (ctx *ServerContext) Dispose() error {
 	return nil
 }
 
-// Server represents a PLAIN mech.
+// Server represents an ANONYMOUS mech.
 type Server struct {
 	opts []mech.Option
 }
@@ -99,7 +155,7 @@ func (server *Server) Type() mech.Type {
 
 // SetOptions sets the mechanism options before starting.
 func (server *Server) SetOptions(opts ...mech.Option) error {
-	server.opts = append(server.opts, opts...)
+	server.opts = opts
 	return nil
 }
 
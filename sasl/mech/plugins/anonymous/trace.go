@@ -0,0 +1,68 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anonymous
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cybergarage/go-sasl/sasl/auth"
+)
+
+// RFC 4505 section 3: The trace information is an email address (addr-spec) or an opaque token
+// which does not include "@", and is limited to 255 UTF-8 characters.
+
+// MaxTraceLength is the maximum number of characters of the trace information.
+const MaxTraceLength = 255
+
+// TraceFormat represents a server option of the accepted trace information format.
+type TraceFormat int
+
+const (
+	// AnyTrace accepts an email address or an opaque token.
+	AnyTrace TraceFormat = iota
+	// EmailTrace accepts only an email address.
+	EmailTrace
+)
+
+// Policy represents a server option which decides whether the anonymous login with the trace information is allowed on the connection.
+// It returns an error to reject the login.
+type Policy func(conn auth.Conn, trace string) error
+
+// ValidateTrace returns nil if the trace information is valid for the format.
+func ValidateTrace(trace string, format TraceFormat) error {
+	if MaxTraceLength*utf8.UTFMax < len(trace) {
+		return fmt.Errorf("%w : %d bytes", ErrInvalidTrace, len(trace))
+	}
+	if !utf8.ValidString(trace) {
+		return fmt.Errorf("%w : invalid UTF-8", ErrInvalidTrace)
+	}
+	if n := utf8.RuneCountInString(trace); n < 1 || MaxTraceLength < n {
+		return fmt.Errorf("%w : %d characters", ErrInvalidTrace, n)
+	}
+	if !strings.Contains(trace, "@") {
+		if format == EmailTrace {
+			return fmt.Errorf("%w : not an email address", ErrInvalidTrace)
+		}
+		return nil
+	}
+	addr, err := mail.ParseAddress(trace)
+	if err != nil || addr.Address != trace {
+		return fmt.Errorf("%w : invalid email address", ErrInvalidTrace)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anonymous
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateTrace(t *testing.T) {
+	tests := []struct {
+		trace  string
+		format TraceFormat
+		valid  bool
+	}{
+		{"sirhc", AnyTrace, true},
+		{"user@example.com", AnyTrace, true},
+		{"user@example.com", EmailTrace, true},
+		{"ユーザー", AnyTrace, true},
+		{strings.Repeat("あ", MaxTraceLength), AnyTrace, true},
+		{"sirhc", EmailTrace, false},
+		{"", AnyTrace, false},
+		{strings.Repeat("a", MaxTraceLength+1), AnyTrace, false},
+		{"\xff\xfe", AnyTrace, false},
+		{"user@", AnyTrace, false},
+		{"@example.com", AnyTrace, false},
+		{"User <user@example.com>", AnyTrace, false},
+	}
+
+	for _, test := range tests {
+		err := ValidateTrace(test.trace, test.format)
+		if test.valid && err != nil {
+			t.Errorf("%q: %v", test.trace, err)
+		}
+		if !test.valid && !errors.Is(err, ErrInvalidTrace) {
+			t.Errorf("%q: %v is not %v", test.trace, err, ErrInvalidTrace)
+		}
+	}
+}
@@ -15,3 +15,10 @@
 package anonymous
 
 const Type = "ANONYMOUS"
+
+const (
+	// Identity is the authorization identity associated with an anonymous connection.
+	Identity = "anonymous"
+	// TraceAttr is the authentication result attribute name of the trace information.
+	TraceAttr = "trace"
+)
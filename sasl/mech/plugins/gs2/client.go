@@ -0,0 +1,225 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gs2
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/cybergarage/go-sasl/sasl/gss"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+// ClientContext represents a GS2 client context.
+type ClientContext struct {
+	mechanism *Client
+	mech.Store
+	username    string
+	authzID     string
+	cb          *mech.ChannelBinding
+	header      *gss.Header
+	sec         gss.InitiatorContext
+	established bool
+	step        int
+	result      mech.AuthResult
+}
+
+// NewClientContext returns a new GS2 client context.
+func NewClientContext(client *Client, opts ...mech.Option) (*ClientContext, error) {
+	ctx := &ClientContext{
+		mechanism:   client,
+		Store:       mech.NewStore(),
+		username:    "",
+		authzID:     "",
+		cb:          nil,
+		header:      nil,
+		sec:         nil,
+		established: false,
+		step:        0,
+		result:      nil,
+	}
+
+	for _, opt := range opts {
+		switch v := opt.(type) {
+		case mech.Username:
+			ctx.username = string(v)
+		case mech.AuthzID:
+			ctx.authzID = string(v)
+		case mech.ChannelBinding:
+			ctx.cb = &v
+		case *mech.ChannelBinding:
+			ctx.cb = v
+		}
+	}
+
+	if client.plus && ctx.cb == nil {
+		return nil, newErrorFromChannelBinding(client.Name(), ctx.step, gss.ErrNoChannelBinding)
+	}
+
+	sec, err := client.gssMech.NewInitiator(opts...)
+	if err != nil {
+		return nil, newError(mech.ReasonInvalidCredentials, client.Name(), ctx.step, err)
+	}
+	ctx.sec = sec
+
+	return ctx, nil
+}
+
+// Mechanism returns the mechanism.
+func (ctx *ClientContext) Mechanism() mech.Mechanism {
+	return ctx.mechanism
+}
+
+// Done returns true if the context is completed.
+func (ctx *ClientContext) Done() bool {
+	return ctx.established
+}
+
+// Step returns the current step number. The step number is incremented by one after each call to Next.
+func (ctx *ClientContext) Step() int {
+	return ctx.step
+}
+
+// Next returns the next response.
+func (ctx *ClientContext) Next(opts ...mech.Parameter) (mech.Response, error) {
+	name := ctx.mechanism.Name()
+	if ctx.established {
+		return nil, newError(mech.ReasonMalformed, name, ctx.step, fmt.Errorf("invalid step : %d", ctx.step))
+	}
+
+	var header *gss.Header
+	var input []byte
+	switch ctx.step {
+	case 0:
+		header = gss.NewHeader()
+		switch {
+		case ctx.mechanism.plus:
+			header.SetCBFlagWithName(gss.ClientSupportsUsedCBSFlag, ctx.cb.Type)
+		case ctx.cb != nil:
+			header.SetCBFlag(gss.ClientSupportsCBSFlag)
+		default:
+			header.SetCBFlag(gss.ClientDoesNotSupportCBSFlag)
+		}
+		if 0 < len(ctx.authzID) {
+			header.SetAuthzID(ctx.authzID)
+		}
+		ctx.header = header
+	default:
+		if len(opts) == 0 {
+			return nil, newError(mech.ReasonMalformed, name, ctx.step, errors.New("no message"))
+		}
+		msg, err := NewMessageFrom(opts[0], false)
+		if err != nil {
+			return nil, newError(mech.ReasonMalformed, name, ctx.step, err)
+		}
+		input = msg.Token()
+	}
+
+	output, established, err := ctx.sec.InitSecContext(input, channelBindings(ctx.header, ctx.cb))
+	if err != nil {
+		return nil, newError(mech.ReasonInvalidCredentials, name, ctx.step, err)
+	}
+
+	if established {
+		ctx.established = true
+		resultOpts := []mech.AuthResultOption{
+			mech.WithAuthResultAuthcID(ctx.username),
+			mech.WithAuthResultAuthzID(ctx.authzID),
+			mech.WithAuthResultMechanism(name),
+		}
+		if ctx.mechanism.plus {
+			resultOpts = append(resultOpts, mech.WithAuthResultChannelBinding(ctx.cb.Type))
+		}
+		ctx.result = mech.NewAuthResult(resultOpts...)
+	}
+
+	ctx.step++
+
+	if header == nil && len(output) == 0 {
+		return nil, nil
+	}
+	return NewMessageWith(header, output), nil
+}
+
+// Wrap protects the message with the established security context, and encrypts it if confidential is true.
+func (ctx *ClientContext) Wrap(msg []byte, confidential bool) ([]byte, error) {
+	if !ctx.established {
+		return nil, gss.ErrContextNotEstablished
+	}
+	return ctx.sec.Wrap(msg, confidential)
+}
+
+// Unwrap verifies the token and returns the message with the established security context, and true if the message was encrypted.
+func (ctx *ClientContext) Unwrap(token []byte) ([]byte, bool, error) {
+	if !ctx.established {
+		return nil, false, gss.ErrContextNotEstablished
+	}
+	return ctx.sec.Unwrap(token)
+}
+
+// AuthResult returns the authentication result, or false if the context is not completed successfully.
+func (ctx *ClientContext) AuthResult() (mech.AuthResult, bool) {
+	return ctx.result, ctx.result != nil
+}
+
+// Dispose disposes the context.
+func (ctx *ClientContext) Dispose() error {
+	return ctx.sec.Delete()
+}
+
+// Client represents a GS2 client mechanism which bridges a GSS-API mechanism.
+type Client struct {
+	gssMech gss.Mechanism
+	plus    bool
+	opts    []mech.Option
+}
+
+// NewClient returns a new GS2 client for the GSS-API mechanism without channel binding.
+func NewClient(m gss.Mechanism) *Client {
+	return &Client{
+		gssMech: m,
+		plus:    false,
+		opts:    []mech.Option{},
+	}
+}
+
+// NewPlusClient returns a new GS2 client for the GSS-API mechanism with channel binding.
+func NewPlusClient(m gss.Mechanism) *Client {
+	client := NewClient(m)
+	client.plus = true
+	return client
+}
+
+// Name returns the mechanism name.
+func (client *Client) Name() string {
+	return MechanismName(client.gssMech, client.plus)
+}
+
+// Type returns the mechanism type.
+func (client *Client) Type() mech.Type {
+	return mech.Client
+}
+
+// SetOptions sets the mechanism options before starting.
+func (client *Client) SetOptions(opts ...mech.Option) error {
+	client.opts = opts
+	return nil
+}
+
+// Start returns the initial context.
+func (client *Client) Start(opts ...mech.Option) (mech.Context, error) {
+	return NewClientContext(client, slices.Concat(client.opts, opts)...)
+}
@@ -0,0 +1,51 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gs2
+
+import (
+	"errors"
+
+	"github.com/cybergarage/go-sasl/sasl/auth"
+	"github.com/cybergarage/go-sasl/sasl/gss"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+func newError(reason mech.ErrorReason, name string, step int, err error) error {
+	return mech.NewError(reason,
+		mech.WithErrorMechanism(name),
+		mech.WithErrorStep(step),
+		mech.WithErrorCause(err),
+	)
+}
+
+func newErrorFromAuth(name string, step int, err error) error {
+	switch {
+	case errors.Is(err, auth.ErrAuthorizationDenied):
+		return newError(mech.ReasonAuthorizationDenied, name, step, err)
+	case errors.Is(err, auth.ErrInvalidCredential), errors.Is(err, auth.ErrNoCredential):
+		return newError(mech.ReasonInvalidCredentials, name, step, err)
+	}
+	return newError(mech.ReasonTemporaryFailure, name, step, err)
+}
+
+func newErrorFromChannelBinding(name string, step int, err error) error {
+	switch {
+	case errors.Is(err, gss.ErrInvalidHeader):
+		return newError(mech.ReasonMalformed, name, step, err)
+	case errors.Is(err, gss.ErrNoChannelBinding), errors.Is(err, gss.ErrUnsupportedChannelBindingType):
+		return newError(mech.ReasonEncryptionRequired, name, step, err)
+	}
+	return newError(mech.ReasonInvalidCredentials, name, step, err)
+}
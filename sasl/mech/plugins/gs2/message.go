@@ -0,0 +1,98 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gs2
+
+import (
+	"fmt"
+
+	"github.com/cybergarage/go-sasl/sasl/gss"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+// Message represents a GS2 message which is a GSS-API context token preceded by the GS2 header in the initial message.
+type Message struct {
+	header *gss.Header
+	token  []byte
+}
+
+// NewMessageWith returns a new GS2 message with the header and the token.
+func NewMessageWith(header *gss.Header, token []byte) *Message {
+	return &Message{
+		header: header,
+		token:  token,
+	}
+}
+
+// NewMessageFrom returns a new GS2 message from the specified value.
+// The header is parsed only if initial is true.
+func NewMessageFrom(v any, initial bool) (*Message, error) {
+	var b []byte
+	switch v := v.(type) {
+	case *Message:
+		return v, nil
+	case mech.Response:
+		b = v.Bytes()
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	case nil:
+		b = nil
+	default:
+		return nil, fmt.Errorf("invalid type %T for GS2 message", v)
+	}
+	if mech.DefaultMaxMessageSize < len(b) {
+		return nil, fmt.Errorf("%w : %d bytes", mech.ErrMessageTooLarge, len(b))
+	}
+	if !initial {
+		return NewMessageWith(nil, b), nil
+	}
+	header, token, err := gss.SplitHeader(b)
+	if err != nil {
+		return nil, err
+	}
+	return NewMessageWith(header, token), nil
+}
+
+// Header returns the GS2 header, or nil if the message is not the initial message.
+func (msg *Message) Header() *gss.Header {
+	return msg.header
+}
+
+// Token returns the GSS-API context token.
+func (msg *Message) Token() []byte {
+	return msg.token
+}
+
+// String returns the message as a string.
+func (msg *Message) String() string {
+	return string(msg.Bytes())
+}
+
+// Bytes returns the message as a byte array.
+func (msg *Message) Bytes() []byte {
+	if msg.header == nil {
+		return msg.token
+	}
+	return append([]byte(msg.header.String()), msg.token...)
+}
+
+func channelBindings(header *gss.Header, cb *mech.ChannelBinding) []byte {
+	b := []byte(header.ChannelBindingHeader())
+	if header.CBFlag() == gss.ClientSupportsUsedCBSFlag && cb != nil {
+		b = append(b, cb.Data...)
+	}
+	return b
+}
@@ -0,0 +1,254 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gs2
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/cybergarage/go-sasl/sasl/auth"
+	"github.com/cybergarage/go-sasl/sasl/gss"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+// ServerContext represents a GS2 server context.
+type ServerContext struct {
+	mechanism *Server
+	mech.Store
+	cb          *mech.ChannelBinding
+	header      *gss.Header
+	sec         gss.AcceptorContext
+	established bool
+	step        int
+	result      mech.AuthResult
+	auth.Manager
+	conn auth.Conn
+}
+
+// NewServerContext returns a new GS2 server context.
+func NewServerContext(server *Server, opts ...mech.Option) (*ServerContext, error) {
+	ctx := &ServerContext{
+		mechanism:   server,
+		Store:       mech.NewStore(),
+		cb:          nil,
+		header:      nil,
+		sec:         nil,
+		established: false,
+		step:        0,
+		result:      nil,
+		Manager:     auth.NewManager(),
+		conn:        nil,
+	}
+
+	for _, opt := range opts {
+		switch v := opt.(type) {
+		case auth.Manager:
+			ctx.Manager = v
+		case auth.Conn:
+			ctx.conn = v
+		case mech.ChannelBinding:
+			ctx.cb = &v
+		case *mech.ChannelBinding:
+			ctx.cb = v
+		}
+	}
+
+	sec, err := server.gssMech.NewAcceptor(opts...)
+	if err != nil {
+		return nil, newError(mech.ReasonTemporaryFailure, server.Name(), ctx.step, err)
+	}
+	ctx.sec = sec
+
+	return ctx, nil
+}
+
+// Mechanism returns the mechanism.
+func (ctx *ServerContext) Mechanism() mech.Mechanism {
+	return ctx.mechanism
+}
+
+// Done returns true if the context is completed.
+func (ctx *ServerContext) Done() bool {
+	return ctx.established
+}
+
+// Step returns the current step number. The step number is incremented by one after each call to Next.
+func (ctx *ServerContext) Step() int {
+	return ctx.step
+}
+
+// verifyChannelBinding verifies the channel binding flag of the header according to RFC 5801 section 5.
+func (ctx *ServerContext) verifyChannelBinding(header *gss.Header) error {
+	switch header.CBFlag() {
+	case gss.ClientSupportsUsedCBSFlag:
+		if !ctx.mechanism.plus {
+			return fmt.Errorf("%w : %s", gss.ErrInvalidHeader, header.String())
+		}
+		if ctx.cb == nil {
+			return gss.ErrNoChannelBinding
+		}
+		if header.CBName() != ctx.cb.Type {
+			return fmt.Errorf("%w : %s", gss.ErrUnsupportedChannelBindingType, header.CBName())
+		}
+	case gss.ClientSupportsCBSFlag:
+		if ctx.mechanism.plus {
+			return fmt.Errorf("%w : %s", gss.ErrInvalidHeader, header.String())
+		}
+		if ctx.cb != nil {
+			return gss.ErrChannelBindingDowngrade
+		}
+	case gss.ClientDoesNotSupportCBSFlag:
+		if ctx.mechanism.plus {
+			return fmt.Errorf("%w : %s", gss.ErrInvalidHeader, header.String())
+		}
+	default:
+		return fmt.Errorf("%w : %s", gss.ErrInvalidHeader, header.String())
+	}
+	return nil
+}
+
+// Next returns the next response.
+func (ctx *ServerContext) Next(opts ...mech.Parameter) (mech.Response, error) {
+	name := ctx.mechanism.Name()
+	if ctx.established {
+		return nil, newError(mech.ReasonMalformed, name, ctx.step, fmt.Errorf("invalid step : %d", ctx.step))
+	}
+	if len(opts) == 0 {
+		return nil, newError(mech.ReasonMalformed, name, ctx.step, errors.New("no message"))
+	}
+
+	msg, err := NewMessageFrom(opts[0], ctx.step == 0)
+	if err != nil {
+		return nil, newError(mech.ReasonMalformed, name, ctx.step, err)
+	}
+
+	if ctx.step == 0 {
+		if err := ctx.verifyChannelBinding(msg.Header()); err != nil {
+			return nil, newErrorFromChannelBinding(name, ctx.step, err)
+		}
+		ctx.header = msg.Header()
+	}
+
+	output, established, err := ctx.sec.AcceptSecContext(msg.Token(), channelBindings(ctx.header, ctx.cb))
+	if err != nil {
+		return nil, newError(mech.ReasonInvalidCredentials, name, ctx.step, err)
+	}
+
+	if established {
+		authcid := ctx.sec.SourceName()
+		authzid := ctx.header.AuthzID()
+		err = ctx.Authorize(ctx.conn, authcid, authzid, name)
+		if err != nil {
+			return nil, newErrorFromAuth(name, ctx.step, err)
+		}
+		ctx.established = true
+		resultOpts := []mech.AuthResultOption{
+			mech.WithAuthResultAuthcID(authcid),
+			mech.WithAuthResultAuthzID(auth.AuthorizedID(authcid, authzid)),
+			mech.WithAuthResultMechanism(name),
+			mech.WithAuthResultRealm(auth.Realm(authcid)),
+		}
+		if ctx.mechanism.plus {
+			resultOpts = append(resultOpts, mech.WithAuthResultChannelBinding(ctx.cb.Type))
+		}
+		ctx.result = mech.NewAuthResult(resultOpts...)
+	}
+
+	ctx.step++
+
+	if established && len(output) == 0 {
+		return nil, nil
+	}
+	return NewMessageWith(nil, output), nil
+}
+
+// Wrap protects the message with the established security context, and encrypts it if confidential is true.
+func (ctx *ServerContext) Wrap(msg []byte, confidential bool) ([]byte, error) {
+	if !ctx.established {
+		return nil, gss.ErrContextNotEstablished
+	}
+	return ctx.sec.Wrap(msg, confidential)
+}
+
+// Unwrap verifies the token and returns the message with the established security context, and true if the message was encrypted.
+func (ctx *ServerContext) Unwrap(token []byte) ([]byte, bool, error) {
+	if !ctx.established {
+		return nil, false, gss.ErrContextNotEstablished
+	}
+	return ctx.sec.Unwrap(token)
+}
+
+// AuthorizedID returns the identity associated with the connection after the context is completed successfully.
+func (ctx *ServerContext) AuthorizedID() string {
+	if ctx.result == nil {
+		return ""
+	}
+	return ctx.result.AuthzID()
+}
+
+// AuthResult returns the authentication result, or false if the context is not completed successfully.
+func (ctx *ServerContext) AuthResult() (mech.AuthResult, bool) {
+	return ctx.result, ctx.result != nil
+}
+
+// Dispose disposes the context.
+func (ctx *ServerContext) Dispose() error {
+	return ctx.sec.Delete()
+}
+
+// Server represents a GS2 server mechanism which bridges a GSS-API mechanism.
+type Server struct {
+	gssMech gss.Mechanism
+	plus    bool
+	opts    []mech.Option
+}
+
+// NewServer returns a new GS2 server for the GSS-API mechanism without channel binding.
+func NewServer(m gss.Mechanism) *Server {
+	return &Server{
+		gssMech: m,
+		plus:    false,
+		opts:    []mech.Option{},
+	}
+}
+
+// NewPlusServer returns a new GS2 server for the GSS-API mechanism with channel binding.
+func NewPlusServer(m gss.Mechanism) *Server {
+	server := NewServer(m)
+	server.plus = true
+	return server
+}
+
+// Name returns the mechanism name.
+func (server *Server) Name() string {
+	return MechanismName(server.gssMech, server.plus)
+}
+
+// Type returns the mechanism type.
+func (server *Server) Type() mech.Type {
+	return mech.Server
+}
+
+// SetOptions sets the mechanism options before starting.
+func (server *Server) SetOptions(opts ...mech.Option) error {
+	server.opts = opts
+	return nil
+}
+
+// Start returns the initial context.
+func (server *Server) Start(opts ...mech.Option) (mech.Context, error) {
+	return NewServerContext(server, slices.Concat(server.opts, opts)...)
+}
@@ -0,0 +1,39 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gs2
+
+import (
+	"github.com/cybergarage/go-sasl/sasl/gss"
+)
+
+// RFC 5801 - Using Generic Security Service Application Program Interface (GSS-API) Mechanisms
+// in Simple Authentication and Security Layer (SASL): The GS2 Mechanism Family
+// https://datatracker.ietf.org/doc/html/rfc5801
+
+const (
+	// Prefix is the prefix of the GS2 mechanism names.
+	Prefix = "GS2-"
+	// PlusSuffix is the suffix of the GS2 mechanism names which use channel binding.
+	PlusSuffix = "-PLUS"
+)
+
+// MechanismName returns the SASL mechanism name of the GSS-API mechanism.
+func MechanismName(m gss.Mechanism, plus bool) string {
+	name := Prefix + m.Name()
+	if plus {
+		name += PlusSuffix
+	}
+	return name
+}
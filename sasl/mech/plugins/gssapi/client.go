@@ -0,0 +1,231 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gssapi
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/cybergarage/go-sasl/sasl/auth"
+	"github.com/cybergarage/go-sasl/sasl/gss"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+// ClientContext represents a GSSAPI client context.
+type ClientContext struct {
+	mechanism mech.Mechanism
+	mech.Store
+	username    string
+	authzID     string
+	layers      SecurityLayer
+	maxSize     MaxBufferSize
+	sec         gss.InitiatorContext
+	established bool
+	layer       SecurityLayer
+	peerMaxSize MaxBufferSize
+	step        int
+	result      mech.AuthResult
+}
+
+// NewClientContext returns a new GSSAPI client context.
+func NewClientContext(client *Client, opts ...mech.Option) (*ClientContext, error) {
+	ctx := &ClientContext{
+		mechanism:   client,
+		Store:       mech.NewStore(),
+		username:    "",
+		authzID:     "",
+		layers:      AllSecurityLayers,
+		maxSize:     DefaultMaxBufferSize,
+		sec:         nil,
+		established: false,
+		layer:       0,
+		peerMaxSize: 0,
+		step:        0,
+		result:      nil,
+	}
+
+	for _, opt := range opts {
+		switch v := opt.(type) {
+		case mech.Username:
+			ctx.username = string(v)
+		case mech.AuthzID:
+			ctx.authzID = string(v)
+		case SecurityLayer:
+			ctx.layers = v
+		case MaxBufferSize:
+			ctx.maxSize = min(v, MaxBufferSizeLimit)
+		}
+	}
+
+	sec, err := client.gssMech.NewInitiator(opts...)
+	if err != nil {
+		return nil, newError(mech.ReasonInvalidCredentials, Type, ctx.step, err)
+	}
+	ctx.sec = sec
+
+	return ctx, nil
+}
+
+// Mechanism returns the mechanism.
+func (ctx *ClientContext) Mechanism() mech.Mechanism {
+	return ctx.mechanism
+}
+
+// Done returns true if the context is completed.
+func (ctx *ClientContext) Done() bool {
+	return ctx.result != nil
+}
+
+// Step returns the current step number. The step number is incremented by one after each call to Next.
+func (ctx *ClientContext) Step() int {
+	return ctx.step
+}
+
+// Next returns the next response.
+func (ctx *ClientContext) Next(opts ...mech.Parameter) (mech.Response, error) {
+	if ctx.Done() {
+		return nil, newError(mech.ReasonMalformed, Type, ctx.step, fmt.Errorf("invalid step : %d", ctx.step))
+	}
+
+	var input []byte
+	if 0 < ctx.step {
+		if len(opts) == 0 {
+			return nil, newError(mech.ReasonMalformed, Type, ctx.step, errors.New("no message"))
+		}
+		msg, err := NewMessageFrom(opts[0])
+		if err != nil {
+			return nil, newError(mech.ReasonMalformed, Type, ctx.step, err)
+		}
+		input = msg.Token()
+	}
+
+	if !ctx.established {
+		output, established, err := ctx.sec.InitSecContext(input, nil)
+		if err != nil {
+			return nil, newError(mech.ReasonInvalidCredentials, Type, ctx.step, err)
+		}
+		ctx.established = established
+		ctx.step++
+		return NewMessageWith(output), nil
+	}
+
+	// RFC 4752 section 3.1: The client selects a security layer offered by the server
+	// and replies with the layer, the maximum buffer size and the authorization identity wrapped with conf_flag FALSE.
+	unwrapped, _, err := ctx.sec.Unwrap(input)
+	if err != nil {
+		return nil, newError(mech.ReasonInvalidCredentials, Type, ctx.step, err)
+	}
+	offered, peerMaxSize, _, err := parseSecurityLayerMessage(unwrapped)
+	if err != nil {
+		return nil, newError(mech.ReasonMalformed, Type, ctx.step, err)
+	}
+	layer := (offered & ctx.layers).Strongest()
+	if layer == 0 {
+		return nil, newError(mech.ReasonEncryptionRequired, Type, ctx.step, fmt.Errorf("%w : %s", ErrNoSecurityLayer, offered))
+	}
+	maxSize := ctx.maxSize
+	if layer == NoSecurityLayer {
+		maxSize = 0
+	}
+	output, err := ctx.sec.Wrap(newSecurityLayerMessage(layer, maxSize, ctx.authzID), false)
+	if err != nil {
+		return nil, newError(mech.ReasonTemporaryFailure, Type, ctx.step, err)
+	}
+
+	ctx.layer = layer
+	ctx.peerMaxSize = peerMaxSize
+	ctx.result = mech.NewAuthResult(
+		mech.WithAuthResultAuthcID(ctx.username),
+		mech.WithAuthResultAuthzID(auth.AuthorizedID(ctx.username, ctx.authzID)),
+		mech.WithAuthResultMechanism(Type),
+		mech.WithAuthResultRealm(auth.Realm(ctx.username)),
+		mech.WithAuthResultSSF(layer.SSF()),
+	)
+	ctx.step++
+	return NewMessageWith(output), nil
+}
+
+// SecurityLayer returns the negotiated security layer, or zero if the context is not completed.
+func (ctx *ClientContext) SecurityLayer() SecurityLayer {
+	return ctx.layer
+}
+
+// MaxBufferSize returns the maximum size of the wrapped messages which the server is able to receive.
+func (ctx *ClientContext) MaxBufferSize() MaxBufferSize {
+	return ctx.peerMaxSize
+}
+
+// Wrap protects the message with the negotiated security layer, and encrypts it with the confidentiality layer.
+func (ctx *ClientContext) Wrap(msg []byte) ([]byte, error) {
+	if !ctx.Done() || ctx.layer == NoSecurityLayer {
+		return nil, ErrNoSecurityLayer
+	}
+	return ctx.sec.Wrap(msg, ctx.layer == ConfidentialityLayer)
+}
+
+// Unwrap verifies the token and returns the message with the negotiated security layer.
+// It fails with ErrConfidentialityRequired if the message is not encrypted with the confidentiality layer.
+func (ctx *ClientContext) Unwrap(token []byte) ([]byte, error) {
+	if !ctx.Done() || ctx.layer == NoSecurityLayer {
+		return nil, ErrNoSecurityLayer
+	}
+	return unwrap(ctx.sec, ctx.layer, token)
+}
+
+// AuthResult returns the authentication result, or false if the context is not completed successfully.
+func (ctx *ClientContext) AuthResult() (mech.AuthResult, bool) {
+	return ctx.result, ctx.result != nil
+}
+
+// Dispose disposes the context.
+func (ctx *ClientContext) Dispose() error {
+	return ctx.sec.Delete()
+}
+
+// Client represents a GSSAPI client mechanism.
+type Client struct {
+	gssMech gss.Mechanism
+	opts    []mech.Option
+}
+
+// NewClient returns a new GSSAPI client with the Kerberos V5 GSS-API mechanism.
+func NewClient(m gss.Mechanism) *Client {
+	return &Client{
+		gssMech: m,
+		opts:    []mech.Option{},
+	}
+}
+
+// Name returns the mechanism name.
+func (client *Client) Name() string {
+	return Type
+}
+
+// Type returns the mechanism type.
+func (client *Client) Type() mech.Type {
+	return mech.Client
+}
+
+// SetOptions sets the mechanism options before starting.
+func (client *Client) SetOptions(opts ...mech.Option) error {
+	client.opts = opts
+	return nil
+}
+
+// Start returns the initial context.
+func (client *Client) Start(opts ...mech.Option) (mech.Context, error) {
+	return NewClientContext(client, slices.Concat(client.opts, opts)...)
+}
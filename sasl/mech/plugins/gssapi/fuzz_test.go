@@ -0,0 +1,45 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gssapi
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func FuzzSecurityLayerMessage(f *testing.F) {
+	seeds := [][]byte{
+		{},
+		{0x01, 0x00, 0x00, 0x00},
+		{0x07, 0x01, 0x00, 0x00},
+		{0x02, 0xFF, 0xFF, 0xFF, 'a', 'd', 'm', 'i', 'n'},
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		layers, maxSize, authzID, err := parseSecurityLayerMessage(b)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidSecurityLayer) {
+				t.Fatalf("%v: untyped error %v", b, err)
+			}
+			return
+		}
+		if !bytes.Equal(newSecurityLayerMessage(layers, maxSize, authzID), b) {
+			t.Fatalf("%v is not encoded back", b)
+		}
+	})
+}
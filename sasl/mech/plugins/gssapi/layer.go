@@ -0,0 +1,118 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gssapi
+
+import (
+	"fmt"
+
+	"github.com/cybergarage/go-sasl/sasl/gss"
+)
+
+// SecurityLayer represents a bit mask of the security layers in RFC 4752 section 3.3.
+type SecurityLayer byte
+
+const (
+	// NoSecurityLayer represents no security layer.
+	NoSecurityLayer SecurityLayer = 0x01
+	// IntegrityLayer represents the integrity protection layer.
+	IntegrityLayer SecurityLayer = 0x02
+	// ConfidentialityLayer represents the confidentiality protection layer.
+	ConfidentialityLayer SecurityLayer = 0x04
+	// AllSecurityLayers represents all security layers.
+	AllSecurityLayers = NoSecurityLayer | IntegrityLayer | ConfidentialityLayer
+)
+
+// MaxBufferSize represents the maximum size of the wrapped messages which the peer is able to receive.
+type MaxBufferSize uint32
+
+const (
+	// DefaultMaxBufferSize is the default maximum buffer size.
+	DefaultMaxBufferSize MaxBufferSize = 65536
+	// MaxBufferSizeLimit is the largest maximum buffer size in the three octets of the security layer message.
+	MaxBufferSizeLimit MaxBufferSize = 0xFFFFFF
+)
+
+const securityLayerMessageSize = 4
+
+// Has returns true if the bit mask includes the layer.
+func (layers SecurityLayer) Has(layer SecurityLayer) bool {
+	return layers&layer != 0
+}
+
+// Strongest returns the strongest layer in the bit mask, or zero if the bit mask is empty.
+func (layers SecurityLayer) Strongest() SecurityLayer {
+	for _, layer := range []SecurityLayer{ConfidentialityLayer, IntegrityLayer, NoSecurityLayer} {
+		if layers.Has(layer) {
+			return layer
+		}
+	}
+	return 0
+}
+
+// SSF returns the security strength factor of the strongest layer in the bit mask.
+func (layers SecurityLayer) SSF() int {
+	switch layers.Strongest() {
+	case ConfidentialityLayer:
+		return 56
+	case IntegrityLayer:
+		return 1
+	}
+	return 0
+}
+
+// String returns a string representation of the bit mask.
+func (layers SecurityLayer) String() string {
+	switch layers {
+	case NoSecurityLayer:
+		return "none"
+	case IntegrityLayer:
+		return "integrity"
+	case ConfidentialityLayer:
+		return "confidentiality"
+	}
+	return fmt.Sprintf("0x%02x", byte(layers))
+}
+
+// newSecurityLayerMessage returns the security layer message which is the bit mask, the maximum buffer size and the authorization identity.
+func newSecurityLayerMessage(layers SecurityLayer, maxSize MaxBufferSize, authzID string) []byte {
+	b := make([]byte, securityLayerMessageSize, securityLayerMessageSize+len(authzID))
+	b[0] = byte(layers)
+	b[1] = byte(maxSize >> 16)
+	b[2] = byte(maxSize >> 8)
+	b[3] = byte(maxSize)
+	return append(b, authzID...)
+}
+
+// parseSecurityLayerMessage parses the security layer message.
+func parseSecurityLayerMessage(b []byte) (SecurityLayer, MaxBufferSize, string, error) {
+	if len(b) < securityLayerMessageSize {
+		return 0, 0, "", fmt.Errorf("%w : %d bytes", ErrInvalidSecurityLayer, len(b))
+	}
+	layers := SecurityLayer(b[0])
+	maxSize := MaxBufferSize(b[1])<<16 | MaxBufferSize(b[2])<<8 | MaxBufferSize(b[3])
+	return layers, maxSize, string(b[securityLayerMessageSize:]), nil
+}
+
+// unwrap unwraps the token with the security context, and verifies that the message is encrypted with the confidentiality layer.
+func unwrap(sec gss.SecContext, layer SecurityLayer, token []byte) ([]byte, error) {
+	msg, confidential, err := sec.Unwrap(token)
+	if err != nil {
+		return nil, err
+	}
+	if layer == ConfidentialityLayer && !confidential {
+		return nil, ErrConfidentialityRequired
+	}
+	return msg, nil
+}
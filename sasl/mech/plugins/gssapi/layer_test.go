@@ -0,0 +1,73 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gssapi
+
+import (
+	"testing"
+)
+
+func TestSecurityLayerMessage(t *testing.T) {
+	tests := []struct {
+		layers  SecurityLayer
+		maxSize MaxBufferSize
+		authzID string
+		bytes   []byte
+	}{
+		{NoSecurityLayer, 0, "", []byte{0x01, 0x00, 0x00, 0x00}},
+		{AllSecurityLayers, DefaultMaxBufferSize, "", []byte{0x07, 0x01, 0x00, 0x00}},
+		{IntegrityLayer, MaxBufferSizeLimit, "admin", []byte{0x02, 0xFF, 0xFF, 0xFF, 'a', 'd', 'm', 'i', 'n'}},
+	}
+
+	for _, test := range tests {
+		b := newSecurityLayerMessage(test.layers, test.maxSize, test.authzID)
+		if string(b) != string(test.bytes) {
+			t.Errorf("expected %v, got %v", test.bytes, b)
+		}
+		layers, maxSize, authzID, err := parseSecurityLayerMessage(b)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if layers != test.layers || maxSize != test.maxSize || authzID != test.authzID {
+			t.Errorf("expected (%v, %v, %v), got (%v, %v, %v)", test.layers, test.maxSize, test.authzID, layers, maxSize, authzID)
+		}
+	}
+
+	if _, _, _, err := parseSecurityLayerMessage([]byte{0x01, 0x00, 0x00}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestSecurityLayerStrongest(t *testing.T) {
+	tests := []struct {
+		layers    SecurityLayer
+		strongest SecurityLayer
+		ssf       int
+	}{
+		{0, 0, 0},
+		{NoSecurityLayer, NoSecurityLayer, 0},
+		{NoSecurityLayer | IntegrityLayer, IntegrityLayer, 1},
+		{AllSecurityLayers, ConfidentialityLayer, 56},
+	}
+
+	for _, test := range tests {
+		if test.layers.Strongest() != test.strongest {
+			t.Errorf("expected %v, got %v", test.strongest, test.layers.Strongest())
+		}
+		if test.layers.SSF() != test.ssf {
+			t.Errorf("expected %v, got %v", test.ssf, test.layers.SSF())
+		}
+	}
+}
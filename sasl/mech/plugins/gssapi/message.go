@@ -0,0 +1,71 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gssapi
+
+import (
+	"fmt"
+
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+// Message represents a GSSAPI message which is a GSS-API token.
+type Message struct {
+	token []byte
+}
+
+// NewMessageWith returns a new GSSAPI message with the token.
+func NewMessageWith(token []byte) *Message {
+	return &Message{
+		token: token,
+	}
+}
+
+// NewMessageFrom returns a new GSSAPI message from the specified value.
+func NewMessageFrom(v any) (*Message, error) {
+	var b []byte
+	switch v := v.(type) {
+	case *Message:
+		return v, nil
+	case mech.Response:
+		b = v.Bytes()
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	case nil:
+		b = nil
+	default:
+		return nil, fmt.Errorf("invalid type %T for GSSAPI message", v)
+	}
+	if mech.DefaultMaxMessageSize < len(b) {
+		return nil, fmt.Errorf("%w : %d bytes", mech.ErrMessageTooLarge, len(b))
+	}
+	return NewMessageWith(b), nil
+}
+
+// Token returns the GSS-API token.
+func (msg *Message) Token() []byte {
+	return msg.token
+}
+
+// String returns the message as a string.
+func (msg *Message) String() string {
+	return string(msg.token)
+}
+
+// Bytes returns the message as a byte array.
+func (msg *Message) Bytes() []byte {
+	return msg.token
+}
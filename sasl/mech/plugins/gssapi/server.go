@@ -0,0 +1,273 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gssapi
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/cybergarage/go-sasl/sasl/auth"
+	"github.com/cybergarage/go-sasl/sasl/gss"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+type serverState int
+
+const (
+	serverAccepting serverState = iota
+	serverWaitingEmptyResponse
+	serverWaitingSecurityLayer
+	serverCompleted
+)
+
+// ServerContext represents a GSSAPI server context.
+type ServerContext struct {
+	mechanism mech.Mechanism
+	mech.Store
+	layers      SecurityLayer
+	maxSize     MaxBufferSize
+	sec         gss.AcceptorContext
+	state       serverState
+	layer       SecurityLayer
+	peerMaxSize MaxBufferSize
+	step        int
+	result      mech.AuthResult
+	auth.Manager
+	conn auth.Conn
+}
+
+// NewServerContext returns a new GSSAPI server context.
+func NewServerContext(server *Server, opts ...mech.Option) (*ServerContext, error) {
+	ctx := &ServerContext{
+		mechanism:   server,
+		Store:       mech.NewStore(),
+		layers:      AllSecurityLayers,
+		maxSize:     DefaultMaxBufferSize,
+		sec:         nil,
+		state:       serverAccepting,
+		layer:       0,
+		peerMaxSize: 0,
+		step:        0,
+		result:      nil,
+		Manager:     auth.NewManager(),
+		conn:        nil,
+	}
+
+	for _, opt := range opts {
+		switch v := opt.(type) {
+		case auth.Manager:
+			ctx.Manager = v
+		case auth.Conn:
+			ctx.conn = v
+		case SecurityLayer:
+			ctx.layers = v
+		case MaxBufferSize:
+			ctx.maxSize = min(v, MaxBufferSizeLimit)
+		}
+	}
+
+	if ctx.layers&AllSecurityLayers == 0 {
+		return nil, newError(mech.ReasonEncryptionRequired, Type, ctx.step, fmt.Errorf("%w : %s", ErrNoSecurityLayer, ctx.layers))
+	}
+
+	sec, err := server.gssMech.NewAcceptor(opts...)
+	if err != nil {
+		return nil, newError(mech.ReasonTemporaryFailure, Type, ctx.step, err)
+	}
+	ctx.sec = sec
+
+	return ctx, nil
+}
+
+// Mechanism returns the mechanism.
+func (ctx *ServerContext) Mechanism() mech.Mechanism {
+	return ctx.mechanism
+}
+
+// Done returns true if the context is completed.
+func (ctx *ServerContext) Done() bool {
+	return ctx.state == serverCompleted
+}
+
+// Step returns the current step number. The step number is incremented by one after each call to Next.
+func (ctx *ServerContext) Step() int {
+	return ctx.step
+}
+
+// securityLayerChallenge returns the security layers and the maximum buffer size offered by the server,
+// which are wrapped with conf_flag FALSE as RFC 4752 section 3.1 requires.
+func (ctx *ServerContext) securityLayerChallenge() (mech.Response, error) {
+	maxSize := ctx.maxSize
+	if ctx.layers&AllSecurityLayers == NoSecurityLayer {
+		maxSize = 0
+	}
+	output, err := ctx.sec.Wrap(newSecurityLayerMessage(ctx.layers&AllSecurityLayers, maxSize, ""), false)
+	if err != nil {
+		return nil, newError(mech.ReasonTemporaryFailure, Type, ctx.step, err)
+	}
+	ctx.state = serverWaitingSecurityLayer
+	ctx.step++
+	return NewMessageWith(output), nil
+}
+
+// Next returns the next response.
+func (ctx *ServerContext) Next(opts ...mech.Parameter) (mech.Response, error) {
+	if ctx.Done() {
+		return nil, newError(mech.ReasonMalformed, Type, ctx.step, fmt.Errorf("invalid step : %d", ctx.step))
+	}
+	if len(opts) == 0 {
+		return nil, newError(mech.ReasonMalformed, Type, ctx.step, errors.New("no message"))
+	}
+	msg, err := NewMessageFrom(opts[0])
+	if err != nil {
+		return nil, newError(mech.ReasonMalformed, Type, ctx.step, err)
+	}
+
+	switch ctx.state {
+	case serverAccepting:
+		output, established, err := ctx.sec.AcceptSecContext(msg.Token(), nil)
+		if err != nil {
+			return nil, newError(mech.ReasonInvalidCredentials, Type, ctx.step, err)
+		}
+		if established && len(output) == 0 {
+			return ctx.securityLayerChallenge()
+		}
+		if established {
+			ctx.state = serverWaitingEmptyResponse
+		}
+		ctx.step++
+		return NewMessageWith(output), nil
+	case serverWaitingEmptyResponse:
+		if 0 < len(msg.Token()) {
+			return nil, newError(mech.ReasonMalformed, Type, ctx.step, ErrUnexpectedToken)
+		}
+		return ctx.securityLayerChallenge()
+	}
+
+	unwrapped, _, err := ctx.sec.Unwrap(msg.Token())
+	if err != nil {
+		return nil, newError(mech.ReasonInvalidCredentials, Type, ctx.step, err)
+	}
+	layer, peerMaxSize, authzid, err := parseSecurityLayerMessage(unwrapped)
+	if err != nil {
+		return nil, newError(mech.ReasonMalformed, Type, ctx.step, err)
+	}
+	if layer.Strongest() != layer || !ctx.layers.Has(layer) {
+		return nil, newError(mech.ReasonMalformed, Type, ctx.step, fmt.Errorf("%w : %s", ErrInvalidSecurityLayer, layer))
+	}
+	if layer == NoSecurityLayer && peerMaxSize != 0 {
+		return nil, newError(mech.ReasonMalformed, Type, ctx.step, fmt.Errorf("%w : max buffer size %d", ErrInvalidSecurityLayer, peerMaxSize))
+	}
+
+	authcid := ctx.sec.SourceName()
+	err = ctx.Authorize(ctx.conn, authcid, authzid, Type)
+	if err != nil {
+		return nil, newErrorFromAuth(Type, ctx.step, err)
+	}
+
+	ctx.layer = layer
+	ctx.peerMaxSize = peerMaxSize
+	ctx.result = mech.NewAuthResult(
+		mech.WithAuthResultAuthcID(authcid),
+		mech.WithAuthResultAuthzID(auth.AuthorizedID(authcid, authzid)),
+		mech.WithAuthResultMechanism(Type),
+		mech.WithAuthResultRealm(auth.Realm(authcid)),
+		mech.WithAuthResultSSF(layer.SSF()),
+	)
+	ctx.state = serverCompleted
+	ctx.step++
+	return nil, nil
+}
+
+// SecurityLayer returns the negotiated security layer, or zero if the context is not completed.
+func (ctx *ServerContext) SecurityLayer() SecurityLayer {
+	return ctx.layer
+}
+
+// MaxBufferSize returns the maximum size of the wrapped messages which the client is able to receive.
+func (ctx *ServerContext) MaxBufferSize() MaxBufferSize {
+	return ctx.peerMaxSize
+}
+
+// Wrap protects the message with the negotiated security layer, and encrypts it with the confidentiality layer.
+func (ctx *ServerContext) Wrap(msg []byte) ([]byte, error) {
+	if !ctx.Done() || ctx.layer == NoSecurityLayer {
+		return nil, ErrNoSecurityLayer
+	}
+	return ctx.sec.Wrap(msg, ctx.layer == ConfidentialityLayer)
+}
+
+// Unwrap verifies the token and returns the message with the negotiated security layer.
+// It fails with ErrConfidentialityRequired if the message is not encrypted with the confidentiality layer.
+func (ctx *ServerContext) Unwrap(token []byte) ([]byte, error) {
+	if !ctx.Done() || ctx.layer == NoSecurityLayer {
+		return nil, ErrNoSecurityLayer
+	}
+	return unwrap(ctx.sec, ctx.layer, token)
+}
+
+// AuthorizedID returns the identity associated with the connection after the context is completed successfully.
+func (ctx *ServerContext) AuthorizedID() string {
+	if ctx.result == nil {
+		return ""
+	}
+	return ctx.result.AuthzID()
+}
+
+// AuthResult returns the authentication result, or false if the context is not completed successfully.
+func (ctx *ServerContext) AuthResult() (mech.AuthResult, bool) {
+	return ctx.result, ctx.result != nil
+}
+
+// Dispose disposes the context.
+func (ctx *ServerContext) Dispose() error {
+	return ctx.sec.Delete()
+}
+
+// Server represents a GSSAPI server mechanism.
+type Server struct {
+	gssMech gss.Mechanism
+	opts    []mech.Option
+}
+
+// NewServer returns a new GSSAPI server with the Kerberos V5 GSS-API mechanism.
+func NewServer(m gss.Mechanism) *Server {
+	return &Server{
+		gssMech: m,
+		opts:    []mech.Option{},
+	}
+}
+
+// Name returns the mechanism name.
+func (server *Server) Name() string {
+	return Type
+}
+
+// Type returns the mechanism type.
+func (server *Server) Type() mech.Type {
+	return mech.Server
+}
+
+// SetOptions sets the mechanism options before starting.
+func (server *Server) SetOptions(opts ...mech.Option) error {
+	server.opts = opts
+	return nil
+}
+
+// Start returns the initial context.
+func (server *Server) Start(opts ...mech.Option) (mech.Context, error) {
+	return NewServerContext(server, slices.Concat(server.opts, opts)...)
+}
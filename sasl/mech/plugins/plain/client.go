@@ -43,7 +43,7 @@ func NewClientContext(m mech.Mechanism, opts ...mech.Option) (*ClientContext, er
 	}
 
 	if err := ctx.setOptions(opts...); err != nil {
-		return nil, err
+		return nil, newError(mech.ReasonMalformed, Type, ctx.step, err)
 	}
 
 	return ctx, nil
@@ -54,6 +54,8 @@ func (ctx *ClientContext) setOptions(opts ...any) error {
 		switch v := opt.(type) {
 		case mech.Group:
 			ctx.group = string(v)
+		case mech.AuthzID:
+			ctx.group = string(v)
 		case mech.Username:
 			ctx.username = string(v)
 		case mech.Password:
@@ -91,13 +93,16 @@ func (ctx *ClientContext) Next(opts ...mech.Parameter) (mech.Response, error) {
 	switch ctx.step {
 	case 0:
 		if err := ctx.setOptions(opts...); err != nil {
-			return nil, err
+			return nil, newError(mech.ReasonMalformed, Type, ctx.step, err)
 		}
 		msg := NewMessageWith(ctx.group, ctx.username, ctx.password)
+		if err := msg.Prepare(); err != nil {
+			return nil, newError(mech.ReasonMalformed, Type, ctx.step, err)
+		}
 		ctx.step++
 		return msg, nil
 	}
-	return nil, fmt.Errorf("invalid step : %d", ctx.step)
+	return nil, newError(mech.ReasonMalformed, Type, ctx.step, fmt.Errorf("invalid step : %d", ctx.step))
 }
 
 // Dispose disposes the context.
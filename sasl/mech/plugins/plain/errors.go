@@ -0,0 +1,52 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plain
+
+import (
+	"errors"
+
+	"github.com/cybergarage/go-sasl/sasl/auth"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+// ErrInvalidMessage is returned when the message does not have exactly three fields.
+var ErrInvalidMessage = errors.New("invalid PLAIN message")
+
+// ErrEmptyField is returned when the authcid or the passwd is empty.
+var ErrEmptyField = errors.New("empty field")
+
+// ErrFieldTooLong is returned when a field exceeds the maximum length.
+var ErrFieldTooLong = errors.New("field too long")
+
+// ErrInvalidField is returned when a field is not valid UTF-8 or includes prohibited characters.
+var ErrInvalidField = errors.New("invalid field")
+
+func newError(reason mech.ErrorReason, name string, step int, err error) error {
+	return mech.NewError(reason,
+		mech.WithErrorMechanism(name),
+		mech.WithErrorStep(step),
+		mech.WithErrorCause(err),
+	)
+}
+
+func newErrorFromAuth(name string, step int, err error) error {
+	switch {
+	case errors.Is(err, auth.ErrAuthorizationDenied):
+		return newError(mech.ReasonAuthorizationDenied, name, step, err)
+	case errors.Is(err, auth.ErrInvalidCredential), errors.Is(err, auth.ErrNoCredential):
+		return newError(mech.ReasonInvalidCredentials, name, step, err)
+	}
+	return newError(mech.ReasonTemporaryFailure, name, step, err)
+}
@@ -0,0 +1,55 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasl/prep"
+)
+
+func FuzzMessage(f *testing.F) {
+	seeds := [][]byte{
+		{},
+		{0x00, 0x62, 0x00, 0x63},
+		{0x61, 0x00, 0x62, 0x00, 0x63},
+		{0x61, 0x00, 0x62, 0x00, 0x63, 0x00},
+		[]byte("\x00I\u00ADX\x00\u2168"),
+		[]byte("\x00b\x00\u0007"),
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		msg, err := NewMessageFrom(b)
+		if err != nil {
+			for _, target := range []error{ErrInvalidMessage, ErrEmptyField, ErrFieldTooLong, ErrInvalidField, prep.ErrInvalidString, mech.ErrMessageTooLarge} {
+				if errors.Is(err, target) {
+					return
+				}
+			}
+			t.Fatalf("%q: untyped error %v", b, err)
+		}
+		reMsg, err := NewMessageFrom(msg.Bytes())
+		if err != nil {
+			t.Fatalf("%q: %v", msg.Bytes(), err)
+		}
+		if reMsg.String() != msg.String() {
+			t.Fatalf("%q != %q", reMsg.String(), msg.String())
+		}
+	})
+}
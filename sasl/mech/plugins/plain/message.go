@@ -17,13 +17,24 @@ package plain
 import (
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasl/prep"
 )
 
 // The PLAIN Simple Authentication and Security Layer (SASL) Mechanism
 // https://datatracker.ietf.org/doc/html/rfc4616
 
+const (
+	// MaxFieldLength is the maximum number of octets of each message field.
+	MaxFieldLength = 255
+	// MaxMessageSize is the maximum number of octets of a message.
+	MaxMessageSize    = messageFieldCount*MaxFieldLength + messageFieldCount - 1
+	messageFieldCount = 3
+	messageSeparator  = "\x00"
+)
+
 // Message represents a SASL PLAIN message.
 type Message struct {
 	authzid string
@@ -53,7 +64,11 @@ func NewMessageWith(authzid, authcid, passwd string) *Message {
 func NewMessageFrom(v any) (*Message, error) {
 	switch v := v.(type) {
 	case *Message:
-		return v, nil
+		msg := NewMessageWith(v.authzid, v.authcid, v.passwd)
+		if err := msg.Prepare(); err != nil {
+			return nil, err
+		}
+		return msg, nil
 	case string:
 		msg := NewMessage()
 		if err := msg.ParseBytes([]byte(v)); err != nil {
@@ -71,6 +86,7 @@ func NewMessageFrom(v any) (*Message, error) {
 		if err := msg.ParseBytes(v); err != nil {
 			return nil, err
 		}
+		return msg, nil
 	}
 	return nil, fmt.Errorf("invalid type %T for PLAIN message", v)
 }
@@ -90,21 +106,65 @@ func (msg *Message) Passwd() string {
 	return msg.passwd
 }
 
-// ParseBytes parses the message bytes.
+// ParseBytes parses the message bytes which must have exactly three fields, and prepares the fields.
 func (msg *Message) ParseBytes(b []byte) error {
-	strs := strings.Split(string(b), "\x00")
-	if len(strs) < 3 {
-		return fmt.Errorf("invalid PLAIN message")
+	if MaxMessageSize < len(b) {
+		return fmt.Errorf("%w : %d octets", mech.ErrMessageTooLarge, len(b))
+	}
+	fields := strings.Split(string(b), messageSeparator)
+	if len(fields) != messageFieldCount {
+		return fmt.Errorf("%w : %d fields", ErrInvalidMessage, len(fields))
+	}
+	msg.authzid = fields[0]
+	msg.authcid = fields[1]
+	msg.passwd = fields[2]
+	return msg.Prepare()
+}
+
+// Prepare validates the fields, and applies SASLprep to the authcid and the passwd.
+func (msg *Message) Prepare() error {
+	fields := []struct {
+		name  string
+		value *string
+		empty bool
+		prep  bool
+	}{
+		{"authzid", &msg.authzid, true, false},
+		{"authcid", &msg.authcid, false, true},
+		{"passwd", &msg.passwd, false, true},
+	}
+	for _, field := range fields {
+		value := *field.value
+		if len(value) == 0 {
+			if field.empty {
+				continue
+			}
+			return fmt.Errorf("%w : %s", ErrEmptyField, field.name)
+		}
+		if MaxFieldLength < len(value) {
+			return fmt.Errorf("%w : %s (%d octets)", ErrFieldTooLong, field.name, len(value))
+		}
+		if !utf8.ValidString(value) || strings.Contains(value, messageSeparator) {
+			return fmt.Errorf("%w : %s", ErrInvalidField, field.name)
+		}
+		if !field.prep {
+			continue
+		}
+		prepValue, err := prep.Normalize(value)
+		if err != nil {
+			return fmt.Errorf("%w : %s : %w", ErrInvalidField, field.name, err)
+		}
+		if len(prepValue) == 0 {
+			return fmt.Errorf("%w : %s", ErrEmptyField, field.name)
+		}
+		*field.value = prepValue
 	}
-	msg.authzid = strs[0]
-	msg.authcid = strs[1]
-	msg.passwd = strs[2]
 	return nil
 }
 
 // Bytes returns the message bytes.
 func (msg *Message) Bytes() []byte {
-	return []byte(msg.authzid + "\x00" + msg.authcid + "\x00" + msg.passwd)
+	return []byte(strings.Join([]string{msg.authzid, msg.authcid, msg.passwd}, messageSeparator))
 }
 
 // String returns the message string.
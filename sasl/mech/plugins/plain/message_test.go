@@ -15,7 +15,11 @@
 package plain
 
 import (
+	"errors"
+	"strings"
 	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl/prep"
 )
 
 func TestPlainMessage(t *testing.T) {
@@ -23,14 +27,6 @@ func TestPlainMessage(t *testing.T) {
 		message  []byte
 		expected string
 	}{
-		{
-			message:  []byte{0x00, 0x00, 0x63},
-			expected: ",,c",
-		},
-		{
-			message:  []byte{0x00, 0x62, 0x00, 0x63},
-			expected: ",b,c",
-		},
 		{
 			message:  []byte{0x00, 0x62, 0x00, 0x63},
 			expected: ",b,c",
@@ -40,16 +36,12 @@ func TestPlainMessage(t *testing.T) {
 			expected: "a,b,c",
 		},
 		{
-			message:  []byte{0x61, 0x00, 0x62, 0x00, 0x63, 0x00},
-			expected: "a,b,c",
-		},
-		{
-			message:  []byte{0x61, 0x00, 0x62, 0x00, 0x63, 0x00, 0x64},
-			expected: "a,b,c",
+			message:  []byte("\x00I\u00ADX\x00pass word"),
+			expected: ",IX,pass word",
 		},
 		{
-			message:  []byte{0x61, 0x00, 0x62, 0x00, 0x63, 0x00, 0x64, 0x00},
-			expected: "a,b,c",
+			message:  []byte("\x00" + strings.Repeat("b", MaxFieldLength) + "\x00c"),
+			expected: "," + strings.Repeat("b", MaxFieldLength) + ",c",
 		},
 	}
 	for _, test := range tests {
@@ -65,3 +57,41 @@ func TestPlainMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestInvalidPlainMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  []byte
+		expected error
+	}{
+		{"two-fields", []byte{0x61, 0x00, 0x62}, ErrInvalidMessage},
+		{"trailing-nul", []byte{0x61, 0x00, 0x62, 0x00, 0x63, 0x00}, ErrInvalidMessage},
+		{"four-fields", []byte{0x61, 0x00, 0x62, 0x00, 0x63, 0x00, 0x64}, ErrInvalidMessage},
+		{"empty-authcid", []byte{0x00, 0x00, 0x63}, ErrEmptyField},
+		{"empty-passwd", []byte{0x00, 0x62, 0x00}, ErrEmptyField},
+		{"prepared-empty-passwd", []byte("\x00b\x00\u00AD"), ErrEmptyField},
+		{"long-authzid", []byte(strings.Repeat("a", MaxFieldLength+1) + "\x00b\x00c"), ErrFieldTooLong},
+		{"long-authcid", []byte("\x00" + strings.Repeat("b", MaxFieldLength+1) + "\x00c"), ErrFieldTooLong},
+		{"long-passwd", []byte("\x00b\x00" + strings.Repeat("c", MaxFieldLength+1)), ErrFieldTooLong},
+		{"invalid-utf8", []byte("\x00b\x00\xff"), ErrInvalidField},
+		{"prohibited", []byte("\x00b\x00c\u0007"), prep.ErrInvalidString},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewMessageFrom(test.message)
+			if !errors.Is(err, test.expected) {
+				t.Errorf("%v is not %v", err, test.expected)
+			}
+		})
+	}
+
+	if err := NewMessageWith("", "", "c").Prepare(); !errors.Is(err, ErrEmptyField) {
+		t.Errorf("%v is not %v", err, ErrEmptyField)
+	}
+	if err := NewMessageWith("a\x00", "b", "c").Prepare(); !errors.Is(err, ErrInvalidField) {
+		t.Errorf("%v is not %v", err, ErrInvalidField)
+	}
+	if _, err := NewMessageFrom(NewMessageWith("", "b\u0007", "c")); !errors.Is(err, prep.ErrInvalidString) {
+		t.Errorf("%v is not %v", err, prep.ErrInvalidString)
+	}
+}
@@ -15,8 +15,8 @@
 package plain
 
 import (
+	"errors"
 	"fmt"
-	"net"
 	"slices"
 
 	"github.com/cybergarage/go-sasl/sasl/auth"
@@ -27,9 +27,10 @@ import (
 type ServerContext struct {
 	mechanism mech.Mechanism
 	mech.Store
-	step int
+	step   int
+	result mech.AuthResult
 	auth.Manager
-	net.Conn
+	conn auth.Conn
 }
 
 // NewServerContext returns a new PLAIN server context.
@@ -38,16 +39,17 @@ func NewServerContext(m mech.Mechanism, opts ...mech.Option) (*ServerContext, er
 		mechanism: m,
 		Store:     mech.NewStore(),
 		step:      0,
+		result:    nil,
 		Manager:   auth.NewManager(),
-		Conn:      nil,
+		conn:      nil,
 	}
 
 	for _, opt := range opts {
 		switch v := opt.(type) {
 		case auth.Manager:
 			ctx.Manager = v
-		case net.Conn:
-			ctx.Conn = v
+		case auth.Conn:
+			ctx.conn = v
 		}
 	}
 
@@ -74,31 +76,60 @@ func (ctx *ServerContext) Next(opts ...mech.Parameter) (mech.Response, error) {
 	switch ctx.step {
 	case 0:
 		if len(opts) == 0 {
-			return nil, fmt.Errorf("no message")
+			return nil, newError(mech.ReasonMalformed, Type, ctx.step, errors.New("no message"))
 		}
 		msg, err := NewMessageFrom(opts[0])
 		if err != nil {
-			return nil, err
+			return nil, newError(mech.ReasonMalformed, Type, ctx.step, err)
 		}
 
 		q, err := auth.NewQuery(
+			auth.WithQueryMechanism(Type),
 			auth.WithQueryGroup(msg.Authzid()),
 			auth.WithQueryUsername(msg.Authcid()),
 			auth.WithQueryPassword(msg.Passwd()),
 		)
 		if err != nil {
-			return nil, err
+			return nil, newError(mech.ReasonMalformed, Type, ctx.step, err)
 		}
 
-		ok, err := ctx.VerifyCredential(ctx.Conn, q)
+		ok, err := ctx.VerifyCredential(ctx.conn, q)
 		if !ok {
-			return nil, err
+			if err == nil {
+				err = fmt.Errorf("%w : %s", auth.ErrInvalidCredential, msg.Authcid())
+			}
+			return nil, newErrorFromAuth(Type, ctx.step, err)
 		}
+
+		err = ctx.Authorize(ctx.conn, msg.Authcid(), msg.Authzid(), Type)
+		if err != nil {
+			return nil, newErrorFromAuth(Type, ctx.step, err)
+		}
+		ctx.result = mech.NewAuthResult(
+			mech.WithAuthResultAuthcID(msg.Authcid()),
+			mech.WithAuthResultAuthzID(auth.AuthorizedID(msg.Authcid(), msg.Authzid())),
+			mech.WithAuthResultMechanism(Type),
+			mech.WithAuthResultRealm(auth.Realm(msg.Authcid())),
+		)
+
 		ctx.step++
 		return nil, nil
 	}
 
-	return nil, fmt.Errorf("invalid step : %d", ctx.step)
+	return nil, newError(mech.ReasonMalformed, Type, ctx.step, fmt.Errorf("invalid step : %d", ctx.step))
+}
+
+// AuthorizedID returns the identity associated with the connection after the context is completed successfully.
+func (ctx *ServerContext) AuthorizedID() string {
+	if ctx.result == nil {
+		return ""
+	}
+	return ctx.result.AuthzID()
+}
+
+// AuthResult returns the authentication result, or false if the context is not completed successfully.
+func (ctx *ServerContext) AuthResult() (mech.AuthResult, bool) {
+	return ctx.result, ctx.result != nil
 }
 
 // Dispose disposes the context.
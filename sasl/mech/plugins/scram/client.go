@@ -15,9 +15,11 @@
 package scram
 
 import (
+	"errors"
 	"fmt"
 	"slices"
 
+	"github.com/cybergarage/go-sasl/sasl/auth"
 	"github.com/cybergarage/go-sasl/sasl/mech"
 	"github.com/cybergarage/go-sasl/sasl/scram"
 )
@@ -26,7 +28,8 @@ import (
 type ClientContext struct {
 	mechanism mech.Mechanism
 
-	step int
+	step   int
+	result mech.AuthResult
 	*scram.Client
 }
 
@@ -39,6 +42,7 @@ func NewClientContext(m mech.Mechanism, opts ...scram.ClientOption) (*ClientCont
 	return &ClientContext{
 		mechanism: m,
 		step:      0,
+		result:    nil,
 		Client:    client,
 	}, nil
 }
@@ -76,6 +80,16 @@ func newClientOptions(opts ...any) ([]scram.ClientOption, error) {
 			clientOpts = append(clientOpts, scram.WithClientRandomSequence(string(v)))
 		case mech.Challenge:
 			clientOpts = append(clientOpts, scram.WithClientChallenge(string(v)))
+		case scram.KeyCache:
+			clientOpts = append(clientOpts, scram.WithClientKeyCache(v))
+		case scram.ClientOption:
+			clientOpts = append(clientOpts, v)
+		case scram.Extension:
+			clientOpts = append(clientOpts, scram.WithClientExtensions(v))
+		case scram.ExtensionRegistry:
+			clientOpts = append(clientOpts, scram.WithClientExtensionRegistry(v))
+		case mech.SecondFactor:
+			clientOpts = append(clientOpts, scram.WithClientSecondFactor(string(v)))
 		}
 	}
 	return clientOpts, nil
@@ -87,48 +101,77 @@ func (ctx *ClientContext) Next(opts ...mech.Parameter) (mech.Response, error) {
 	case 0:
 		clientOpts, err := newClientOptions(opts...)
 		if err != nil {
-			return nil, err
+			return nil, newErrorFrom(ctx.mechanism.Name(), ctx.step, err)
 		}
 		if err := ctx.Client.SetOptions(clientOpts...); err != nil {
-			return nil, err
+			return nil, newErrorFrom(ctx.mechanism.Name(), ctx.step, err)
 		}
 		res, err := ctx.Client.FirstMessage()
 		if err != nil {
-			return nil, err
+			return nil, newErrorFrom(ctx.mechanism.Name(), ctx.step, err)
 		}
 		ctx.step++
 		return res, nil
 	case 1:
 		if len(opts) == 0 {
-			return nil, fmt.Errorf("no client first message")
+			return nil, newError(mech.ReasonMalformed, ctx.mechanism.Name(), ctx.step, errors.New("no server first message"))
 		}
 		msg, err := scram.NewMessageFrom(opts[0])
 		if err != nil {
-			return nil, err
+			return nil, newErrorFrom(ctx.mechanism.Name(), ctx.step, err)
 		}
 		res, err := ctx.Client.FinalMessageFrom(msg)
 		if err != nil {
-			return nil, err
+			return nil, newErrorFrom(ctx.mechanism.Name(), ctx.step, err)
 		}
 		ctx.step++
 		return res, nil
 	case 2:
 		if len(opts) == 0 {
-			return nil, fmt.Errorf("no client final message")
+			return nil, newError(mech.ReasonMalformed, ctx.mechanism.Name(), ctx.step, errors.New("no server final message"))
 		}
 		msg, err := scram.NewMessageFrom(opts[0])
 		if err != nil {
-			return nil, err
+			return nil, newErrorFrom(ctx.mechanism.Name(), ctx.step, err)
 		}
 		err = ctx.Client.ValidateServerFinalMessage(msg)
 		if err != nil {
-			return nil, err
+			return nil, newErrorFrom(ctx.mechanism.Name(), ctx.step, err)
 		}
+		resultOpts := []mech.AuthResultOption{
+			mech.WithAuthResultAuthcID(ctx.Client.Username()),
+			mech.WithAuthResultAuthzID(auth.AuthorizedID(ctx.Client.Username(), ctx.Client.AuthzID())),
+			mech.WithAuthResultMechanism(ctx.mechanism.Name()),
+			mech.WithAuthResultRealm(auth.Realm(ctx.Client.Username())),
+			mech.WithAuthResultChannelBinding(ctx.Client.ChannelBinding()),
+		}
+		for name, value := range ctx.Client.ExtensionAttributes() {
+			resultOpts = append(resultOpts, mech.WithAuthResultAttribute(name, value))
+		}
+		ctx.result = mech.NewAuthResult(resultOpts...)
 		ctx.step++
 		return nil, nil
 	}
 
-	return nil, fmt.Errorf("invalid step : %d", ctx.step)
+	return nil, newError(mech.ReasonMalformed, ctx.mechanism.Name(), ctx.step, fmt.Errorf("invalid step : %d", ctx.step))
+}
+
+// Suspend returns an opaque token of the context which is sealed by the sealer.
+// The context can be suspended between any steps until it is completed, and is resumed by Resume of the mechanism.
+func (ctx *ClientContext) Suspend(sealer mech.TokenSealer) ([]byte, error) {
+	if ctx.Done() {
+		return nil, newError(mech.ReasonTemporaryFailure, ctx.mechanism.Name(), ctx.step, fmt.Errorf("not suspendable step : %d", ctx.step))
+	}
+	state, err := ctx.Client.MarshalState()
+	if err != nil {
+		return nil, newErrorFrom(ctx.mechanism.Name(), ctx.step, err)
+	}
+	return sealContextState(sealer, ctx.mechanism.Name(), ctx.step, state)
+}
+
+// AuthResult returns the authentication result, or false if the context is not completed successfully.
+func (ctx *ClientContext) AuthResult() (mech.AuthResult, bool) {
+	return ctx.result, ctx.result != nil
 }
 
 // Dispose disposes the context.
@@ -168,20 +211,52 @@ func (client *Client) SetOptions(opts ...mech.Option) error {
 
 // Start returns the initial context.
 func (client *Client) Start(opts ...mech.Option) (mech.Context, error) {
-	clientOpts, err := newClientOptions(slices.Concat(client.opts, opts)...)
+	clientOpts, err := client.clientOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := NewClientContext(client, clientOpts...)
+	if err != nil {
+		return nil, newErrorFrom(client.Name(), 0, err)
+	}
+	return ctx, nil
+}
+
+// Resume returns the context from the token returned by Suspend. The options are the same as Start.
+func (client *Client) Resume(sealer mech.TokenSealer, token []byte, opts ...mech.Option) (mech.Context, error) {
+	state, err := openContextState(sealer, client.Name(), token)
+	if err != nil {
+		return nil, newError(mech.ReasonMalformed, client.Name(), 0, err)
+	}
+	clientOpts, err := client.clientOptions(opts...)
 	if err != nil {
 		return nil, err
 	}
+	ctx, err := NewClientContext(client, clientOpts...)
+	if err != nil {
+		return nil, newErrorFrom(client.Name(), state.Step, err)
+	}
+	if err := ctx.Client.UnmarshalState(state.State); err != nil {
+		return nil, newError(mech.ReasonMalformed, client.Name(), state.Step, err)
+	}
+	ctx.step = state.Step
+	return ctx, nil
+}
+
+func (client *Client) clientOptions(opts ...mech.Option) ([]scram.ClientOption, error) {
+	clientOpts, err := newClientOptions(slices.Concat(client.opts, opts)...)
+	if err != nil {
+		return nil, newErrorFrom(client.Name(), 0, err)
+	}
 	switch client.scramType {
 	case SHA1:
 		clientOpts = append(clientOpts, scram.WithClientHashFunc(scram.HashSHA1()))
-		return NewClientContext(client, clientOpts...)
 	case SHA256:
 		clientOpts = append(clientOpts, scram.WithClientHashFunc(scram.HashSHA256()))
-		return NewClientContext(client, clientOpts...)
 	case SHA512:
 		clientOpts = append(clientOpts, scram.WithClientHashFunc(scram.HashSHA512()))
-		return NewClientContext(client, clientOpts...)
+	default:
+		return nil, newError(mech.ReasonUnsupportedMechanism, client.Name(), 0, fmt.Errorf("unknown SCRAM type : %d", client.scramType))
 	}
-	return nil, fmt.Errorf("unknown SCRAM type : %d", client.scramType)
+	return clientOpts, nil
 }
@@ -0,0 +1,70 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scram
+
+import (
+	"errors"
+
+	"github.com/cybergarage/go-sasl/sasl/auth"
+	"github.com/cybergarage/go-sasl/sasl/gss"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasl/scram"
+)
+
+func newError(reason mech.ErrorReason, name string, step int, err error) error {
+	return mech.NewError(reason,
+		mech.WithErrorMechanism(name),
+		mech.WithErrorStep(step),
+		mech.WithErrorCause(err),
+	)
+}
+
+// newErrorFrom returns a new error with the reason of the specified SCRAM or authentication error.
+// The SCRAM package returns no-resources for missing attributes, so it is handled as a malformed message.
+func newErrorFrom(name string, step int, err error) error {
+	if _, ok := mech.ReasonOf(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, auth.ErrAuthorizationDenied):
+		return newError(mech.ReasonAuthorizationDenied, name, step, err)
+	case errors.Is(err, scram.ErrInvalidProof),
+		errors.Is(err, scram.ErrUnknownUser),
+		errors.Is(err, scram.ErrChannelBindingsDontMatch),
+		errors.Is(err, scram.ErrSecondFactorRequired),
+		errors.Is(err, scram.ErrInvalidSecondFactor),
+		errors.Is(err, scram.ErrNoPassword):
+		return newError(mech.ReasonInvalidCredentials, name, step, err)
+	case errors.Is(err, scram.ErrInvalidEncoding),
+		errors.Is(err, scram.ErrInvalidUsernameEncoding),
+		errors.Is(err, scram.ErrExtensionsNotSupported),
+		errors.Is(err, scram.ErrChannelBindingNotSupported),
+		errors.Is(err, scram.ErrServerDoesSupportChannelBinding),
+		errors.Is(err, scram.ErrUnsupportedChannelBindingType),
+		errors.Is(err, scram.ErrNoResources),
+		errors.Is(err, scram.ErrOtherError),
+		errors.Is(err, scram.ErrInvalidIterationCount),
+		errors.Is(err, scram.ErrIterationCountTooLow),
+		errors.Is(err, scram.ErrIterationCountTooHigh),
+		errors.Is(err, scram.ErrSaltTooShort),
+		errors.Is(err, scram.ErrInvalidNonce),
+		errors.Is(err, scram.ErrTooManyAttributes),
+		errors.Is(err, scram.ErrDuplicateAttribute),
+		errors.Is(err, mech.ErrMessageTooLarge),
+		errors.Is(err, gss.ErrInvalidHeader):
+		return newError(mech.ReasonMalformed, name, step, err)
+	}
+	return newError(mech.ReasonTemporaryFailure, name, step, err)
+}
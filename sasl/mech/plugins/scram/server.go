@@ -15,9 +15,11 @@
 package scram
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/cybergarage/go-sasl/sasl/auth"
+	"github.com/cybergarage/go-sasl/sasl/instrument"
 	"github.com/cybergarage/go-sasl/sasl/mech"
 	"github.com/cybergarage/go-sasl/sasl/scram"
 )
@@ -26,7 +28,9 @@ import (
 type ServerContext struct {
 	mechanism mech.Mechanism
 
-	step int
+	step   int
+	result mech.AuthResult
+	err    error
 	*scram.Server
 }
 
@@ -39,6 +43,8 @@ func NewServerContext(m mech.Mechanism, opts ...scram.ServerOption) (*ServerCont
 	return &ServerContext{
 		mechanism: m,
 		step:      0,
+		result:    nil,
+		err:       nil,
 		Server:    server,
 	}, nil
 }
@@ -48,48 +54,97 @@ func (ctx *ServerContext) Mechanism() mech.Mechanism {
 	return ctx.mechanism
 }
 
-// Done returns true if the context is completed.
+// Done returns true if the context is completed. A failed context is not completed, and Err returns the failure.
 func (ctx *ServerContext) Done() bool {
 	return ctx.step == 2
 }
 
+// Err returns the error which failed the exchange, or nil if the exchange has not failed.
+func (ctx *ServerContext) Err() error {
+	return ctx.err
+}
+
 // Step returns the current step number. The step number is incremented by one after each call to Next.
 func (ctx *ServerContext) Step() int {
 	return ctx.step
 }
 
 // Next returns the next response.
+// If the exchange fails on the client-final-message, Next returns a server-final-message with the server-error attribute ("e=") together with the error.
+// RFC 5802 defines the server-error only in the server-final-message, so earlier failures return only the error, and the protocol aborts the exchange.
+// Every later call returns the same error.
 func (ctx *ServerContext) Next(opts ...mech.Parameter) (mech.Response, error) {
+	if ctx.err != nil {
+		return nil, ctx.err
+	}
+
 	if len(opts) == 0 {
-		return nil, fmt.Errorf("no message")
+		return nil, newError(mech.ReasonMalformed, ctx.mechanism.Name(), ctx.step, errors.New("no message"))
 	}
 
 	switch ctx.step {
 	case 0:
 		msg, err := scram.NewMessageFromWithHeader(opts[0])
 		if err != nil {
-			return nil, err
+			return ctx.fail(err)
 		}
 		res, err := ctx.Server.FirstMessageFrom(msg)
 		if err != nil {
-			return nil, err
+			return ctx.fail(err)
 		}
 		ctx.step++
 		return res, nil
 	case 1:
 		msg, err := scram.NewMessageFrom(opts[0])
 		if err != nil {
-			return nil, err
+			return ctx.fail(err)
 		}
 		res, err := ctx.Server.FinalMessageFrom(msg)
 		if err != nil {
-			return nil, err
+			return ctx.fail(err)
+		}
+		resultOpts := []mech.AuthResultOption{
+			mech.WithAuthResultAuthcID(ctx.Server.Username()),
+			mech.WithAuthResultAuthzID(ctx.Server.AuthorizedID()),
+			mech.WithAuthResultMechanism(ctx.mechanism.Name()),
+			mech.WithAuthResultRealm(auth.Realm(ctx.Server.Username())),
+			mech.WithAuthResultChannelBinding(ctx.Server.ChannelBinding()),
 		}
+		for name, value := range ctx.Server.ExtensionAttributes() {
+			resultOpts = append(resultOpts, mech.WithAuthResultAttribute(name, value))
+		}
+		ctx.result = mech.NewAuthResult(resultOpts...)
 		ctx.step++
 		return res, nil
 	}
 
-	return nil, fmt.Errorf("invalid step : %d", ctx.step)
+	return nil, newError(mech.ReasonMalformed, ctx.mechanism.Name(), ctx.step, fmt.Errorf("invalid step : %d", ctx.step))
+}
+
+func (ctx *ServerContext) fail(err error) (mech.Response, error) {
+	ctx.err = newErrorFrom(ctx.mechanism.Name(), ctx.step, err)
+	if ctx.step == 0 {
+		return nil, ctx.err
+	}
+	return scram.NewMessageWithError(err), ctx.err
+}
+
+// Suspend returns an opaque token of the context which is sealed by the sealer.
+// The context can be suspended only after the server first message, and is resumed by Resume of the mechanism.
+func (ctx *ServerContext) Suspend(sealer mech.TokenSealer) ([]byte, error) {
+	if ctx.step != 1 || ctx.err != nil {
+		return nil, newError(mech.ReasonTemporaryFailure, ctx.mechanism.Name(), ctx.step, fmt.Errorf("not suspendable step : %d", ctx.step))
+	}
+	state, err := ctx.Server.MarshalState()
+	if err != nil {
+		return nil, newErrorFrom(ctx.mechanism.Name(), ctx.step, err)
+	}
+	return sealContextState(sealer, ctx.mechanism.Name(), ctx.step, state)
+}
+
+// AuthResult returns the authentication result, or false if the context is not completed successfully.
+func (ctx *ServerContext) AuthResult() (mech.AuthResult, bool) {
+	return ctx.result, ctx.result != nil
 }
 
 // Dispose disposes the context.
@@ -129,7 +184,46 @@ func (server *Server) SetOptions(opts ...mech.Option) error {
 
 // Start returns the initial context.
 func (server *Server) Start(opts ...mech.Option) (mech.Context, error) {
-	serverOpts := []scram.ServerOption{}
+	serverOpts, err := server.serverOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := NewServerContext(server, serverOpts...)
+	if err != nil {
+		return nil, newErrorFrom(server.Name(), 0, err)
+	}
+	return ctx, nil
+}
+
+// Resume returns the context from the token returned by Suspend. The options are the same as Start.
+// The resumed attempt is checked by the limiter before the token is opened, and a token can be resumed only once.
+func (server *Server) Resume(sealer mech.TokenSealer, token []byte, opts ...mech.Option) (mech.Context, error) {
+	serverOpts, err := server.serverOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := NewServerContext(server, serverOpts...)
+	if err != nil {
+		return nil, newErrorFrom(server.Name(), 0, err)
+	}
+	if err := ctx.Server.AllowResume(); err != nil {
+		return nil, newErrorFrom(server.Name(), 0, err)
+	}
+	state, err := openContextState(sealer, server.Name(), token)
+	if err != nil {
+		return nil, newError(mech.ReasonMalformed, server.Name(), 0, err)
+	}
+	if err := ctx.Server.UnmarshalState(state.State); err != nil {
+		return nil, newError(mech.ReasonMalformed, server.Name(), state.Step, err)
+	}
+	ctx.step = state.Step
+	return ctx, nil
+}
+
+func (server *Server) serverOptions(opts ...mech.Option) ([]scram.ServerOption, error) {
+	serverOpts := []scram.ServerOption{
+		scram.WithServeMechanism(server.Name()),
+	}
 
 	switch server.scramType {
 	case SHA1:
@@ -139,13 +233,34 @@ func (server *Server) Start(opts ...mech.Option) (mech.Context, error) {
 	case SHA512:
 		serverOpts = append(serverOpts, scram.WithServerHashFunc(scram.HashSHA512()))
 	default:
-		return nil, fmt.Errorf("unknown SCRAM type : %d", server.scramType)
+		return nil, newError(mech.ReasonUnsupportedMechanism, server.Name(), 0, fmt.Errorf("unknown SCRAM type : %d", server.scramType))
 	}
 
+	var credStore auth.CredentialStore
+	var instrumentation instrument.Instrumentation
 	for _, opt := range append(server.opts, opts...) {
 		switch v := opt.(type) {
 		case auth.CredentialStore:
-			serverOpts = append(serverOpts, scram.WithServerCredentialStore(v))
+			credStore = v
+		case auth.Manager:
+			serverOpts = append(serverOpts, scram.WithServerAuthorizer(v))
+			if limiter := v.Limiter(); limiter != nil {
+				serverOpts = append(serverOpts, scram.WithServerLimiter(limiter))
+			}
+			if store := v.CredentialStore(); store != nil {
+				credStore = store
+			}
+			if i := v.Instrumentation(); i != nil {
+				instrumentation = i
+				serverOpts = append(serverOpts, scram.WithServerInstrumentation(i))
+			}
+		case auth.Conn:
+			serverOpts = append(serverOpts, scram.WithServerConn(v))
+		case instrument.Instrumentation:
+			instrumentation = v
+			serverOpts = append(serverOpts, scram.WithServerInstrumentation(v))
+		case mech.Username:
+			serverOpts = append(serverOpts, scram.WithServerUsername(string(v)))
 		case mech.RandomSequence:
 			serverOpts = append(serverOpts, scram.WithServerRandomSequence(string(v)))
 		case mech.HashFunc:
@@ -154,10 +269,22 @@ func (server *Server) Start(opts ...mech.Option) (mech.Context, error) {
 			serverOpts = append(serverOpts, scram.WithServerIterationCount(int(v)))
 		case mech.Salt:
 			serverOpts = append(serverOpts, scram.WithServerSaltString(string(v)))
+		case mech.MockSecret:
+			serverOpts = append(serverOpts, scram.WithServerMockSecret(v))
+		case scram.Extension:
+			serverOpts = append(serverOpts, scram.WithServerExtensions(v))
+		case scram.ExtensionRegistry:
+			serverOpts = append(serverOpts, scram.WithServerExtensionRegistry(v))
+		case scram.SecondFactorVerifier:
+			serverOpts = append(serverOpts, scram.WithServerSecondFactorVerifier(v))
 		default:
-			return nil, fmt.Errorf("unknown option : %v", v)
+			return nil, newError(mech.ReasonMalformed, server.Name(), 0, fmt.Errorf("unknown option : %v", v))
 		}
 	}
 
-	return NewServerContext(server, serverOpts...)
+	if credStore != nil {
+		serverOpts = append(serverOpts, scram.WithServerCredentialStore(auth.NewInstrumentedCredentialStore(credStore, instrumentation)))
+	}
+
+	return serverOpts, nil
 }
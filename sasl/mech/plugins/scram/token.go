@@ -0,0 +1,51 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scram
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+// contextState represents a suspended context.
+type contextState struct {
+	Step  int             `json:"step"`
+	State json.RawMessage `json:"state"`
+}
+
+func sealContextState(sealer mech.TokenSealer, name string, step int, state []byte) ([]byte, error) {
+	b, err := json.Marshal(&contextState{
+		Step:  step,
+		State: state,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sealer.Seal(name, b)
+}
+
+func openContextState(sealer mech.TokenSealer, name string, token []byte) (*contextState, error) {
+	b, err := sealer.Open(name, token)
+	if err != nil {
+		return nil, err
+	}
+	var state contextState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, fmt.Errorf("%w : %w", mech.ErrInvalidToken, err)
+	}
+	return &state, nil
+}
@@ -0,0 +1,47 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"time"
+)
+
+// AuthResult represents the result of a successfully completed authentication exchange.
+type AuthResult interface {
+	// AuthcID returns the authentication identity.
+	AuthcID() string
+	// AuthzID returns the authorization identity associated with the connection.
+	AuthzID() string
+	// Mechanism returns the mechanism name.
+	Mechanism() string
+	// Realm returns the realm of the authentication identity, or an empty string.
+	Realm() string
+	// SSF returns the security strength factor of the negotiated security layer, or zero if no security layer is negotiated.
+	SSF() int
+	// ChannelBinding returns the channel binding type, or an empty string if channel binding is not used.
+	ChannelBinding() string
+	// Timestamp returns the time when the exchange was completed.
+	Timestamp() time.Time
+	// Attribute returns a mechanism specific attribute.
+	Attribute(name string) (string, bool)
+	// Attributes returns all mechanism specific attributes.
+	Attributes() map[string]string
+}
+
+// AuthResultContext represents a SASL mechanism context which exposes the authentication result.
+type AuthResultContext interface {
+	// AuthResult returns the authentication result, or false if the context is not completed successfully.
+	AuthResult() (AuthResult, bool)
+}
@@ -0,0 +1,158 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"maps"
+	"time"
+)
+
+type authResult struct {
+	authcID        string
+	authzID        string
+	mechanism      string
+	realm          string
+	ssf            int
+	channelBinding string
+	timestamp      time.Time
+	attrs          map[string]string
+}
+
+// AuthResultOption represents an option for an authentication result.
+type AuthResultOption func(*authResult)
+
+// NewAuthResult returns a new authentication result with options.
+// The authorization identity defaults to the authentication identity, and the timestamp defaults to the current time.
+func NewAuthResult(opts ...AuthResultOption) AuthResult {
+	result := &authResult{
+		authcID:        "",
+		authzID:        "",
+		mechanism:      "",
+		realm:          "",
+		ssf:            0,
+		channelBinding: "",
+		timestamp:      time.Now(),
+		attrs:          map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(result)
+	}
+	if len(result.authzID) == 0 {
+		result.authzID = result.authcID
+	}
+	return result
+}
+
+// WithAuthResultAuthcID returns an option to set the authentication identity.
+func WithAuthResultAuthcID(authcID string) AuthResultOption {
+	return func(result *authResult) {
+		result.authcID = authcID
+	}
+}
+
+// WithAuthResultAuthzID returns an option to set the authorization identity.
+func WithAuthResultAuthzID(authzID string) AuthResultOption {
+	return func(result *authResult) {
+		result.authzID = authzID
+	}
+}
+
+// WithAuthResultMechanism returns an option to set the mechanism name.
+func WithAuthResultMechanism(mechanism string) AuthResultOption {
+	return func(result *authResult) {
+		result.mechanism = mechanism
+	}
+}
+
+// WithAuthResultRealm returns an option to set the realm.
+func WithAuthResultRealm(realm string) AuthResultOption {
+	return func(result *authResult) {
+		result.realm = realm
+	}
+}
+
+// WithAuthResultSSF returns an option to set the security strength factor.
+func WithAuthResultSSF(ssf int) AuthResultOption {
+	return func(result *authResult) {
+		result.ssf = ssf
+	}
+}
+
+// WithAuthResultChannelBinding returns an option to set the channel binding type.
+func WithAuthResultChannelBinding(cbType string) AuthResultOption {
+	return func(result *authResult) {
+		result.channelBinding = cbType
+	}
+}
+
+// WithAuthResultTimestamp returns an option to set the completion time.
+func WithAuthResultTimestamp(ts time.Time) AuthResultOption {
+	return func(result *authResult) {
+		result.timestamp = ts
+	}
+}
+
+// WithAuthResultAttribute returns an option to set a mechanism specific attribute.
+func WithAuthResultAttribute(name string, value string) AuthResultOption {
+	return func(result *authResult) {
+		result.attrs[name] = value
+	}
+}
+
+// AuthcID returns the authentication identity.
+func (result *authResult) AuthcID() string {
+	return result.authcID
+}
+
+// AuthzID returns the authorization identity associated with the connection.
+func (result *authResult) AuthzID() string {
+	return result.authzID
+}
+
+// Mechanism returns the mechanism name.
+func (result *authResult) Mechanism() string {
+	return result.mechanism
+}
+
+// Realm returns the realm of the authentication identity, or an empty string.
+func (result *authResult) Realm() string {
+	return result.realm
+}
+
+// SSF returns the security strength factor of the negotiated security layer.
+func (result *authResult) SSF() int {
+	return result.ssf
+}
+
+// ChannelBinding returns the channel binding type, or an empty string if channel binding is not used.
+func (result *authResult) ChannelBinding() string {
+	return result.channelBinding
+}
+
+// Timestamp returns the time when the exchange was completed.
+func (result *authResult) Timestamp() time.Time {
+	return result.timestamp
+}
+
+// Attribute returns a mechanism specific attribute.
+func (result *authResult) Attribute(name string) (string, bool) {
+	v, ok := result.attrs[name]
+	return v, ok
+}
+
+// Attributes returns all mechanism specific attributes.
+func (result *authResult) Attributes() map[string]string {
+	return maps.Clone(result.attrs)
+}
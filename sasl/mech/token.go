@@ -0,0 +1,61 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidToken is the error that is returned when a token is malformed, tampered or sealed for another mechanism.
+var ErrInvalidToken = errors.New("invalid token")
+
+// ErrTokenExpired is the error that is returned when a token is expired.
+var ErrTokenExpired = errors.New("token expired")
+
+// ErrTokenReplayed is the error that is returned when a token is opened more than once.
+var ErrTokenReplayed = errors.New("token replayed")
+
+// TokenSealer represents a sealer which encrypts and authenticates suspended contexts into opaque tokens.
+type TokenSealer interface {
+	// Seal encrypts the state of the mechanism and returns an opaque token which expires after the TTL of the sealer.
+	Seal(mechanism string, state []byte) ([]byte, error)
+	// Open verifies and decrypts the token of the mechanism, and returns the state.
+	// A token can be opened only once, and ErrTokenReplayed is returned if the token is opened again.
+	Open(mechanism string, token []byte) ([]byte, error)
+}
+
+// TokenReplayCache represents a cache of the IDs of opened tokens to reject replayed tokens.
+// Processes which share a token sealer key should share a replay cache, for example one backed by a shared store.
+type TokenReplayCache interface {
+	// Use records the token ID until the expiry, and returns false if the ID is already recorded.
+	Use(id []byte, expiry time.Time) bool
+}
+
+// SuspendableContext represents a context which can be suspended between steps and resumed later,
+// possibly by another process which shares the token sealer key.
+type SuspendableContext interface {
+	Context
+	// Suspend returns an opaque token of the context which is sealed by the sealer.
+	Suspend(sealer TokenSealer) ([]byte, error)
+}
+
+// ResumableMechanism represents a mechanism which can resume suspended contexts.
+type ResumableMechanism interface {
+	Mechanism
+	// Resume returns the context from the token returned by Suspend. The options are the same as Start,
+	// and secrets such as credential stores or passwords are not included in the token and must be specified again.
+	Resume(sealer TokenSealer, token []byte, opts ...Option) (Context, error)
+}
@@ -0,0 +1,195 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultTokenTTL is the default time-to-live of tokens.
+const DefaultTokenTTL = 5 * time.Minute
+
+const (
+	tokenReplayCachePruneSize = 1024
+	tokenVersion              = 1
+	tokenExpiryLen            = 8
+	tokenVersionLen           = 1
+)
+
+type tokenSealer struct {
+	aead        cipher.AEAD
+	ttl         time.Duration
+	clock       func() time.Time
+	replayCache TokenReplayCache
+}
+
+// TokenSealerOptionFn represents an option for a token sealer.
+type TokenSealerOptionFn func(*tokenSealer)
+
+// NewTokenSealer returns a new AES-GCM token sealer with the key and options.
+// The key must be 16, 24 or 32 bytes to select AES-128, AES-192 or AES-256,
+// and must be shared by all processes which resume the tokens.
+func NewTokenSealer(key []byte, opts ...TokenSealerOptionFn) (TokenSealer, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	sealer := &tokenSealer{
+		aead:        aead,
+		ttl:         DefaultTokenTTL,
+		clock:       time.Now,
+		replayCache: nil,
+	}
+	for _, opt := range opts {
+		opt(sealer)
+	}
+	if sealer.replayCache == nil {
+		sealer.replayCache = NewTokenReplayCache(WithTokenReplayCacheClock(sealer.clock))
+	}
+	return sealer, nil
+}
+
+// WithTokenSealerTTL returns an option to set the time-to-live of tokens.
+func WithTokenSealerTTL(ttl time.Duration) TokenSealerOptionFn {
+	return func(sealer *tokenSealer) {
+		sealer.ttl = ttl
+	}
+}
+
+// WithTokenSealerClock returns an option to set the clock of the token sealer.
+func WithTokenSealerClock(clock func() time.Time) TokenSealerOptionFn {
+	return func(sealer *tokenSealer) {
+		sealer.clock = clock
+	}
+}
+
+// WithTokenSealerReplayCache returns an option to set the replay cache of the token sealer.
+// By default, the token sealer has an in-memory replay cache, which rejects tokens replayed to the same process only.
+func WithTokenSealerReplayCache(cache TokenReplayCache) TokenSealerOptionFn {
+	return func(sealer *tokenSealer) {
+		sealer.replayCache = cache
+	}
+}
+
+// Seal encrypts the state of the mechanism and returns an opaque token which expires after the TTL.
+// The token is version || nonce || AES-GCM(expiry || state), and the mechanism name is authenticated as additional data.
+func (sealer *tokenSealer) Seal(mechanism string, state []byte) ([]byte, error) {
+	nonceSize := sealer.aead.NonceSize()
+	token := make([]byte, tokenVersionLen+nonceSize, tokenVersionLen+nonceSize+tokenExpiryLen+len(state)+sealer.aead.Overhead())
+	token[0] = tokenVersion
+	nonce := token[tokenVersionLen:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	plaintext := make([]byte, tokenExpiryLen, tokenExpiryLen+len(state))
+	expiry := sealer.clock().Add(sealer.ttl).Unix()
+	binary.BigEndian.PutUint64(plaintext, uint64(expiry))
+	plaintext = append(plaintext, state...)
+	return sealer.aead.Seal(token, nonce, plaintext, sealer.additionalData(mechanism)), nil
+}
+
+// Open verifies and decrypts the token of the mechanism, and returns the state.
+func (sealer *tokenSealer) Open(mechanism string, token []byte) ([]byte, error) {
+	nonceSize := sealer.aead.NonceSize()
+	if len(token) < tokenVersionLen+nonceSize+sealer.aead.Overhead()+tokenExpiryLen || token[0] != tokenVersion {
+		return nil, fmt.Errorf("%w : %s", ErrInvalidToken, mechanism)
+	}
+	nonce := token[tokenVersionLen : tokenVersionLen+nonceSize]
+	ciphertext := token[tokenVersionLen+nonceSize:]
+	plaintext, err := sealer.aead.Open(nil, nonce, ciphertext, sealer.additionalData(mechanism))
+	if err != nil {
+		return nil, fmt.Errorf("%w : %s", ErrInvalidToken, mechanism)
+	}
+	expiry := time.Unix(int64(binary.BigEndian.Uint64(plaintext)), 0)
+	if !sealer.clock().Before(expiry) {
+		return nil, fmt.Errorf("%w : %s", ErrTokenExpired, mechanism)
+	}
+	// The random nonce identifies the token, and is recorded only after the token is authenticated.
+	if !sealer.replayCache.Use(nonce, expiry) {
+		return nil, fmt.Errorf("%w : %s", ErrTokenReplayed, mechanism)
+	}
+	return plaintext[tokenExpiryLen:], nil
+}
+
+func (sealer *tokenSealer) additionalData(mechanism string) []byte {
+	return append([]byte{tokenVersion}, mechanism...)
+}
+
+type tokenReplayCache struct {
+	sync.Mutex
+	clock     func() time.Time
+	used      map[string]time.Time
+	pruneSize int
+}
+
+// TokenReplayCacheOptionFn represents an option for a token replay cache.
+type TokenReplayCacheOptionFn func(*tokenReplayCache)
+
+// NewTokenReplayCache returns a new in-memory token replay cache with options.
+// The IDs are removed after they expire.
+func NewTokenReplayCache(opts ...TokenReplayCacheOptionFn) TokenReplayCache {
+	cache := &tokenReplayCache{
+		Mutex:     sync.Mutex{},
+		clock:     time.Now,
+		used:      map[string]time.Time{},
+		pruneSize: tokenReplayCachePruneSize,
+	}
+	for _, opt := range opts {
+		opt(cache)
+	}
+	return cache
+}
+
+// WithTokenReplayCacheClock returns an option to set the clock of the token replay cache.
+func WithTokenReplayCacheClock(clock func() time.Time) TokenReplayCacheOptionFn {
+	return func(cache *tokenReplayCache) {
+		cache.clock = clock
+	}
+}
+
+// Use records the token ID until the expiry, and returns false if the ID is already recorded.
+func (cache *tokenReplayCache) Use(id []byte, expiry time.Time) bool {
+	cache.Lock()
+	defer cache.Unlock()
+	now := cache.clock()
+	if cache.pruneSize <= len(cache.used) {
+		cache.prune(now)
+	}
+	if usedUntil, ok := cache.used[string(id)]; ok && now.Before(usedUntil) {
+		return false
+	}
+	cache.used[string(id)] = expiry
+	return true
+}
+
+// prune removes the expired IDs, and doubles the size of the next pruning if most IDs are not expired yet.
+func (cache *tokenReplayCache) prune(now time.Time) {
+	for id, expiry := range cache.used {
+		if !now.Before(expiry) {
+			delete(cache.used, id)
+		}
+	}
+	cache.pruneSize = max(tokenReplayCachePruneSize, 2*len(cache.used))
+}
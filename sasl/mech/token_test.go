@@ -0,0 +1,113 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokenSealer(t *testing.T) {
+	key := bytes.Repeat([]byte{0x01}, 32)
+	now := time.Unix(1700000000, 0)
+	sealer, err := NewTokenSealer(key,
+		WithTokenSealerTTL(time.Minute),
+		WithTokenSealerClock(func() time.Time { return now }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := []byte("state")
+	token, err := sealer.Seal("SCRAM-SHA-256", state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(token, state) {
+		t.Error("token contains the plain state")
+	}
+
+	opened, err := sealer.Open("SCRAM-SHA-256", token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, state) {
+		t.Errorf("opened state = %q, want %q", opened, state)
+	}
+	if _, err := sealer.Open("SCRAM-SHA-256", token); !errors.Is(err, ErrTokenReplayed) {
+		t.Errorf("replayed token : %v", err)
+	}
+
+	if _, err := sealer.Open("SCRAM-SHA-1", token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("other mechanism : %v", err)
+	}
+
+	tampered := bytes.Clone(token)
+	tampered[len(tampered)-1] ^= 0x01
+	if _, err := sealer.Open("SCRAM-SHA-256", tampered); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("tampered token : %v", err)
+	}
+	if _, err := sealer.Open("SCRAM-SHA-256", token[:8]); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("short token : %v", err)
+	}
+
+	otherSealer, err := NewTokenSealer(bytes.Repeat([]byte{0x02}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := otherSealer.Open("SCRAM-SHA-256", token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("other key : %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := sealer.Open("SCRAM-SHA-256", token); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("expired token : %v", err)
+	}
+
+	if _, err := NewTokenSealer([]byte("short")); err == nil {
+		t.Error("invalid key length is accepted")
+	}
+}
+
+func TestTokenReplayCache(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	cache := NewTokenReplayCache(WithTokenReplayCacheClock(func() time.Time { return now }))
+	expiry := now.Add(time.Minute)
+
+	if !cache.Use([]byte("a"), expiry) {
+		t.Fatal("new ID is rejected")
+	}
+	if cache.Use([]byte("a"), expiry) {
+		t.Error("used ID is accepted")
+	}
+	if !cache.Use([]byte("b"), expiry) {
+		t.Error("other ID is rejected")
+	}
+
+	now = expiry
+	if !cache.Use([]byte("a"), now.Add(time.Minute)) {
+		t.Error("expired ID is rejected")
+	}
+
+	for n := range 10 * tokenReplayCachePruneSize {
+		now = now.Add(time.Second)
+		cache.Use([]byte{byte(n), byte(n >> 8), byte(n >> 16)}, now.Add(time.Second))
+	}
+	if n := len(cache.(*tokenReplayCache).used); 2*tokenReplayCachePruneSize < n {
+		t.Errorf("IDs = %d, want at most %d", n, 2*tokenReplayCachePruneSize)
+	}
+}
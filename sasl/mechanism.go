@@ -28,6 +28,47 @@ type Response = mech.Response
 // Context represents a SASL mechanism context.
 type Context = mech.Context
 
+// AuthorizedContext represents a SASL mechanism context which exposes the authorized identity.
+type AuthorizedContext = mech.AuthorizedContext
+
+// AuthResult represents the result of a successfully completed authentication exchange.
+type AuthResult = mech.AuthResult
+
+// AuthResultContext represents a SASL mechanism context which exposes the authentication result.
+type AuthResultContext = mech.AuthResultContext
+
+// TokenSealer represents a sealer which encrypts and authenticates suspended contexts into opaque tokens.
+type TokenSealer = mech.TokenSealer
+
+// TokenReplayCache represents a cache of the IDs of opened tokens to reject replayed tokens.
+type TokenReplayCache = mech.TokenReplayCache
+
+// SuspendableContext represents a context which can be suspended between steps and resumed later.
+type SuspendableContext = mech.SuspendableContext
+
+// ResumableMechanism represents a mechanism which can resume suspended contexts.
+type ResumableMechanism = mech.ResumableMechanism
+
+// ContextAs returns the context as T if the context or any context wrapped by it implements T.
+func ContextAs[T any](ctx Context) (T, bool) {
+	return mech.ContextAs[T](ctx)
+}
+
+// MechanismAs returns the mechanism as T if the mechanism or any mechanism wrapped by it implements T.
+func MechanismAs[T any](m Mechanism) (T, bool) {
+	return mech.MechanismAs[T](m)
+}
+
+// NewTokenSealer returns a new AES-GCM token sealer with the key and options.
+func NewTokenSealer(key []byte, opts ...mech.TokenSealerOptionFn) (TokenSealer, error) {
+	return mech.NewTokenSealer(key, opts...)
+}
+
+// NewTokenReplayCache returns a new in-memory token replay cache with options.
+func NewTokenReplayCache(opts ...mech.TokenReplayCacheOptionFn) TokenReplayCache {
+	return mech.NewTokenReplayCache(opts...)
+}
+
 // Option represents a SASL mechanism option.
 type Option = mech.Option
 
@@ -66,3 +107,12 @@ type Challenge = mech.Challenge
 
 // Salt represents a salt.
 type Salt = mech.Salt
+
+// SecondFactor represents a second factor such as a one-time password.
+type SecondFactor = mech.SecondFactor
+
+// ChannelBinding represents the channel binding type and data of the underlying secure channel such as "tls-exporter".
+type ChannelBinding = mech.ChannelBinding
+
+// MockSecret represents a server secret to continue exchanges for unknown users not to disclose which users exist.
+type MockSecret = mech.MockSecret
@@ -0,0 +1,64 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otp
+
+import (
+	"crypto/hmac"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"time"
+)
+
+// RFC 4226 - HOTP: An HMAC-Based One-Time Password Algorithm
+// https://datatracker.ietf.org/doc/html/rfc4226
+// RFC 6238 - TOTP: Time-Based One-Time Password Algorithm
+// https://datatracker.ietf.org/doc/html/rfc6238
+
+// HOTP returns the HOTP value of the counter with the specified number of digits.
+func HOTP(h func() hash.Hash, secret []byte, counter uint64, digits int) string {
+	// 5.3. Generating an HOTP Value
+	// Step 1: Generate an HMAC-SHA-1 value Let HS = HMAC-SHA-1(K,C)
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(h, secret)
+	mac.Write(msg[:])
+	hs := mac.Sum(nil)
+	// Step 2: Generate a 4-byte string (Dynamic Truncation)
+	offset := hs[len(hs)-1] & 0x0f
+	bin := binary.BigEndian.Uint32(hs[offset:offset+4]) & 0x7fffffff
+	// Step 3: Compute an HOTP value
+	mod := uint64(1)
+	for range digits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, uint64(bin)%mod)
+}
+
+// TOTP returns the TOTP value at the time with the specified time step and number of digits.
+func TOTP(h func() hash.Hash, secret []byte, t time.Time, period time.Duration, digits int) string {
+	return HOTP(h, secret, timeStep(t, period), digits)
+}
+
+// timeStep returns the number of time steps between the Unix time 0 and the time.
+// T = (Current Unix time - T0) / X.
+func timeStep(t time.Time, period time.Duration) uint64 {
+	secs := t.Unix()
+	step := int64(period / time.Second)
+	if secs < 0 || step <= 0 {
+		return 0
+	}
+	return uint64(secs / step)
+}
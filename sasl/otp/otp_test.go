@@ -0,0 +1,155 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otp
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"hash"
+	"testing"
+	"time"
+)
+
+func TestHOTP(t *testing.T) {
+	// RFC 4226 Appendix D - HOTP Algorithm: Test Values
+	secret := []byte("12345678901234567890")
+	expected := []string{
+		"755224", "287082", "359152", "969429", "338314",
+		"254676", "287922", "162583", "399871", "520489",
+	}
+	for counter, want := range expected {
+		if got := HOTP(sha1.New, secret, uint64(counter), 6); got != want {
+			t.Errorf("HOTP(%d) = %s, want %s", counter, got, want)
+		}
+	}
+}
+
+func TestTOTP(t *testing.T) {
+	// RFC 6238 Appendix B - Test Vectors
+	secrets := map[string][]byte{
+		"SHA1":   []byte("12345678901234567890"),
+		"SHA256": []byte("12345678901234567890123456789012"),
+		"SHA512": []byte("1234567890123456789012345678901234567890123456789012345678901234"),
+	}
+	hashes := map[string]func() hash.Hash{
+		"SHA1":   sha1.New,
+		"SHA256": sha256.New,
+		"SHA512": sha512.New,
+	}
+	tests := []struct {
+		time int64
+		mode string
+		want string
+	}{
+		{59, "SHA1", "94287082"},
+		{59, "SHA256", "46119246"},
+		{59, "SHA512", "90693936"},
+		{1111111109, "SHA1", "07081804"},
+		{1111111109, "SHA256", "68084774"},
+		{1111111109, "SHA512", "25091201"},
+		{1111111111, "SHA1", "14050471"},
+		{1111111111, "SHA256", "67062674"},
+		{1111111111, "SHA512", "99943326"},
+		{1234567890, "SHA1", "89005924"},
+		{1234567890, "SHA256", "91819424"},
+		{1234567890, "SHA512", "93441116"},
+		{2000000000, "SHA1", "69279037"},
+		{2000000000, "SHA256", "90698825"},
+		{2000000000, "SHA512", "38618901"},
+		{20000000000, "SHA1", "65353130"},
+		{20000000000, "SHA256", "77737706"},
+		{20000000000, "SHA512", "47863826"},
+	}
+	for _, tt := range tests {
+		got := TOTP(hashes[tt.mode], secrets[tt.mode], time.Unix(tt.time, 0), DefaultPeriod, 8)
+		if got != tt.want {
+			t.Errorf("TOTP(%d, %s) = %s, want %s", tt.time, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestTOTPVerifier(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	now := time.Unix(1111111111, 0)
+	v := NewTOTPVerifier(
+		WithVerifierSecret("admin", secret),
+		WithVerifierClock(func() time.Time { return now }),
+	)
+
+	if typ, ok, err := v.SecondFactor("admin"); err != nil || !ok || typ != string(TOTPType) {
+		t.Errorf("SecondFactor(admin) = %s, %t, %v", typ, ok, err)
+	}
+	if _, ok, _ := v.SecondFactor("user"); ok {
+		t.Error("SecondFactor(user) is required")
+	}
+
+	code := TOTP(sha1.New, secret, now, DefaultPeriod, DefaultDigits)
+	if err := v.VerifySecondFactor("admin", code); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.VerifySecondFactor("admin", code); !errors.Is(err, ErrReplayedCode) {
+		t.Errorf("replayed code: %v", err)
+	}
+
+	// The previous time step is accepted within the window unless a later code has been used.
+	prev := TOTP(sha1.New, secret, now.Add(-DefaultPeriod), DefaultPeriod, DefaultDigits)
+	if err := v.VerifySecondFactor("admin", prev); !errors.Is(err, ErrReplayedCode) {
+		t.Errorf("previous code: %v", err)
+	}
+	next := TOTP(sha1.New, secret, now.Add(DefaultPeriod), DefaultPeriod, DefaultDigits)
+	if err := v.VerifySecondFactor("admin", next); err != nil {
+		t.Errorf("next code: %v", err)
+	}
+
+	now = now.Add(10 * DefaultPeriod)
+	stale := TOTP(sha1.New, secret, now.Add(-2*DefaultPeriod), DefaultPeriod, DefaultDigits)
+	if err := v.VerifySecondFactor("admin", stale); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("stale code: %v", err)
+	}
+	if err := v.VerifySecondFactor("user", code); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("unknown user: %v", err)
+	}
+}
+
+func TestHOTPVerifier(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	v := NewHOTPVerifier(
+		WithVerifierSecret("admin", secret),
+		WithVerifierWindow(2),
+	)
+
+	// RFC 4226 Appendix D - counter 1 and 2 are accepted ahead of the counter 0.
+	if err := v.VerifySecondFactor("admin", "287082"); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.VerifySecondFactor("admin", "287082"); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("reused code: %v", err)
+	}
+	if err := v.VerifySecondFactor("admin", "755224"); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("skipped code: %v", err)
+	}
+	if err := v.VerifySecondFactor("admin", "359152"); err != nil {
+		t.Error(err)
+	}
+	// The counter 6 is beyond the window from the next counter 3.
+	if err := v.VerifySecondFactor("admin", "287922"); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("out of window code: %v", err)
+	}
+	if err := v.VerifySecondFactor("admin", "12345"); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("short code: %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otp
+
+// Type represents a type of one-time passwords.
+type Type string
+
+const (
+	// TOTPType represents RFC 6238 time-based one-time passwords.
+	TOTPType Type = "totp"
+	// HOTPType represents RFC 4226 counter-based one-time passwords.
+	HOTPType Type = "hotp"
+)
+
+// Verifier represents a one-time password verifier which can be used as a second factor verifier.
+type Verifier interface {
+	// Type returns the type of the one-time passwords.
+	Type() Type
+	// SetSecret registers the shared secret of the user, and resets the counter of the user.
+	SetSecret(username string, secret []byte)
+	// SecondFactor returns the type of the one-time passwords and true if the user has a registered secret.
+	SecondFactor(username string) (string, bool, error)
+	// VerifySecondFactor verifies the one-time password of the user.
+	// An accepted password is not accepted again to prevent replay attacks.
+	VerifySecondFactor(username string, code string) error
+}
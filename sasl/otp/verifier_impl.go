@@ -0,0 +1,183 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otp
+
+import (
+	"crypto/sha1"
+	"crypto/subtle"
+	"fmt"
+	"hash"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDigits is the default number of digits of one-time passwords.
+	DefaultDigits = 6
+	// DefaultPeriod is the default time step of TOTP.
+	DefaultPeriod = 30 * time.Second
+	// DefaultTOTPWindow is the default number of time steps accepted before and after the current time step.
+	DefaultTOTPWindow = 1
+	// DefaultHOTPWindow is the default number of counters accepted ahead of the next expected counter.
+	DefaultHOTPWindow = 10
+)
+
+type userSecret struct {
+	secret []byte
+	// next is the smallest counter or time step which is not used yet.
+	next uint64
+}
+
+type verifier struct {
+	sync.Mutex
+	typ      Type
+	hashFunc func() hash.Hash
+	digits   int
+	period   time.Duration
+	window   int
+	clock    func() time.Time
+	users    map[string]*userSecret
+}
+
+// VerifierOptionFn represents an option for a verifier.
+type VerifierOptionFn func(*verifier)
+
+// NewTOTPVerifier returns a new in-memory RFC 6238 TOTP verifier with options.
+func NewTOTPVerifier(opts ...VerifierOptionFn) Verifier {
+	return newVerifier(TOTPType, DefaultTOTPWindow, opts...)
+}
+
+// NewHOTPVerifier returns a new in-memory RFC 4226 HOTP verifier with options.
+func NewHOTPVerifier(opts ...VerifierOptionFn) Verifier {
+	return newVerifier(HOTPType, DefaultHOTPWindow, opts...)
+}
+
+func newVerifier(typ Type, window int, opts ...VerifierOptionFn) *verifier {
+	v := &verifier{
+		Mutex:    sync.Mutex{},
+		typ:      typ,
+		hashFunc: sha1.New,
+		digits:   DefaultDigits,
+		period:   DefaultPeriod,
+		window:   window,
+		clock:    time.Now,
+		users:    map[string]*userSecret{},
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// WithVerifierHashFunc returns an option to set the hash function of the HMAC. The default is SHA-1.
+func WithVerifierHashFunc(h func() hash.Hash) VerifierOptionFn {
+	return func(v *verifier) {
+		v.hashFunc = h
+	}
+}
+
+// WithVerifierDigits returns an option to set the number of digits of the one-time passwords.
+func WithVerifierDigits(digits int) VerifierOptionFn {
+	return func(v *verifier) {
+		v.digits = digits
+	}
+}
+
+// WithVerifierPeriod returns an option to set the time step of TOTP.
+func WithVerifierPeriod(period time.Duration) VerifierOptionFn {
+	return func(v *verifier) {
+		v.period = period
+	}
+}
+
+// WithVerifierWindow returns an option to set the number of the time steps accepted before and after the current time step for TOTP,
+// or the number of the counters accepted ahead of the next expected counter for HOTP.
+func WithVerifierWindow(window int) VerifierOptionFn {
+	return func(v *verifier) {
+		v.window = window
+	}
+}
+
+// WithVerifierClock returns an option to set the clock of the TOTP verifier.
+func WithVerifierClock(clock func() time.Time) VerifierOptionFn {
+	return func(v *verifier) {
+		v.clock = clock
+	}
+}
+
+// WithVerifierSecret returns an option to register the shared secret of the user.
+func WithVerifierSecret(username string, secret []byte) VerifierOptionFn {
+	return func(v *verifier) {
+		v.SetSecret(username, secret)
+	}
+}
+
+// Type returns the type of the one-time passwords.
+func (v *verifier) Type() Type {
+	return v.typ
+}
+
+// SetSecret registers the shared secret of the user, and resets the counter of the user.
+func (v *verifier) SetSecret(username string, secret []byte) {
+	v.Lock()
+	defer v.Unlock()
+	v.users[username] = &userSecret{
+		secret: secret,
+		next:   0,
+	}
+}
+
+// SecondFactor returns the type of the one-time passwords and true if the user has a registered secret.
+func (v *verifier) SecondFactor(username string) (string, bool, error) {
+	v.Lock()
+	defer v.Unlock()
+	_, ok := v.users[username]
+	return string(v.typ), ok, nil
+}
+
+// VerifySecondFactor verifies the one-time password of the user.
+func (v *verifier) VerifySecondFactor(username string, code string) error {
+	v.Lock()
+	defer v.Unlock()
+	user, ok := v.users[username]
+	if !ok || len(code) != v.digits {
+		return fmt.Errorf("%w : %s", ErrInvalidCode, username)
+	}
+
+	var first, last uint64
+	switch v.typ {
+	case TOTPType:
+		step := timeStep(v.clock(), v.period)
+		first = step - min(step, uint64(v.window))
+		last = step + uint64(v.window)
+	case HOTPType:
+		first = user.next
+		last = user.next + uint64(v.window)
+	}
+
+	for counter := first; counter <= last; counter++ {
+		expected := HOTP(v.hashFunc, user.secret, counter, v.digits)
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) != 1 {
+			continue
+		}
+		if counter < user.next {
+			return fmt.Errorf("%w : %s", ErrReplayedCode, username)
+		}
+		user.next = counter + 1
+		return nil
+	}
+
+	return fmt.Errorf("%w : %s", ErrInvalidCode, username)
+}
@@ -14,12 +14,56 @@
 
 package prep
 
+import (
+	"errors"
+	"fmt"
+
+	"github.com/xdg-go/stringprep"
+)
+
 // RFC 4013 - SASLprep: Stringprep Profile for User Names and Passwords
 // https://datatracker.ietf.org/doc/html/rfc4013
 // RFC 3454 - Preparation of Internationalized Strings
 // https://datatracker.ietf.org/doc/html/rfc3454
 
-// Normalize returns the SASLprep normalized string.
+// ErrInvalidString is returned when the string includes prohibited characters or violates the bidirectional rules.
+var ErrInvalidString = errors.New("invalid string")
+
+// saslprepQuery is the SASLprep profile for query strings which allows unassigned code points (RFC 3454 section 7).
+var saslprepQuery = stringprep.Profile{
+	Mappings:  stringprep.SASLprep.Mappings,
+	Normalize: stringprep.SASLprep.Normalize,
+	Prohibits: []stringprep.Set{
+		stringprep.TableC1_2,
+		stringprep.TableC2_1,
+		stringprep.TableC2_2,
+		stringprep.TableC3,
+		stringprep.TableC4,
+		stringprep.TableC5,
+		stringprep.TableC6,
+		stringprep.TableC7,
+		stringprep.TableC8,
+		stringprep.TableC9,
+	},
+	CheckBiDi: stringprep.SASLprep.CheckBiDi,
+}
+
+// Normalize returns the SASLprep normalized string as a query string which may include unassigned code points.
+// RFC 5802 defines Normalize() of SCRAM in this way, and it applies to the received identities and passwords.
 func Normalize(str string) (string, error) {
-	return str, nil
+	return prepare(saslprepQuery, str)
+}
+
+// NormalizeStored returns the SASLprep normalized string as a stored string which must not include unassigned code points.
+// It applies to the identities and passwords before they are stored in credential stores.
+func NormalizeStored(str string) (string, error) {
+	return prepare(stringprep.SASLprep, str)
+}
+
+func prepare(profile stringprep.Profile, str string) (string, error) {
+	prepStr, err := profile.Prepare(str)
+	if err != nil {
+		return "", fmt.Errorf("%w : %s", ErrInvalidString, err.Error())
+	}
+	return prepStr, nil
 }
@@ -15,8 +15,54 @@
 package prep
 
 import (
+	"errors"
 	"testing"
 )
 
 func TestNormalize(t *testing.T) {
+	tests := []struct {
+		str      string
+		expected string
+	}{
+		{"user", "user"},
+		{"USER", "USER"},
+		{"I\u00ADX", "IX"},
+		{"user\u2000name", "user name"},
+		{"\u00AA", "a"},
+		{"\u2168", "IX"},
+	}
+	for _, test := range tests {
+		str, err := Normalize(test.str)
+		if err != nil {
+			t.Errorf("%q: %v", test.str, err)
+			continue
+		}
+		if str != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, str)
+		}
+	}
+
+	for _, str := range []string{"\u0007", "\u0627\u0031"} {
+		if _, err := Normalize(str); !errors.Is(err, ErrInvalidString) {
+			t.Errorf("%q: %v is not %v", str, err, ErrInvalidString)
+		}
+		if _, err := NormalizeStored(str); !errors.Is(err, ErrInvalidString) {
+			t.Errorf("%q: %v is not %v", str, err, ErrInvalidString)
+		}
+	}
+}
+
+func TestNormalizeUnassigned(t *testing.T) {
+	// U+1F600 is unassigned in Unicode 3.2 which stringprep is based on.
+	const str = "pass\U0001F600"
+	prepStr, err := Normalize(str)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prepStr != str {
+		t.Errorf("expected %q, got %q", str, prepStr)
+	}
+	if _, err := NormalizeStored(str); !errors.Is(err, ErrInvalidString) {
+		t.Errorf("%q: %v is not %v", str, err, ErrInvalidString)
+	}
 }
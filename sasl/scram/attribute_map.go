@@ -78,6 +78,33 @@ func (m *AttributeMap) FutureFutureExtensibility() (string, bool) {
 	return m.Attribute(FutureExtensibilityAttr)
 }
 
+// MandatoryExtensions returns the names of the mandatory extensions in the future extensibility attribute.
+// Each letter of the attribute value is the attribute name of a mandatory extension
+// if the extension registry negotiates extensions (see WithExtensionNegotiation).
+func (m *AttributeMap) MandatoryExtensions() []string {
+	v, ok := m.Attribute(FutureExtensibilityAttr)
+	if !ok {
+		return []string{}
+	}
+	names := make([]string, 0, len(v))
+	for _, c := range v {
+		names = append(names, string(c))
+	}
+	return names
+}
+
+// ExtensionAttributes returns the attributes which are not defined by RFC 5802.
+func (m *AttributeMap) ExtensionAttributes() map[string]string {
+	attrs := map[string]string{}
+	for _, key := range m.keys {
+		if isStandardAttribute(key) {
+			continue
+		}
+		attrs[key] = m.attrs[key].Value()
+	}
+	return attrs
+}
+
 // RandomSequence returns the random sequence attribute from the map.
 func (m *AttributeMap) RandomSequence() (string, bool) {
 	return m.Attribute(RandomSequenceAttr)
@@ -17,6 +17,8 @@ package scram
 import (
 	"bytes"
 	"encoding/base64"
+	"fmt"
+	"maps"
 	"strings"
 
 	"github.com/cybergarage/go-sasl/sasl/gss"
@@ -33,6 +35,14 @@ type Client struct {
 	username       string
 	password       string
 	hashFunc       HashFunc
+	keyCache       KeyCache
+	serverKey      []byte
+	minIterCount   int
+	maxIterCount   int
+	minSaltLength  int
+	validateNonce  bool
+	extensions     ExtensionRegistry
+	extensionAttrs map[string]string
 	challenge      string
 	clientFirstMsg *Message
 	clientFinalMsg *Message
@@ -51,6 +61,14 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 		username:       "",
 		password:       "",
 		hashFunc:       HashSHA256(),
+		keyCache:       nil,
+		serverKey:      nil,
+		minIterCount:   minimumIterationCount,
+		maxIterCount:   0,
+		minSaltLength:  0,
+		validateNonce:  true,
+		extensions:     NewExtensionRegistry(),
+		extensionAttrs: map[string]string{},
 		challenge:      "",
 		randomSequence: "",
 		clientFirstMsg: nil,
@@ -104,6 +122,80 @@ func WithClientHashFunc(hashFunc HashFunc) ClientOption {
 	}
 }
 
+// WithClientKeyCache returns a client option to set the key cache which is shared to skip Hi() computations.
+func WithClientKeyCache(cache KeyCache) ClientOption {
+	return func(client *Client) error {
+		client.keyCache = cache
+		return nil
+	}
+}
+
+// WithClientMinIterationCount returns a client option to set the minimum iteration count which the server may send.
+// The default minimum is 4096 as recommended by RFC 5802.
+func WithClientMinIterationCount(n int) ClientOption {
+	return func(client *Client) error {
+		client.minIterCount = n
+		return nil
+	}
+}
+
+// WithClientMaxIterationCount returns a client option to set the maximum iteration count which the server may send.
+// A non-positive count, which is the default, means no limit.
+func WithClientMaxIterationCount(n int) ClientOption {
+	return func(client *Client) error {
+		client.maxIterCount = n
+		return nil
+	}
+}
+
+// WithClientMinSaltLength returns a client option to set the minimum length of the decoded salt which the server may send.
+func WithClientMinSaltLength(n int) ClientOption {
+	return func(client *Client) error {
+		client.minSaltLength = n
+		return nil
+	}
+}
+
+// WithClientNonceValidation returns a client option to set whether the client validates that the server appends
+// its own printable nonce to the client nonce. The client always verifies that the server nonce starts with the client nonce.
+func WithClientNonceValidation(enabled bool) ClientOption {
+	return func(client *Client) error {
+		client.validateNonce = enabled
+		return nil
+	}
+}
+
+// WithClientExtensions returns a client option to register the extensions.
+func WithClientExtensions(exts ...Extension) ClientOption {
+	return func(client *Client) error {
+		for _, ext := range exts {
+			if err := client.extensions.RegisterExtension(ext); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// WithClientSecondFactor returns a client option to send the second factor when the server requires it.
+func WithClientSecondFactor(code string) ClientOption {
+	return WithClientExtensions(NewSecondFactorClientExtension(code))
+}
+
+// WithClientExtensionRegistry returns a client option to set the extension registry.
+// The extensions which are registered before are registered to the registry.
+func WithClientExtensionRegistry(reg ExtensionRegistry) ClientOption {
+	return func(client *Client) error {
+		for _, ext := range client.extensions.Extensions() {
+			if err := reg.RegisterExtension(ext); err != nil {
+				return err
+			}
+		}
+		client.extensions = reg
+		return nil
+	}
+}
+
 // WithClientRandomSequence returns a client option to set the random sequence.
 func WithClientRandomSequence(randomSequence string) ClientOption {
 	return func(client *Client) error {
@@ -199,14 +291,35 @@ func (client *Client) HashFunc() HashFunc {
 	return client.hashFunc
 }
 
+// Username returns the authentication identity.
+func (client *Client) Username() string {
+	return client.username
+}
+
+// AuthzID returns the authorization identity.
+func (client *Client) AuthzID() string {
+	return client.authzID
+}
+
+// Extensions returns the extension registry.
+func (client *Client) Extensions() ExtensionRegistry {
+	return client.extensions
+}
+
+// ExtensionAttributes returns the extension attributes received from the server.
+func (client *Client) ExtensionAttributes() map[string]string {
+	return maps.Clone(client.extensionAttrs)
+}
+
+// ChannelBinding returns the channel binding type used by the client, or an empty string if channel binding is not used.
+func (client *Client) ChannelBinding() string {
+	return channelBindingOf(client.clientFirstMsg)
+}
+
 // FirstMessage returns the first message.
 func (client *Client) FirstMessage() (*Message, error) {
 	msg := NewMessage(WithHeader(gss.NewHeader()))
 
-	if 0 < len(client.authzID) {
-		msg.SetAuthzID(util.EncodeName(client.authzID))
-	}
-
 	// GS2 Header
 
 	msg.SetCBFlag(gss.ClientDoesNotSupportCBSFlag)
@@ -214,18 +327,28 @@ func (client *Client) FirstMessage() (*Message, error) {
 		msg.SetAuthzID(client.authzID)
 	}
 
-	// n: username
+	// m: mandatory extensions
 
-	if 0 < len(client.username) {
-		msg.SetUsername(util.EncodeName(client.username))
-		client.SetValue(UsernameID, client.username)
+	if names := mandatoryExtensionNames(client.extensions); 0 < len(names) {
+		msg.SetFutureExtensibility(names)
 	}
 
+	// n: username
+
+	msg.SetUsername(util.EncodeName(client.username))
+	client.SetValue(UsernameID, client.username)
+
 	// r: random sequence
 
 	msg.SetRandomSequence(client.randomSequence)
 	client.SetValue(RandomSequenceID, client.randomSequence)
 
+	// extensions
+
+	if err := sendExtensions(client.extensions, client, ClientFirstMessage, msg); err != nil {
+		return nil, err
+	}
+
 	client.clientFirstMsg = msg
 
 	return msg, nil
@@ -241,6 +364,20 @@ func (client *Client) FinalMessageFrom(serverFirstMsg *Message) (*Message, error
 		return nil, ErrNoResources
 	}
 
+	if err := serverFirstMsg.ServerError(); err != nil {
+		return nil, err
+	}
+
+	// m: The client MUST fail the exchange if the server requires extensions which the client does not support.
+
+	if err := checkMandatoryExtensions(client.extensions, serverFirstMsg); err != nil {
+		return nil, err
+	}
+	maps.Copy(client.extensionAttrs, extensionAttributes(client.extensions, serverFirstMsg))
+	if err := receiveExtensions(client.extensions, client, ServerFirstMessage, serverFirstMsg); err != nil {
+		return nil, err
+	}
+
 	client.serverFirstMsg = serverFirstMsg
 
 	msg := NewMessage()
@@ -264,8 +401,8 @@ func (client *Client) FinalMessageFrom(serverFirstMsg *Message) (*Message, error
 	if !ok {
 		return nil, ErrNoResources
 	}
-	if !strings.HasPrefix(serverRS, clientRS) {
-		return nil, ErrOtherError
+	if err := client.validateServerNonce(clientRS, serverRS); err != nil {
+		return nil, err
 	}
 	msg.SetRandomSequence(serverRS)
 
@@ -277,8 +414,11 @@ func (client *Client) FinalMessageFrom(serverFirstMsg *Message) (*Message, error
 		return nil, ErrNoResources
 	}
 
-	if ic < minimumIterationCount {
-		return nil, ErrOtherError
+	if ic < client.minIterCount || ic < 1 {
+		return nil, fmt.Errorf("%w : %d", ErrIterationCountTooLow, ic)
+	}
+	if 0 < client.maxIterCount && client.maxIterCount < ic {
+		return nil, fmt.Errorf("%w : %d", ErrIterationCountTooHigh, ic)
 	}
 
 	// SaltedPassword := Hi(Normalize(password), salt, i)
@@ -288,22 +428,32 @@ func (client *Client) FinalMessageFrom(serverFirstMsg *Message) (*Message, error
 		return nil, ErrNoResources
 	}
 
-	saltedPassword, err := SaltedPassword(client.hashFunc, client.password, salt, ic)
-	if err != nil {
-		return nil, err
+	if len(salt) < client.minSaltLength {
+		return nil, fmt.Errorf("%w : %d", ErrSaltTooShort, len(salt))
 	}
-	client.SetValue(SaltedPasswordID, saltedPassword)
 
 	// ClientKey := HMAC(SaltedPassword, "Client Key")
+	// ServerKey := HMAC(SaltedPassword, "Server Key")
 
-	clientKey := ClientKey(client.hashFunc, saltedPassword)
+	clientKey, serverKey, err := client.keys(salt, ic)
+	if err != nil {
+		return nil, err
+	}
 	client.SetValue(ClientKeyID, clientKey)
+	client.SetValue(ServerKeyID, serverKey)
+	client.serverKey = serverKey
 
 	//  StoredKey := H(ClientKey)
 
 	storedKey := H(client.hashFunc, clientKey)
 	client.SetValue(StoredKeyID, storedKey)
 
+	// extensions: The extensions are sent before the proof, and are protected by the proof.
+
+	if err := sendExtensions(client.extensions, client, ClientFinalMessage, msg); err != nil {
+		return nil, err
+	}
+
 	// AuthMessage := client-first-message-bare + "," +
 	//                server-first-message + "," +
 	//                client-final-message-without-proof
@@ -333,6 +483,12 @@ func (client *Client) ValidateServerFinalMessage(serverFinalMsg *Message) error
 		return ErrNoResources
 	}
 
+	// e: This attribute specifies an error that occurred during authentication exchange.
+
+	if err := serverFinalMsg.ServerError(); err != nil {
+		return err
+	}
+
 	if client.clientFirstMsg == nil {
 		return ErrNoResources
 	}
@@ -350,24 +506,12 @@ func (client *Client) ValidateServerFinalMessage(serverFinalMsg *Message) error
 		return ErrNoResources
 	}
 
-	// SaltedPassword := Hi(Normalize(password), salt, i)
+	// The server key is derived from the salted password in the client final message.
 
-	ic, ok := client.serverFirstMsg.IterationCount()
-	if !ok {
+	if client.clientFinalMsg == nil || client.serverKey == nil {
 		return ErrNoResources
 	}
 
-	salt, ok := client.serverFirstMsg.Salt()
-	if !ok {
-		return ErrNoResources
-	}
-
-	saltedPassword, err := SaltedPassword(client.hashFunc, client.password, salt, ic)
-	if err != nil {
-		return err
-	}
-	client.SetValue(SaltedPasswordID, saltedPassword)
-
 	// AuthMessage := client-first-message-bare + "," +
 	//                server-first-message + "," +
 	//                client-final-message-without-proof
@@ -375,18 +519,74 @@ func (client *Client) ValidateServerFinalMessage(serverFinalMsg *Message) error
 	authMsg := AuthMessage(client.clientFirstMsg.StringWithoutHeader(), client.serverFirstMsg.String(), client.clientFinalMsg.StringWithoutProof())
 	client.SetValue(AuthMessageID, authMsg)
 
-	// ServerKey := HMAC(SaltedPassword, "Server Key")
-
-	serverKey := HMAC(client.hashFunc, saltedPassword, []byte("Server Key"))
-	client.SetValue(ServerKeyID, serverKey)
-
 	// ServerSignature := HMAC(ServerKey, AuthMessage)
-	serverSignature := HMAC(client.hashFunc, serverKey, []byte(authMsg))
+	serverSignature := HMAC(client.hashFunc, client.serverKey, []byte(authMsg))
 	client.SetValue(ServerSignatureID, serverSignature)
 
 	if !bytes.Equal(serverSignature, receivedServerSignature) {
 		return ErrOtherError
 	}
 
+	maps.Copy(client.extensionAttrs, extensionAttributes(client.extensions, serverFinalMsg))
+
+	return receiveExtensions(client.extensions, client, ServerFinalMessage, serverFinalMsg)
+}
+
+// validateServerNonce validates the nonce sent by the server in the server first message.
+func (client *Client) validateServerNonce(clientRS string, serverRS string) error {
+	if !strings.HasPrefix(serverRS, clientRS) {
+		return fmt.Errorf("%w : %s", ErrInvalidNonce, serverRS)
+	}
+	if !client.validateNonce {
+		return nil
+	}
+	// nonce = printable
+	// printable = %x21-2B / %x2D-7E ;; Printable ASCII except ",".
+	if len(serverRS) == len(clientRS) {
+		return fmt.Errorf("%w : no server nonce", ErrInvalidNonce)
+	}
+	for _, c := range serverRS {
+		if c < 0x21 || 0x7E < c || c == ',' {
+			return fmt.Errorf("%w : %q", ErrInvalidNonce, serverRS)
+		}
+	}
 	return nil
 }
+
+// keys returns the client key and server key from the key cache, or derives them from the salted password.
+// A client without a password uses the keys seeded in the key cache for the user.
+func (client *Client) keys(salt []byte, ic int) ([]byte, []byte, error) {
+	if len(client.password) == 0 {
+		if client.keyCache != nil {
+			clientKey, serverKey, ok := client.keyCache.SeededKeys(client.hashFunc, client.username, salt, ic)
+			if ok {
+				return clientKey, serverKey, nil
+			}
+		}
+		return nil, nil, fmt.Errorf("%w : %s", ErrNoPassword, client.username)
+	}
+
+	if client.keyCache != nil {
+		clientKey, serverKey, ok := client.keyCache.Keys(client.hashFunc, client.password, salt, ic)
+		if ok {
+			return clientKey, serverKey, nil
+		}
+	}
+
+	// SaltedPassword := Hi(Normalize(password), salt, i)
+
+	saltedPassword, err := SaltedPassword(client.hashFunc, client.password, salt, ic)
+	if err != nil {
+		return nil, nil, err
+	}
+	client.SetValue(SaltedPasswordID, saltedPassword)
+
+	clientKey := ClientKey(client.hashFunc, saltedPassword)
+	serverKey := ServerKey(client.hashFunc, saltedPassword)
+
+	if client.keyCache != nil {
+		client.keyCache.SetKeys(client.hashFunc, client.password, salt, ic, clientKey, serverKey)
+	}
+
+	return clientKey, serverKey, nil
+}
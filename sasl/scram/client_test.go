@@ -0,0 +1,78 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scram
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClientServerParameters(t *testing.T) {
+	const clientRS = "fyko+d2lbbFgONRv9qkxdawL"
+	salt := base64.StdEncoding.EncodeToString([]byte("QSXCR+Q6sek8bf92"))
+
+	tests := []struct {
+		serverRS string
+		salt     string
+		ic       int
+		opts     []ClientOption
+		expected error
+	}{
+		{clientRS + "3rfcNHYJY1ZVvWVs7j", salt, 4096, nil, nil},
+		{clientRS + "%)$#!~", salt, 4096, nil, nil},
+		{clientRS + "3rfcNHYJY1ZVvWVs7j", salt, 1024, nil, ErrIterationCountTooLow},
+		{clientRS + "3rfcNHYJY1ZVvWVs7j", salt, 1024, []ClientOption{WithClientMinIterationCount(1)}, nil},
+		{clientRS + "3rfcNHYJY1ZVvWVs7j", salt, 0, []ClientOption{WithClientMinIterationCount(0)}, ErrIterationCountTooLow},
+		{clientRS + "3rfcNHYJY1ZVvWVs7j", salt, 20000, []ClientOption{WithClientMaxIterationCount(10000)}, ErrIterationCountTooHigh},
+		{clientRS + "3rfcNHYJY1ZVvWVs7j", base64.StdEncoding.EncodeToString([]byte("salt")), 4096, []ClientOption{WithClientMinSaltLength(16)}, ErrSaltTooShort},
+		{"3rfcNHYJY1ZVvWVs7j", salt, 4096, nil, ErrInvalidNonce},
+		{clientRS, salt, 4096, nil, ErrInvalidNonce},
+		{clientRS, salt, 4096, []ClientOption{WithClientNonceValidation(false)}, nil},
+		{clientRS + "3rfc NHYJ", salt, 4096, nil, ErrInvalidNonce},
+		{clientRS + "3rfcé", salt, 4096, nil, ErrInvalidNonce},
+	}
+
+	for n, test := range tests {
+		t.Run(fmt.Sprintf("%02d", n), func(t *testing.T) {
+			opts := append([]ClientOption{
+				WithClientUsername("user"),
+				WithClientPassword("pencil"),
+				WithClientHashFunc(HashSHA1()),
+				WithClientRandomSequence(clientRS),
+			}, test.opts...)
+			client, err := NewClient(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.FirstMessage(); err != nil {
+				t.Fatal(err)
+			}
+			serverFirstMsg := NewMessage(
+				WithAttribute(RandomSequenceAttr, test.serverRS),
+				WithAttribute(SaltAttr, test.salt),
+				WithAttribute(IterationCountAttr, fmt.Sprintf("%d", test.ic)),
+			)
+			_, err = client.FinalMessageFrom(serverFirstMsg)
+			switch {
+			case test.expected == nil && err != nil:
+				t.Errorf("FinalMessageFrom() = %v", err)
+			case test.expected != nil && !errors.Is(err, test.expected):
+				t.Errorf("FinalMessageFrom() = %v, want %v", err, test.expected)
+			}
+		})
+	}
+}
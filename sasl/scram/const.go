@@ -14,6 +14,10 @@
 
 package scram
 
+import (
+	"slices"
+)
+
 const (
 	AuthorizationIDAttr     = "a"
 	UserNameAttr            = "n"
@@ -27,6 +31,41 @@ const (
 	ErrorAttr               = "e"
 )
 
+var standardAttributes = []string{
+	AuthorizationIDAttr,
+	UserNameAttr,
+	FutureExtensibilityAttr,
+	RandomSequenceAttr,
+	ChannelBindingDataAttr,
+	SaltAttr,
+	IterationCountAttr,
+	ClientProofAttr,
+	ServerSignatureAttr,
+	ErrorAttr,
+}
+
+// isStandardAttribute returns true if the name is an attribute defined by RFC 5802.
+func isStandardAttribute(name string) bool {
+	return slices.Contains(standardAttributes, name)
+}
+
+// isAttributeName returns true if the name is a valid attribute name.
+// attr-val = ALPHA "=" value.
+func isAttributeName(name string) bool {
+	if len(name) != 1 {
+		return false
+	}
+	c := name[0]
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+const (
+	// MaxMessageSize is the maximum size in bytes of a SCRAM message.
+	MaxMessageSize = 8192
+	// MaxAttributeCount is the maximum number of attributes in a SCRAM message.
+	MaxAttributeCount = 32
+)
+
 const (
 	initialRandomSequenceLength    = 24
 	additionalRandomSequenceLength = 16
@@ -16,6 +16,7 @@ package scram
 
 import (
 	"errors"
+	"fmt"
 )
 
 // ErrInvalidEncoding is returned when the encoding is invalid.
@@ -51,6 +52,77 @@ var ErrNoResources = errors.New("no-resources")
 // ErrOtherError is returned when there is another error.
 var ErrOtherError = errors.New("other-error")
 
+// ErrNoPassword is returned when the client has no password and no keys are seeded for the user.
+var ErrNoPassword = errors.New("no password")
+
+// ErrInvalidIterationCount is returned when the iteration count is invalid.
+var ErrInvalidIterationCount = errors.New("invalid iteration count")
+
+// ErrIterationCountTooLow is returned when the iteration count sent by the server is less than the client minimum.
+var ErrIterationCountTooLow = errors.New("iteration count too low")
+
+// ErrIterationCountTooHigh is returned when the iteration count sent by the server is greater than the client maximum.
+var ErrIterationCountTooHigh = errors.New("iteration count too high")
+
+// ErrSaltTooShort is returned when the salt sent by the server is shorter than the client minimum.
+var ErrSaltTooShort = errors.New("salt too short")
+
+// ErrInvalidNonce is returned when the nonce sent by the server is invalid.
+var ErrInvalidNonce = errors.New("invalid nonce")
+
+// ErrInvalidExtension is returned when the extension name is not a valid extension attribute name.
+var ErrInvalidExtension = errors.New("invalid extension")
+
+// ErrTooManyAttributes is returned when a message has more attributes than the maximum.
+var ErrTooManyAttributes = errors.New("too many attributes")
+
+// ErrDuplicateAttribute is returned when a message has the same attribute more than once.
+var ErrDuplicateAttribute = errors.New("duplicate attribute")
+
+// ErrInvalidState is returned when the suspended state of an exchange is invalid.
+var ErrInvalidState = errors.New("invalid state")
+
+// ErrSecondFactorRequired is returned when the second factor is required but not presented.
+var ErrSecondFactorRequired = errors.New("second factor required")
+
+// ErrInvalidSecondFactor is returned when the second factor is invalid.
+var ErrInvalidSecondFactor = errors.New("invalid second factor")
+
+var standardErrors = []error{
+	ErrInvalidEncoding,
+	ErrExtensionsNotSupported,
+	ErrInvalidProof,
+	ErrChannelBindingsDontMatch,
+	ErrServerDoesSupportChannelBinding,
+	ErrChannelBindingNotSupported,
+	ErrUnsupportedChannelBindingType,
+	ErrUnknownUser,
+	ErrInvalidUsernameEncoding,
+	ErrNoResources,
+	ErrOtherError,
+}
+
+// StandardErrorOf returns the standard error which the specified error wraps, or ErrOtherError if the error is not a standard error.
+func StandardErrorOf(err error) error {
+	for _, stdErr := range standardErrors {
+		if errors.Is(err, stdErr) {
+			return stdErr
+		}
+	}
+	return ErrOtherError
+}
+
+// NewErrorFromValue returns the standard error for the specified server-error value.
+// If the value is not a standard error name, the returned error wraps ErrOtherError.
+func NewErrorFromValue(value string) error {
+	for _, stdErr := range standardErrors {
+		if stdErr.Error() == value {
+			return stdErr
+		}
+	}
+	return fmt.Errorf("%w : %s", ErrOtherError, value)
+}
+
 // IsStandardError returns true if the error is a standard error.
 func IsStandardError(err error) bool {
 	if errors.Is(err, ErrInvalidEncoding) {
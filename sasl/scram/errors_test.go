@@ -15,6 +15,8 @@
 package scram
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -39,3 +41,34 @@ func TestStandardErrors(t *testing.T) {
 		}
 	}
 }
+
+func TestServerErrorMessages(t *testing.T) {
+	for _, stdErr := range standardErrors {
+		t.Run(stdErr.Error(), func(t *testing.T) {
+			msg := NewMessageWithError(fmt.Errorf("%w : %s", stdErr, "detail"))
+			if msg.String() != "e="+stdErr.Error() {
+				t.Errorf("%s != e=%s", msg.String(), stdErr.Error())
+			}
+			parsedMsg, err := NewMessageFromString(msg.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := parsedMsg.ServerError(); !errors.Is(err, stdErr) {
+				t.Errorf("%v != %v", err, stdErr)
+			}
+		})
+	}
+
+	msg := NewMessageWithError(errors.New("internal failure"))
+	if msg.String() != "e="+ErrOtherError.Error() {
+		t.Errorf("%s != e=%s", msg.String(), ErrOtherError.Error())
+	}
+
+	msg, err := NewMessageFromString("e=unknown-extension-error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := msg.ServerError(); !errors.Is(err, ErrOtherError) {
+		t.Errorf("%v != %v", err, ErrOtherError)
+	}
+}
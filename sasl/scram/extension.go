@@ -0,0 +1,79 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scram
+
+import (
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+// MessageType represents a type of SCRAM messages.
+type MessageType int
+
+const (
+	// ClientFirstMessage represents the client-first-message.
+	ClientFirstMessage MessageType = iota
+	// ServerFirstMessage represents the server-first-message.
+	ServerFirstMessage
+	// ClientFinalMessage represents the client-final-message.
+	ClientFinalMessage
+	// ServerFinalMessage represents the server-final-message.
+	ServerFinalMessage
+)
+
+// ExtensionContext represents the exchange which is passed to extensions.
+type ExtensionContext interface {
+	// Store represents the values of the exchange.
+	mech.Store
+	// Username returns the authentication identity.
+	Username() string
+}
+
+// Extension represents a SCRAM extension which is carried by an extension attribute.
+type Extension interface {
+	// Name returns the attribute name of the extension, which is a letter not defined by RFC 5802.
+	Name() string
+	// Mandatory returns true if the peer must support the extension.
+	// If the registry negotiates extensions, the names of the mandatory extensions are sent in the "m" attribute,
+	// and the peer fails the exchange if it does not support them. Otherwise, Mandatory is not used.
+	Mandatory() bool
+	// Send returns the attribute value to send in the specified message, or false not to send the attribute.
+	Send(ctx ExtensionContext, msgType MessageType) (string, bool, error)
+	// Receive handles the attribute value received in the specified message. The ok is false if the attribute is not received.
+	Receive(ctx ExtensionContext, msgType MessageType, value string, ok bool) error
+}
+
+// ExtensionRegistry represents a registry of SCRAM extensions.
+type ExtensionRegistry interface {
+	// RegisterExtension registers the extension.
+	RegisterExtension(ext Extension) error
+	// Extension returns the extension with the specified name.
+	Extension(name string) (Extension, bool)
+	// Extensions returns all registered extensions in the registered order.
+	Extensions() []Extension
+	// Negotiation returns true if the registry negotiates the mandatory extensions with the "m" attribute.
+	Negotiation() bool
+}
+
+// ExtensionRegistryOption represents an option for an extension registry.
+type ExtensionRegistryOption func(*extensionRegistry)
+
+// WithExtensionNegotiation returns an option to negotiate the mandatory extensions with the "m" attribute.
+// RFC 5802 reserves the "m" attribute and requires the peer to fail the exchange if it is present,
+// so the negotiation works only between peers which enable it explicitly.
+func WithExtensionNegotiation() ExtensionRegistryOption {
+	return func(reg *extensionRegistry) {
+		reg.negotiation = true
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scram
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type extensionRegistry struct {
+	exts        []Extension
+	negotiation bool
+}
+
+// NewExtensionRegistry returns a new extension registry with options.
+// By default, the registry does not negotiate extensions, and the "m" attribute is handled as RFC 5802 requires.
+func NewExtensionRegistry(opts ...ExtensionRegistryOption) ExtensionRegistry {
+	reg := &extensionRegistry{
+		exts:        []Extension{},
+		negotiation: false,
+	}
+	for _, opt := range opts {
+		opt(reg)
+	}
+	return reg
+}
+
+// RegisterExtension registers the extension. If an extension with the same name is registered, it is replaced.
+func (reg *extensionRegistry) RegisterExtension(ext Extension) error {
+	name := ext.Name()
+	if !isAttributeName(name) || isStandardAttribute(name) {
+		return fmt.Errorf("%w : %s", ErrInvalidExtension, name)
+	}
+	idx := slices.IndexFunc(reg.exts, func(e Extension) bool { return e.Name() == name })
+	if 0 <= idx {
+		reg.exts[idx] = ext
+		return nil
+	}
+	reg.exts = append(reg.exts, ext)
+	return nil
+}
+
+// Extension returns the extension with the specified name.
+func (reg *extensionRegistry) Extension(name string) (Extension, bool) {
+	for _, ext := range reg.exts {
+		if ext.Name() == name {
+			return ext, true
+		}
+	}
+	return nil, false
+}
+
+// Extensions returns all registered extensions in the registered order.
+func (reg *extensionRegistry) Extensions() []Extension {
+	return slices.Clone(reg.exts)
+}
+
+// Negotiation returns true if the registry negotiates the mandatory extensions with the "m" attribute.
+func (reg *extensionRegistry) Negotiation() bool {
+	return reg.negotiation
+}
+
+// mandatoryExtensionNames returns the names of the mandatory extensions for the "m" attribute,
+// or an empty string if the registry does not negotiate extensions.
+func mandatoryExtensionNames(reg ExtensionRegistry) string {
+	if !reg.Negotiation() {
+		return ""
+	}
+	var names strings.Builder
+	for _, ext := range reg.Extensions() {
+		if ext.Mandatory() {
+			names.WriteString(ext.Name())
+		}
+	}
+	return names.String()
+}
+
+// checkMandatoryExtensions returns ErrExtensionsNotSupported if the message requires extensions which are not registered.
+// m: This attribute is reserved for future extensibility. In this version of SCRAM, its presence in a client or a server message
+// MUST cause authentication failure when the attribute is parsed by the other end (RFC 5802).
+func checkMandatoryExtensions(reg ExtensionRegistry, msg *Message) error {
+	if _, ok := msg.Attribute(FutureExtensibilityAttr); ok && !reg.Negotiation() {
+		return fmt.Errorf("%w : %s", ErrExtensionsNotSupported, FutureExtensibilityAttr)
+	}
+	for _, name := range msg.MandatoryExtensions() {
+		if _, ok := reg.Extension(name); !ok {
+			return fmt.Errorf("%w : %s", ErrExtensionsNotSupported, name)
+		}
+	}
+	return nil
+}
+
+// extensionAttributes returns the attributes of the registered extensions in the message.
+// The other attributes which are not defined by RFC 5802 are ignored.
+func extensionAttributes(reg ExtensionRegistry, msg *Message) map[string]string {
+	attrs := map[string]string{}
+	for name, value := range msg.ExtensionAttributes() {
+		if _, ok := reg.Extension(name); ok {
+			attrs[name] = value
+		}
+	}
+	return attrs
+}
+
+// sendExtensions sets the attributes of the registered extensions to the message.
+func sendExtensions(reg ExtensionRegistry, ctx ExtensionContext, msgType MessageType, msg *Message) error {
+	for _, ext := range reg.Extensions() {
+		value, ok, err := ext.Send(ctx, msgType)
+		if err != nil {
+			return err
+		}
+		if ok {
+			msg.SetAttribute(ext.Name(), value)
+		}
+	}
+	return nil
+}
+
+// receiveExtensions passes the attributes of the registered extensions in the message to the extensions.
+func receiveExtensions(reg ExtensionRegistry, ctx ExtensionContext, msgType MessageType, msg *Message) error {
+	for _, ext := range reg.Extensions() {
+		value, ok := msg.Attribute(ext.Name())
+		if err := ext.Receive(ctx, msgType, value, ok); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -16,7 +16,9 @@ package scram
 
 import (
 	"crypto/hmac"
+	"fmt"
 
+	"github.com/cybergarage/go-sasl/sasl/pkcs"
 	"github.com/cybergarage/go-sasl/sasl/prep"
 )
 
@@ -26,31 +28,40 @@ import (
 // Hi(str, salt, i) is defined as:.
 // 2.2. Notation.
 func Hi(h HashFunc, str string, salt []byte, i int) []byte {
-	if i <= 1 {
+	// An iteration count less than 1 returns an empty slice.
+	if i < 1 {
 		return []byte{}
 	}
-	// salt + INT(1)
-	// INT(g) is a 4-octet encoding of the integer g, most significant octet first.
-	saltInt1 := make([]byte, len(salt), len(salt)+4)
-	copy(saltInt1, salt)
-	saltInt1 = append(saltInt1, 0x00, 0x00, 0x00, 0x01)
+	mac := hmac.New(h, []byte(str))
 	// U1   := HMAC(str, salt + INT(1))
+	// INT(g) is a 4-octet encoding of the integer g, most significant octet first.
+	mac.Write(salt)
+	mac.Write([]byte{0x00, 0x00, 0x00, 0x01})
+	u := mac.Sum(nil)
+	hi := make([]byte, len(u))
+	copy(hi, u)
 	// U2   := HMAC(str, U1)
 	// ...
-	// Ui-1 := HMAC(str, Ui-2)
 	// Ui   := HMAC(str, Ui-1)
-	u := make([][]byte, i)
-	u[0] = HMAC(h, []byte(str), saltInt1)
-	for n := 1; n < i; n++ {
-		u[n] = HMAC(h, []byte(str), u[n-1])
-	}
 	// Hi := U1 XOR U2 XOR ... XOR Ui
-	hi := make([]byte, len(u[0]))
-	copy(hi, u[0])
+	// The iterations reuse a single HMAC and XOR each Ui into Hi in place.
 	for n := 1; n < i; n++ {
-		hi = XOR(hi, u[n])
+		mac.Reset()
+		mac.Write(u)
+		u = mac.Sum(u[:0])
+		for k := range hi {
+			hi[k] ^= u[k]
+		}
 	}
-	return hi[0:h().Size()]
+	return hi
+}
+
+// HiPBKDF2 computes Hi(str, salt, i) with PBKDF2 which is the same function as Hi with the output length of the hash function.
+func HiPBKDF2(h HashFunc, str string, salt []byte, i int) ([]byte, error) {
+	if i < 1 {
+		return nil, fmt.Errorf("%w : %d", ErrInvalidIterationCount, i)
+	}
+	return pkcs.PBKDF2(str, salt, i, h().Size(), h)
 }
 
 // HMAC(key, data) is defined as:.
@@ -84,6 +95,9 @@ func XOR(a, b []byte) []byte {
 
 // SaltedPassword  := Hi(Normalize(password), salt, i).
 func SaltedPassword(h HashFunc, password string, salt []byte, i int) ([]byte, error) {
+	if i < 1 {
+		return nil, fmt.Errorf("%w : %d", ErrInvalidIterationCount, i)
+	}
 	prepPassword, err := prep.Normalize(password)
 	if err != nil {
 		return nil, err
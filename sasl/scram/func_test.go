@@ -0,0 +1,127 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scram
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// legacyHi is the previous implementation of Hi which keeps all iterations and creates an HMAC for each iteration.
+func legacyHi(h HashFunc, str string, salt []byte, i int) []byte {
+	saltInt1 := make([]byte, len(salt), len(salt)+4)
+	copy(saltInt1, salt)
+	saltInt1 = append(saltInt1, 0x00, 0x00, 0x00, 0x01)
+	u := make([][]byte, i)
+	u[0] = HMAC(h, []byte(str), saltInt1)
+	for n := 1; n < i; n++ {
+		u[n] = HMAC(h, []byte(str), u[n-1])
+	}
+	hi := make([]byte, len(u[0]))
+	copy(hi, u[0])
+	for n := 1; n < i; n++ {
+		hi = XOR(hi, u[n])
+	}
+	return hi
+}
+
+func TestHi(t *testing.T) {
+	// RFC 6070 - PKCS #5: Password-Based Key Derivation Function 2 (PBKDF2) Test Vectors
+	tests := []struct {
+		password string
+		salt     string
+		i        int
+		expected string
+	}{
+		{"password", "salt", 1, "0c60c80f961f0e71f3a9b524af6012062fe037a6"},
+		{"password", "salt", 2, "ea6c014dc72d6f8ccd1ed92ace1d41f0d8de8957"},
+		{"password", "salt", 4096, "4b007901b765489abead49d926f721d065a429c1"},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%d", test.i), func(t *testing.T) {
+			hi := Hi(HashSHA1(), test.password, []byte(test.salt), test.i)
+			if hex.EncodeToString(hi) != test.expected {
+				t.Errorf("Hi() = %x, want %s", hi, test.expected)
+			}
+			pbkdf2Hi, err := HiPBKDF2(HashSHA1(), test.password, []byte(test.salt), test.i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(hi, pbkdf2Hi) {
+				t.Errorf("HiPBKDF2() = %x, want %x", pbkdf2Hi, hi)
+			}
+		})
+	}
+
+	for _, h := range []HashFunc{HashSHA1(), HashSHA256(), HashSHA512()} {
+		for _, i := range []int{2, 3, 4096} {
+			hi := Hi(h, "pencil", []byte("salt"), i)
+			if expected := legacyHi(h, "pencil", []byte("salt"), i); !bytes.Equal(hi, expected) {
+				t.Errorf("Hi(%d) = %x, want %x", i, hi, expected)
+			}
+		}
+	}
+
+	if _, err := SaltedPassword(HashSHA256(), "pencil", []byte("salt"), 0); !errors.Is(err, ErrInvalidIterationCount) {
+		t.Errorf("SaltedPassword(0) = %v, want %v", err, ErrInvalidIterationCount)
+	}
+
+	// RFC 5802 section 2.2: Normalize() treats the password as a query string which may include unassigned code points.
+	saltedPassword, err := SaltedPassword(HashSHA256(), "pass\U0001F600", []byte("salt"), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := Hi(HashSHA256(), "pass\U0001F600", []byte("salt"), 2); !bytes.Equal(saltedPassword, expected) {
+		t.Errorf("SaltedPassword() = %x, want %x", saltedPassword, expected)
+	}
+}
+
+func BenchmarkHi(b *testing.B) {
+	const i = 4096
+	salt := []byte("QSXCR+Q6sek8bf92")
+
+	hashFuncs := []struct {
+		name string
+		h    HashFunc
+	}{
+		{"SHA-1", HashSHA1()},
+		{"SHA-256", HashSHA256()},
+		{"SHA-512", HashSHA512()},
+	}
+
+	for _, hashFunc := range hashFuncs {
+		b.Run(hashFunc.name+"/legacy", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				legacyHi(hashFunc.h, "pencil", salt, i)
+			}
+		})
+		b.Run(hashFunc.name+"/streaming", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				Hi(hashFunc.h, "pencil", salt, i)
+			}
+		})
+		b.Run(hashFunc.name+"/pbkdf2", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				HiPBKDF2(hashFunc.h, "pencil", salt, i)
+			}
+		})
+	}
+}
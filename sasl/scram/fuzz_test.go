@@ -0,0 +1,119 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scram
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl/gss"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+func isMessageError(err error) bool {
+	for _, target := range []error{ErrOtherError, ErrTooManyAttributes, ErrDuplicateAttribute, mech.ErrMessageTooLarge, gss.ErrInvalidHeader} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func accessAttributes(msg *Message) {
+	msg.AuthorizationID()
+	msg.Username()
+	msg.FutureFutureExtensibility()
+	msg.MandatoryExtensions()
+	msg.ExtensionAttributes()
+	msg.RandomSequence()
+	msg.Salt()
+	msg.IterationCount()
+	msg.ClientProof()
+	msg.ChannelBindingData()
+	msg.ServerSignature()
+	msg.ServerError()
+	msg.StringWithoutProof()
+}
+
+func FuzzMessage(f *testing.F) {
+	seeds := []string{
+		"",
+		"r=fyko+d2lbbFgONRv9qkxdawL3rfcNHYJY1ZVvWVs7j,s=QSXCR+Q6sek8bf92,i=4096",
+		"c=biws,r=fyko+d2lbbFgONRv9qkxdawL3rfcNHYJY1ZVvWVs7j,p=v0X8v3Bz2T0CJGbJQyF0X+HI4Ts=",
+		"v=rmF9pqV8S7suAoZWja4dJRkFsKQ=",
+		"e=invalid-proof",
+		"m=t,t=totp",
+		"r=a,r=b",
+		"=,r",
+		strings.Repeat("a=b,", MaxAttributeCount+1),
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, str string) {
+		msg, err := NewMessageFromString(str)
+		if err != nil {
+			if !isMessageError(err) {
+				t.Fatalf("%q: untyped error %v", str, err)
+			}
+			return
+		}
+		accessAttributes(msg)
+		reMsg, err := NewMessageFromString(msg.String())
+		if err != nil {
+			t.Fatalf("%q: %v", msg.String(), err)
+		}
+		if !msg.Equals(reMsg) {
+			t.Fatalf("%q != %q", msg.String(), reMsg.String())
+		}
+	})
+}
+
+func FuzzMessageWithHeader(f *testing.F) {
+	seeds := []string{
+		"",
+		"n,,n=user,r=fyko+d2lbbFgONRv9qkxdawL",
+		"y,a=admin,n=user,r=fyko+d2lbbFgONRv9qkxdawL",
+		"p=tls-server-end-point,,n=user,r=fyko+d2lbbFgONRv9qkxdawL",
+		"F,n,,n=user,r=fyko+d2lbbFgONRv9qkxdawL",
+		"F",
+		"F,",
+		"F,n",
+		"p,,",
+		"p=,,",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, str string) {
+		msg, err := NewMessageFromStringWithHeader(str)
+		if err != nil {
+			if !isMessageError(err) {
+				t.Fatalf("%q: untyped error %v", str, err)
+			}
+			return
+		}
+		accessAttributes(msg)
+		channelBindingOf(msg)
+		reMsg, err := NewMessageFromStringWithHeader(msg.String())
+		if err != nil {
+			t.Fatalf("%q: %v", msg.String(), err)
+		}
+		if !msg.Equals(reMsg) {
+			t.Fatalf("%q != %q", msg.String(), reMsg.String())
+		}
+	})
+}
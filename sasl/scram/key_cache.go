@@ -0,0 +1,33 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scram
+
+// KeyCache represents a cache of the client keys and server keys derived from salted passwords.
+// A key cache is safe for concurrent use, and can be shared by clients of multiple connections to skip Hi() computations.
+type KeyCache interface {
+	// Keys returns the cached client key and server key for the hash function, password, salt and iteration count.
+	Keys(hashFunc HashFunc, password string, salt []byte, iterationCount int) ([]byte, []byte, bool)
+	// SetKeys caches the client key and server key for the hash function, password, salt and iteration count.
+	// The least recently used keys are evicted when the cache is full.
+	SetKeys(hashFunc HashFunc, password string, salt []byte, iterationCount int, clientKey []byte, serverKey []byte)
+	// SeededKeys returns the seeded client key and server key of the user for the hash function, salt and iteration count.
+	SeededKeys(hashFunc HashFunc, username string, salt []byte, iterationCount int) ([]byte, []byte, bool)
+	// SeedKeys seeds the client key and server key of the user for the hash function, salt and iteration count.
+	// Seeded keys are used by clients without a password, so that clients can authenticate with keys derived
+	// from a stored secret without holding the plaintext password. Seeded keys are never evicted.
+	SeedKeys(hashFunc HashFunc, username string, salt []byte, iterationCount int, clientKey []byte, serverKey []byte)
+	// Clear removes all cached and seeded keys.
+	Clear()
+}
@@ -0,0 +1,170 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scram
+
+import (
+	"container/list"
+	"crypto/rand"
+	"crypto/sha256"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// DefaultKeyCacheSize is the default maximum number of cached keys.
+const DefaultKeyCacheSize = 1024
+
+type keyCacheEntry struct {
+	key       string
+	clientKey []byte
+	serverKey []byte
+}
+
+type keyCache struct {
+	sync.Mutex
+	secret  []byte
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+	seeded  map[string]*keyCacheEntry
+}
+
+// KeyCacheOptionFn represents an option for a key cache.
+type KeyCacheOptionFn func(*keyCache)
+
+// NewKeyCache returns a new key cache with options.
+// The passwords are not stored in the cache, and are only used as keyed digests with a random secret of the cache.
+func NewKeyCache(opts ...KeyCacheOptionFn) KeyCache {
+	// crypto/rand.Read never returns an error.
+	secret := make([]byte, sha256.Size)
+	rand.Read(secret)
+	cache := &keyCache{
+		Mutex:   sync.Mutex{},
+		secret:  secret,
+		size:    DefaultKeyCacheSize,
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+		seeded:  map[string]*keyCacheEntry{},
+	}
+	for _, opt := range opts {
+		opt(cache)
+	}
+	return cache
+}
+
+// WithKeyCacheSize returns an option to set the maximum number of cached keys.
+// The least recently used keys are evicted when the cache is full. Seeded keys are not counted.
+func WithKeyCacheSize(size int) KeyCacheOptionFn {
+	return func(cache *keyCache) {
+		cache.size = size
+	}
+}
+
+// Keys returns the cached client key and server key for the hash function, password, salt and iteration count.
+func (cache *keyCache) Keys(hashFunc HashFunc, password string, salt []byte, iterationCount int) ([]byte, []byte, bool) {
+	key := cache.keyOf(hashFunc, password, salt, iterationCount)
+	cache.Lock()
+	defer cache.Unlock()
+	elem, ok := cache.entries[key]
+	if !ok {
+		return nil, nil, false
+	}
+	cache.order.MoveToFront(elem)
+	entry, _ := elem.Value.(*keyCacheEntry)
+	return slices.Clone(entry.clientKey), slices.Clone(entry.serverKey), true
+}
+
+// SetKeys caches the client key and server key for the hash function, password, salt and iteration count.
+func (cache *keyCache) SetKeys(hashFunc HashFunc, password string, salt []byte, iterationCount int, clientKey []byte, serverKey []byte) {
+	if cache.size <= 0 {
+		return
+	}
+	entry := &keyCacheEntry{
+		key:       cache.keyOf(hashFunc, password, salt, iterationCount),
+		clientKey: slices.Clone(clientKey),
+		serverKey: slices.Clone(serverKey),
+	}
+	cache.Lock()
+	defer cache.Unlock()
+	if elem, ok := cache.entries[entry.key]; ok {
+		elem.Value = entry
+		cache.order.MoveToFront(elem)
+		return
+	}
+	cache.entries[entry.key] = cache.order.PushFront(entry)
+	for cache.size < cache.order.Len() {
+		elem := cache.order.Back()
+		oldest, _ := elem.Value.(*keyCacheEntry)
+		delete(cache.entries, oldest.key)
+		cache.order.Remove(elem)
+	}
+}
+
+// SeededKeys returns the seeded client key and server key of the user for the hash function, salt and iteration count.
+func (cache *keyCache) SeededKeys(hashFunc HashFunc, username string, salt []byte, iterationCount int) ([]byte, []byte, bool) {
+	key := seedKeyOf(hashFunc, username, salt, iterationCount)
+	cache.Lock()
+	defer cache.Unlock()
+	entry, ok := cache.seeded[key]
+	if !ok {
+		return nil, nil, false
+	}
+	return slices.Clone(entry.clientKey), slices.Clone(entry.serverKey), true
+}
+
+// SeedKeys seeds the client key and server key of the user for the hash function, salt and iteration count.
+func (cache *keyCache) SeedKeys(hashFunc HashFunc, username string, salt []byte, iterationCount int, clientKey []byte, serverKey []byte) {
+	entry := &keyCacheEntry{
+		key:       seedKeyOf(hashFunc, username, salt, iterationCount),
+		clientKey: slices.Clone(clientKey),
+		serverKey: slices.Clone(serverKey),
+	}
+	cache.Lock()
+	defer cache.Unlock()
+	cache.seeded[entry.key] = entry
+}
+
+// Clear removes all cached and seeded keys.
+func (cache *keyCache) Clear() {
+	cache.Lock()
+	defer cache.Unlock()
+	cache.entries = map[string]*list.Element{}
+	cache.order.Init()
+	cache.seeded = map[string]*keyCacheEntry{}
+}
+
+// keyOf returns the cache key which consists of the hash function, the keyed password digest, the salt and the iteration count.
+func (cache *keyCache) keyOf(hashFunc HashFunc, password string, salt []byte, iterationCount int) string {
+	return parameterKeyOf(hashFunc, HMAC(sha256.New, cache.secret, []byte(password)), salt, iterationCount)
+}
+
+// seedKeyOf returns the seed key which consists of the hash function, the username, the salt and the iteration count.
+func seedKeyOf(hashFunc HashFunc, username string, salt []byte, iterationCount int) string {
+	return parameterKeyOf(hashFunc, []byte(strconv.Itoa(len(username))+":"+username), salt, iterationCount)
+}
+
+// parameterKeyOf returns the key of the identity and the Hi() parameters.
+// The hash function is identified by the digest of the empty input.
+func parameterKeyOf(hashFunc HashFunc, id []byte, salt []byte, iterationCount int) string {
+	hashID := H(hashFunc, nil)
+	key := make([]byte, 0, len(hashID)+len(id)+len(salt)+32)
+	key = append(key, hashID...)
+	key = append(key, id...)
+	key = strconv.AppendInt(key, int64(len(salt)), 10)
+	key = append(key, ':')
+	key = append(key, salt...)
+	key = strconv.AppendInt(key, int64(iterationCount), 10)
+	return string(key)
+}
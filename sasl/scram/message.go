@@ -43,10 +43,11 @@ func NewMessage(opts ...MessageOption) *Message {
 	return msg
 }
 
-// NewMessageWithError returns a new message from the specified error.
+// NewMessageWithError returns a new server-final-message with the server-error attribute for the specified error.
+// Errors other than the standard errors are sent as "other-error" not to disclose the details to the client.
 func NewMessageWithError(err error) *Message {
 	msg := NewMessage()
-	msg.SetError(err.Error())
+	msg.SetError(StandardErrorOf(err).Error())
 	return msg
 }
 
@@ -118,6 +119,9 @@ func (msg *Message) HasHeader() bool {
 
 // ParseStringWithHeader parses the specified string　with the GS2 header.
 func (msg *Message) ParseStringWithHeader(str string) error {
+	if MaxMessageSize < len(str) {
+		return fmt.Errorf("%w : %d bytes", mech.ErrMessageTooLarge, len(str))
+	}
 	return msg.ParseStringsWithHeader(strings.Split(str, ","))
 }
 
@@ -128,44 +132,63 @@ func (msg *Message) ParseStringsWithHeader(props []string) error {
 	if err != nil {
 		return err
 	}
-	scramProps := props[gss.GS2PropertyMaxCount:]
-	if !msg.Header.HasStdFlag() {
-		scramProps = props[(gss.GS2PropertyMaxCount - 1):]
+	headerCount := gss.GS2PropertyMaxCount - 1
+	if msg.Header.HasStdFlag() {
+		headerCount = gss.GS2PropertyMaxCount
 	}
-	return msg.ParseStrings(scramProps)
+	return msg.ParseStrings(props[headerCount:])
 }
 
 // ParseString parses the specified string.
 func (msg *Message) ParseString(str string) error {
+	if MaxMessageSize < len(str) {
+		return fmt.Errorf("%w : %d bytes", mech.ErrMessageTooLarge, len(str))
+	}
 	return msg.ParseStrings(strings.Split(str, ","))
 }
 
 // ParseStrings parses the specified property strings.
+// Extension attributes which are not defined by RFC 5802 are also parsed, and the extensions handle or ignore them.
 func (msg *Message) ParseStrings(props []string) error {
+	if MaxAttributeCount < len(props) {
+		return fmt.Errorf("%w : %d", ErrTooManyAttributes, len(props))
+	}
 	for _, scramProp := range props {
 		if len(scramProp) < 2 || scramProp[1] != '=' {
 			return ErrOtherError
 		}
 		attrName := scramProp[:1]
 		attrValue := scramProp[2:]
-		switch attrName {
-		case UserNameAttr,
-			FutureExtensibilityAttr,
-			RandomSequenceAttr,
-			ChannelBindingDataAttr,
-			SaltAttr,
-			IterationCountAttr,
-			ClientProofAttr,
-			ServerSignatureAttr,
-			ErrorAttr:
-			msg.SetAttribute(attrName, attrValue)
-		default:
+		if !isAttributeName(attrName) {
 			return ErrOtherError
 		}
+		if _, ok := msg.Attribute(attrName); ok {
+			return fmt.Errorf("%w : %s", ErrDuplicateAttribute, attrName)
+		}
+		msg.SetAttribute(attrName, attrValue)
 	}
 	return nil
 }
 
+// ServerError returns the error sent by the server as the server-error attribute, or nil if the message has no server-error attribute.
+func (msg *Message) ServerError() error {
+	value, ok := msg.Error()
+	if !ok {
+		return nil
+	}
+	return NewErrorFromValue(value)
+}
+
+func channelBindingOf(msg *Message) string {
+	if msg == nil || !msg.HasHeader() {
+		return ""
+	}
+	if msg.CBFlag() != gss.ClientSupportsUsedCBSFlag {
+		return ""
+	}
+	return msg.CBName()
+}
+
 // Equals returns true if the message equals the specified message.
 func (msg *Message) Equals(other *Message) bool {
 	if msg.HasHeader() != other.HasHeader() {
@@ -17,6 +17,7 @@ package scram
 import (
 	"bytes"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -184,3 +185,56 @@ func TestSCRAMMessage(t *testing.T) {
 		}
 	}
 }
+
+func TestSCRAMExtensionAttributes(t *testing.T) {
+	msg, err := NewMessageFromString("m=tx,n=user,r=fyko+d2lbbFgONRv9qkxdawL,t=123456,x=ext")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := msg.MandatoryExtensions(); strings.Join(names, ",") != "t,x" {
+		t.Errorf("mandatory extensions = %v", names)
+	}
+	attrs := msg.ExtensionAttributes()
+	if len(attrs) != 2 || attrs["t"] != "123456" || attrs["x"] != "ext" {
+		t.Errorf("extension attributes = %v", attrs)
+	}
+	if msg.String() != "m=tx,n=user,r=fyko+d2lbbFgONRv9qkxdawL,t=123456,x=ext" {
+		t.Errorf("%s", msg.String())
+	}
+
+	for _, str := range []string{"1=value", "r", "xy=value"} {
+		if _, err := NewMessageFromString(str); err == nil {
+			t.Errorf("%s is parsed", str)
+		}
+	}
+
+	reg := NewExtensionRegistry()
+	for _, name := range []string{"r", "xy", "1", ""} {
+		if err := reg.RegisterExtension(&testExtension{name: name}); !errors.Is(err, ErrInvalidExtension) {
+			t.Errorf("RegisterExtension(%q) = %v", name, err)
+		}
+	}
+	if err := reg.RegisterExtension(&testExtension{name: "t"}); err != nil {
+		t.Error(err)
+	}
+}
+
+type testExtension struct {
+	name string
+}
+
+func (ext *testExtension) Name() string {
+	return ext.name
+}
+
+func (ext *testExtension) Mandatory() bool {
+	return false
+}
+
+func (ext *testExtension) Send(ctx ExtensionContext, msgType MessageType) (string, bool, error) {
+	return "", false, nil
+}
+
+func (ext *testExtension) Receive(ctx ExtensionContext, msgType MessageType, value string, ok bool) error {
+	return nil
+}
@@ -0,0 +1,31 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scram
+
+// draft-ietf-kitten-scram-2fa - Extensions to Salted Challenge Response (SCRAM) for 2 factor authentication
+// https://datatracker.ietf.org/doc/draft-ietf-kitten-scram-2fa/
+
+// SecondFactorAttr is the attribute name of the second factor extension.
+// The server sends the type of the required second factor in the server-first-message,
+// and the client sends the second factor in the client-final-message, which is covered by the client proof.
+const SecondFactorAttr = "t"
+
+// SecondFactorVerifier represents a verifier of second factors such as one-time passwords.
+type SecondFactorVerifier interface {
+	// SecondFactor returns the type of the second factor and true if the user must present a second factor.
+	SecondFactor(username string) (string, bool, error)
+	// VerifySecondFactor verifies the second factor of the user.
+	VerifySecondFactor(username string, code string) error
+}
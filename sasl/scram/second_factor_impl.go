@@ -0,0 +1,126 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scram
+
+import (
+	"fmt"
+)
+
+type secondFactorServer struct {
+	verifier SecondFactorVerifier
+}
+
+// NewSecondFactorServerExtension returns a new server extension which requires the second factor for the users specified by the verifier.
+// The second factor is verified only after the client proof is verified.
+func NewSecondFactorServerExtension(verifier SecondFactorVerifier) Extension {
+	return &secondFactorServer{
+		verifier: verifier,
+	}
+}
+
+// Name returns the attribute name of the extension.
+func (ext *secondFactorServer) Name() string {
+	return SecondFactorAttr
+}
+
+// Mandatory returns false because the second factor is required only for the specific users.
+func (ext *secondFactorServer) Mandatory() bool {
+	return false
+}
+
+// Send returns the type of the second factor in the server-first-message if the user must present a second factor.
+// For unknown users of a server with a mock secret, the type is sent deterministically by the mock value
+// not to disclose which users exist or use a second factor.
+func (ext *secondFactorServer) Send(ctx ExtensionContext, msgType MessageType) (string, bool, error) {
+	if msgType != ServerFirstMessage {
+		return "", false, nil
+	}
+	factor, required, err := ext.verifier.SecondFactor(ctx.Username())
+	if err != nil {
+		return "", false, err
+	}
+	if v, ok := ctx.Value(MockValueID); ok {
+		mock, _ := v.([]byte)
+		required = 0 < len(factor) && 0 < len(mock) && mock[0]&1 == 1
+	}
+	if !required {
+		return "", false, nil
+	}
+	ctx.SetValue(SecondFactorID, factor)
+	return factor, true, nil
+}
+
+// Receive verifies the second factor in the client-final-message if the user must present a second factor.
+func (ext *secondFactorServer) Receive(ctx ExtensionContext, msgType MessageType, value string, ok bool) error {
+	if msgType != ClientFinalMessage {
+		return nil
+	}
+	// The verifier is queried again not to depend on the server-first-message step, which may run on another server.
+	_, required, err := ext.verifier.SecondFactor(ctx.Username())
+	if err != nil || !required {
+		return err
+	}
+	if !ok || len(value) == 0 {
+		return fmt.Errorf("%w : %s", ErrSecondFactorRequired, ctx.Username())
+	}
+	if err := ext.verifier.VerifySecondFactor(ctx.Username(), value); err != nil {
+		return fmt.Errorf("%w : %w", ErrInvalidSecondFactor, err)
+	}
+	return nil
+}
+
+type secondFactorClient struct {
+	code string
+}
+
+// NewSecondFactorClientExtension returns a new client extension which sends the second factor when the server requires it.
+func NewSecondFactorClientExtension(code string) Extension {
+	return &secondFactorClient{
+		code: code,
+	}
+}
+
+// Name returns the attribute name of the extension.
+func (ext *secondFactorClient) Name() string {
+	return SecondFactorAttr
+}
+
+// Mandatory returns false because the server requires the second factor only for the specific users.
+func (ext *secondFactorClient) Mandatory() bool {
+	return false
+}
+
+// Send returns the second factor in the client-final-message if the server requires it.
+func (ext *secondFactorClient) Send(ctx ExtensionContext, msgType MessageType) (string, bool, error) {
+	if msgType != ClientFinalMessage {
+		return "", false, nil
+	}
+	if _, required := ctx.Value(SecondFactorID); !required {
+		return "", false, nil
+	}
+	return ext.code, true, nil
+}
+
+// Receive records the type of the second factor required by the server in the server-first-message.
+func (ext *secondFactorClient) Receive(ctx ExtensionContext, msgType MessageType, value string, ok bool) error {
+	if msgType != ServerFirstMessage || !ok {
+		return nil
+	}
+	if len(ext.code) == 0 {
+		return fmt.Errorf("%w : %s", ErrSecondFactorRequired, value)
+	}
+	ctx.SetValue(SecondFactorID, value)
+	return nil
+}
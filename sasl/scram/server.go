@@ -16,10 +16,14 @@ package scram
 
 import (
 	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/base64"
+	"maps"
 
 	"github.com/cybergarage/go-sasl/sasl/auth"
+	"github.com/cybergarage/go-sasl/sasl/instrument"
 	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasl/util"
 	"github.com/cybergarage/go-sasl/sasl/util/rand"
 )
 
@@ -27,16 +31,26 @@ import (
 type Server struct {
 	mech.Store
 
-	credStore      auth.CredentialStore
-	mechanism      string
-	challenge      string
-	authzID        string
-	randomSequence string
-	iterationCount int
-	salt           []byte
-	hashFunc       HashFunc
-	clientFirstMsg *Message
-	serverFirstMsg *Message
+	credStore       auth.CredentialStore
+	authorizer      auth.Authorizer
+	limiter         auth.Limiter
+	conn            auth.Conn
+	mechanism       string
+	challenge       string
+	username        string
+	authzID         string
+	authorizedID    string
+	randomSequence  string
+	iterationCount  int
+	salt            []byte
+	mockSecret      []byte
+	unknownUser     bool
+	extensions      ExtensionRegistry
+	extensionAttrs  map[string]string
+	hashFunc        HashFunc
+	clientFirstMsg  *Message
+	serverFirstMsg  *Message
+	instrumentation instrument.Instrumentation
 }
 
 // ServerOption represents a server option.
@@ -45,17 +59,27 @@ type ServerOption func(*Server) error
 // NewServer returns a new SCRAM server.
 func NewServer(opts ...ServerOption) (*Server, error) {
 	srv := &Server{
-		Store:          mech.NewStore(),
-		credStore:      nil,
-		mechanism:      "",
-		hashFunc:       nil,
-		challenge:      "",
-		authzID:        "",
-		randomSequence: "",
-		iterationCount: defaultIterationCount,
-		salt:           nil,
-		clientFirstMsg: nil,
-		serverFirstMsg: nil,
+		Store:           mech.NewStore(),
+		credStore:       nil,
+		authorizer:      auth.NewDefaultAuthorizer(),
+		limiter:         nil,
+		conn:            nil,
+		mechanism:       "",
+		hashFunc:        nil,
+		challenge:       "",
+		username:        "",
+		authzID:         "",
+		authorizedID:    "",
+		randomSequence:  "",
+		iterationCount:  defaultIterationCount,
+		salt:            nil,
+		mockSecret:      nil,
+		unknownUser:     false,
+		extensions:      NewExtensionRegistry(),
+		extensionAttrs:  map[string]string{},
+		clientFirstMsg:  nil,
+		serverFirstMsg:  nil,
+		instrumentation: nil,
 	}
 	rs, err := rand.NewRandomSequence(additionalRandomSequenceLength)
 	if err != nil {
@@ -87,6 +111,23 @@ func WithServerRandomSequence(randomSequence string) ServerOption {
 	}
 }
 
+// WithServerUsername returns a server option to set the username authenticated by the application protocol such as the PostgreSQL startup message.
+// The username is used when the client sends an empty username as PostgreSQL clients do.
+func WithServerUsername(username string) ServerOption {
+	return func(server *Server) error {
+		server.username = username
+		return nil
+	}
+}
+
+// WithServerInstrumentation returns a server option to set the instrumentation which measures the salted password computation.
+func WithServerInstrumentation(i instrument.Instrumentation) ServerOption {
+	return func(server *Server) error {
+		server.instrumentation = i
+		return nil
+	}
+}
+
 // WithServeMechanism returns a server option to set the mechanism.
 func WithServeMechanism(mechanism string) ServerOption {
 	return func(server *Server) error {
@@ -115,6 +156,47 @@ func WithServerSaltString(salt string) ServerOption {
 	}
 }
 
+// WithServerMockSecret returns a server option to continue the exchange for unknown users not to disclose which users exist.
+// The salt of every user is derived from the secret and the username unless a salt is specified,
+// and unknown users fail at the proof verification as well as invalid passwords.
+func WithServerMockSecret(secret []byte) ServerOption {
+	return func(server *Server) error {
+		server.mockSecret = secret
+		return nil
+	}
+}
+
+// WithServerExtensions returns a server option to register the extensions.
+func WithServerExtensions(exts ...Extension) ServerOption {
+	return func(server *Server) error {
+		for _, ext := range exts {
+			if err := server.extensions.RegisterExtension(ext); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// WithServerSecondFactorVerifier returns a server option to require the second factor for the users specified by the verifier.
+func WithServerSecondFactorVerifier(verifier SecondFactorVerifier) ServerOption {
+	return WithServerExtensions(NewSecondFactorServerExtension(verifier))
+}
+
+// WithServerExtensionRegistry returns a server option to set the extension registry.
+// The extensions which are registered before are registered to the registry.
+func WithServerExtensionRegistry(reg ExtensionRegistry) ServerOption {
+	return func(server *Server) error {
+		for _, ext := range server.extensions.Extensions() {
+			if err := reg.RegisterExtension(ext); err != nil {
+				return err
+			}
+		}
+		server.extensions = reg
+		return nil
+	}
+}
+
 // WithServerCredentialStore returns a server option to set the credential store.
 func WithServerCredentialStore(store auth.CredentialStore) ServerOption {
 	return func(server *Server) error {
@@ -123,11 +205,65 @@ func WithServerCredentialStore(store auth.CredentialStore) ServerOption {
 	}
 }
 
+// WithServerAuthorizer returns a server option to set the authorizer.
+func WithServerAuthorizer(authz auth.Authorizer) ServerOption {
+	return func(server *Server) error {
+		server.authorizer = authz
+		return nil
+	}
+}
+
+// WithServerLimiter returns a server option to set the limiter for authentication attempts.
+func WithServerLimiter(limiter auth.Limiter) ServerOption {
+	return func(server *Server) error {
+		server.limiter = limiter
+		return nil
+	}
+}
+
+// WithServerConn returns a server option to set the client connection.
+func WithServerConn(conn auth.Conn) ServerOption {
+	return func(server *Server) error {
+		server.conn = conn
+		return nil
+	}
+}
+
 // HashFunc returns the hash function.
 func (server *Server) HashFunc() HashFunc {
 	return server.hashFunc
 }
 
+// Username returns the authentication identity sent by the client.
+func (server *Server) Username() string {
+	return server.username
+}
+
+// AuthzID returns the authorization identity sent by the client.
+func (server *Server) AuthzID() string {
+	return server.authzID
+}
+
+// ChannelBinding returns the channel binding type requested by the client, or an empty string if channel binding is not used.
+func (server *Server) ChannelBinding() string {
+	return channelBindingOf(server.clientFirstMsg)
+}
+
+// Extensions returns the extension registry.
+func (server *Server) Extensions() ExtensionRegistry {
+	return server.extensions
+}
+
+// ExtensionAttributes returns the extension attributes received from the client.
+func (server *Server) ExtensionAttributes() map[string]string {
+	return maps.Clone(server.extensionAttrs)
+}
+
+// AuthorizedID returns the identity associated with the connection after the exchange is completed successfully.
+func (server *Server) AuthorizedID() string {
+	return server.authorizedID
+}
+
 // SetOptions sets the specified options.
 func (server *Server) SetOptions(opts ...ServerOption) error {
 	for _, opt := range opts {
@@ -157,39 +293,71 @@ func (server *Server) FirstMessageFrom(clientMsg *Message) (*Message, error) {
 
 	// authzid: authorization ID
 	//  This is a server optional attribute, and is part of the GS2 [RFC5801] bridge between the GSS-API and SASL
-	authzID, ok := clientMsg.AuthorizationID()
-	if ok {
-		server.authzID = string(authzID)
-	} else {
-		// u: username
-		// If the "a" attribute is not specified (which would normally be the case),
-		// this username is also the identity that will be associated with the connection subsequent to
-		// authentication and authorization.
-		u, ok := clientMsg.Username()
-		if ok {
-			server.authzID = string(u)
-		}
+
+	if clientMsg.HasHeader() {
+		server.authzID = clientMsg.Header.AuthzID()
+	}
+	if authzID, ok := clientMsg.AuthorizationID(); ok {
+		server.authzID = util.DecodeName(authzID)
+	}
+
+	// n: username
+	// If the "a" attribute is not specified (which would normally be the case),
+	// this username is also the identity that will be associated with the connection subsequent to
+	// authentication and authorization.
+
+	username, ok := clientMsg.Username()
+	if ok && 0 < len(username) {
+		server.username = username
 	}
 
 	//  If the preparation of the username fails or results in an empty string,
 	// the client SHOULD abort the authentication exchange
 
-	if len(server.authzID) == 0 {
+	if len(server.username) == 0 {
 		return nil, ErrUnknownUser
 	}
-	server.SetValue(UsernameID, authzID)
+	server.SetValue(UsernameID, server.username)
+
+	if server.limiter != nil {
+		if err := server.limiter.Allow(server.conn, server.username); err != nil {
+			return nil, err
+		}
+	}
 
 	q, err := auth.NewQuery(
 		auth.WithQueryMechanism(server.mechanism),
-		auth.WithQueryUsername(server.authzID),
+		auth.WithQueryUsername(server.username),
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	// To prevent user enumeration, the server continues the exchange for unknown users
+	// with a mock salt and fails at the proof verification if the mock secret is specified.
+
 	_, ok, _ = server.LookupCredential(q)
 	if !ok {
-		return nil, ErrUnknownUser
+		if len(server.mockSecret) == 0 {
+			server.failed()
+			return nil, ErrUnknownUser
+		}
+		server.unknownUser = true
+		server.SetValue(MockValueID, server.mockValue("extension"))
+	}
+
+	// m: The server MUST fail the exchange if the client requires extensions which the server does not support.
+
+	if err := checkMandatoryExtensions(server.extensions, clientMsg); err != nil {
+		return nil, err
+	}
+	maps.Copy(server.extensionAttrs, extensionAttributes(server.extensions, clientMsg))
+	if err := receiveExtensions(server.extensions, server, ClientFirstMessage, clientMsg); err != nil {
+		return nil, err
+	}
+
+	if names := mandatoryExtensionNames(server.extensions); 0 < len(names) {
+		msg.SetFutureExtensibility(names)
 	}
 
 	// r: random sequence
@@ -205,11 +373,15 @@ func (server *Server) FirstMessageFrom(clientMsg *Message) (*Message, error) {
 	// s: salt
 
 	if len(server.salt) == 0 {
-		salt, err := rand.NewSalt(defaultSaltLength)
-		if err != nil {
-			return nil, err
+		if 0 < len(server.mockSecret) {
+			server.salt = server.mockValue("salt")[:defaultSaltLength]
+		} else {
+			salt, err := rand.NewSalt(defaultSaltLength)
+			if err != nil {
+				return nil, err
+			}
+			server.salt = salt
 		}
-		server.salt = salt
 	}
 	msg.SetSaltBytes(server.salt)
 	server.SetValue(SaltID, server.salt)
@@ -219,6 +391,12 @@ func (server *Server) FirstMessageFrom(clientMsg *Message) (*Message, error) {
 	msg.SetIterationCount(server.iterationCount)
 	server.SetValue(IterationCountID, server.iterationCount)
 
+	// extensions
+
+	if err := sendExtensions(server.extensions, server, ServerFirstMessage, msg); err != nil {
+		return nil, err
+	}
+
 	server.clientFirstMsg = clientMsg
 	server.serverFirstMsg = msg
 
@@ -254,31 +432,39 @@ func (server *Server) FinalMessageFrom(clientMsg *Message) (*Message, error) {
 
 	q, err := auth.NewQuery(
 		auth.WithQueryMechanism(server.mechanism),
-		auth.WithQueryUsername(server.authzID),
+		auth.WithQueryUsername(server.username),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	storedCred, ok, _ := server.LookupCredential(q)
-	if !ok {
-		return nil, ErrUnknownUser
-	}
-
 	var storedPassword string
-	switch passwd := storedCred.Password().(type) {
-	case string:
-		storedPassword = passwd
-	case []byte:
-		storedPassword = string(passwd)
+	storedCred, ok, _ := server.LookupCredential(q)
+	switch {
+	case ok && !server.unknownUser:
+		switch passwd := storedCred.Password().(type) {
+		case string:
+			storedPassword = passwd
+		case []byte:
+			storedPassword = string(passwd)
+		default:
+			return nil, ErrUnknownUser
+		}
+	case 0 < len(server.mockSecret):
+		// The unknown user is verified with a mock password to take the same time as known users.
+		server.unknownUser = true
+		storedPassword = base64.StdEncoding.EncodeToString(server.mockValue("password"))
 	default:
 		return nil, ErrUnknownUser
 	}
 
+	timer := instrument.Start(server.instrumentation, instrument.Hi, server.mechanism)
 	saltedPassword, err := SaltedPassword(server.hashFunc, storedPassword, server.salt, server.iterationCount)
 	if err != nil {
+		timer.Stop(instrument.Error)
 		return nil, err
 	}
+	timer.Stop(instrument.Success)
 	server.SetValue(SaltedPasswordID, saltedPassword)
 
 	// ClientKey := HMAC(SaltedPassword, "Client Key")
@@ -307,11 +493,13 @@ func (server *Server) FinalMessageFrom(clientMsg *Message) (*Message, error) {
 
 	clientProof, ok := clientMsg.ClientProof()
 	if !ok {
+		server.failed()
 		return nil, ErrInvalidProof
 	}
 
 	hashSize := server.hashFunc().Size()
 	if len(clientProof) != hashSize {
+		server.failed()
 		return nil, ErrInvalidProof
 	}
 
@@ -326,10 +514,33 @@ func (server *Server) FinalMessageFrom(clientMsg *Message) (*Message, error) {
 
 	receivedStoredKey := H(server.hashFunc, receivedClientKey)
 
-	if !hmac.Equal(storedKey, receivedStoredKey) {
-		return nil, ErrOtherError
+	if !hmac.Equal(storedKey, receivedStoredKey) || server.unknownUser {
+		server.failed()
+		return nil, ErrInvalidProof
 	}
 
+	// The extensions in the client final message are handled after the proof is verified.
+
+	maps.Copy(server.extensionAttrs, extensionAttributes(server.extensions, clientMsg))
+	if err := receiveExtensions(server.extensions, server, ClientFinalMessage, clientMsg); err != nil {
+		server.failed()
+		return nil, err
+	}
+
+	if server.limiter != nil {
+		server.limiter.Succeeded(server.conn, server.username)
+	}
+
+	// The authorization identity is checked after successful authentication.
+
+	if server.authorizer != nil {
+		err := server.authorizer.Authorize(server.conn, server.username, server.authzID, server.mechanism)
+		if err != nil {
+			return nil, err
+		}
+	}
+	server.authorizedID = auth.AuthorizedID(server.username, server.authzID)
+
 	// ServerKey := HMAC(SaltedPassword, "Server Key")
 	serverKey := HMAC(server.hashFunc, saltedPassword, []byte("Server Key"))
 	server.SetValue(ServerKeyID, serverKey)
@@ -341,5 +552,29 @@ func (server *Server) FinalMessageFrom(clientMsg *Message) (*Message, error) {
 	msg := NewMessage()
 	msg.SetServerSignature(serverSignature)
 
+	if err := sendExtensions(server.extensions, server, ServerFinalMessage, msg); err != nil {
+		return nil, err
+	}
+
 	return msg, nil
 }
+
+func (server *Server) mockValue(label string) []byte {
+	return HMAC(sha256.New, server.mockSecret, []byte(label+"\x00"+server.username))
+}
+
+// AllowResume returns nil if the limiter allows to resume an exchange from the connection.
+// The user is not known before the suspended state is opened, so only the connection is checked.
+func (server *Server) AllowResume() error {
+	if server.limiter == nil {
+		return nil
+	}
+	return server.limiter.Allow(server.conn, "")
+}
+
+func (server *Server) failed() {
+	if server.limiter == nil {
+		return
+	}
+	server.limiter.Failed(server.conn, server.username)
+}
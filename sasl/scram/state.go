@@ -0,0 +1,178 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scram
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+)
+
+// serverState represents the state of a server between the first and final messages.
+type serverState struct {
+	Username       string            `json:"username"`
+	AuthzID        string            `json:"authzid,omitempty"`
+	UnknownUser    bool              `json:"unknownUser,omitempty"`
+	IterationCount int               `json:"iterationCount"`
+	Salt           []byte            `json:"salt"`
+	ClientFirstMsg string            `json:"clientFirst"`
+	ServerFirstMsg string            `json:"serverFirst"`
+	ExtensionAttrs map[string]string `json:"extensions,omitempty"`
+}
+
+// clientState represents the state of a client between the messages.
+type clientState struct {
+	Username       string            `json:"username"`
+	AuthzID        string            `json:"authzid,omitempty"`
+	RandomSequence string            `json:"randomSequence"`
+	ServerKey      []byte            `json:"serverKey,omitempty"`
+	ClientFirstMsg string            `json:"clientFirst,omitempty"`
+	ServerFirstMsg string            `json:"serverFirst,omitempty"`
+	ClientFinalMsg string            `json:"clientFinal,omitempty"`
+	ExtensionAttrs map[string]string `json:"extensions,omitempty"`
+}
+
+// MarshalState returns the state of the exchange after the server first message to resume the exchange later.
+// The state includes the salt and messages, and must be encrypted before it is sent to untrusted parties.
+// The credential store and the other options are not included, and must be specified again when resuming.
+func (server *Server) MarshalState() ([]byte, error) {
+	if server.clientFirstMsg == nil || server.serverFirstMsg == nil {
+		return nil, ErrNoResources
+	}
+	return json.Marshal(&serverState{
+		Username:       server.username,
+		AuthzID:        server.authzID,
+		UnknownUser:    server.unknownUser,
+		IterationCount: server.iterationCount,
+		Salt:           server.salt,
+		ClientFirstMsg: server.clientFirstMsg.String(),
+		ServerFirstMsg: server.serverFirstMsg.String(),
+		ExtensionAttrs: server.extensionAttrs,
+	})
+}
+
+// UnmarshalState restores the state returned by MarshalState.
+func (server *Server) UnmarshalState(b []byte) error {
+	var state serverState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return fmt.Errorf("%w : %w", ErrInvalidState, err)
+	}
+	clientFirstMsg, err := NewMessageFromStringWithHeader(state.ClientFirstMsg)
+	if err != nil {
+		return fmt.Errorf("%w : %w", ErrInvalidState, err)
+	}
+	serverFirstMsg, err := NewMessageFromString(state.ServerFirstMsg)
+	if err != nil {
+		return fmt.Errorf("%w : %w", ErrInvalidState, err)
+	}
+	randomSequence, ok := serverFirstMsg.RandomSequence()
+	if !ok || len(state.Username) == 0 {
+		return ErrInvalidState
+	}
+
+	server.username = state.Username
+	server.authzID = state.AuthzID
+	server.unknownUser = state.UnknownUser
+	server.iterationCount = state.IterationCount
+	server.salt = state.Salt
+	server.clientFirstMsg = clientFirstMsg
+	server.serverFirstMsg = serverFirstMsg
+	maps.Copy(server.extensionAttrs, state.ExtensionAttrs)
+
+	server.SetValue(UsernameID, server.username)
+	server.SetValue(RandomSequenceID, randomSequence)
+	server.SetValue(SaltID, server.salt)
+	server.SetValue(IterationCountID, server.iterationCount)
+
+	return nil
+}
+
+// MarshalState returns the state of the exchange to resume the exchange later.
+// The state includes the server key after the client final message, and must be encrypted before it is stored.
+// The password and the other options are not included, and must be specified again when resuming.
+func (client *Client) MarshalState() ([]byte, error) {
+	state := &clientState{
+		Username:       client.username,
+		AuthzID:        client.authzID,
+		RandomSequence: client.randomSequence,
+		ServerKey:      client.serverKey,
+		ClientFirstMsg: "",
+		ServerFirstMsg: "",
+		ClientFinalMsg: "",
+		ExtensionAttrs: client.extensionAttrs,
+	}
+	if client.clientFirstMsg != nil {
+		state.ClientFirstMsg = client.clientFirstMsg.String()
+	}
+	if client.serverFirstMsg != nil {
+		state.ServerFirstMsg = client.serverFirstMsg.String()
+	}
+	if client.clientFinalMsg != nil {
+		state.ClientFinalMsg = client.clientFinalMsg.String()
+	}
+	return json.Marshal(state)
+}
+
+// UnmarshalState restores the state returned by MarshalState.
+func (client *Client) UnmarshalState(b []byte) error {
+	var state clientState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return fmt.Errorf("%w : %w", ErrInvalidState, err)
+	}
+
+	parse := func(str string, withHeader bool) (*Message, error) {
+		if len(str) == 0 {
+			return nil, nil
+		}
+		var msg *Message
+		var err error
+		if withHeader {
+			msg, err = NewMessageFromStringWithHeader(str)
+		} else {
+			msg, err = NewMessageFromString(str)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w : %w", ErrInvalidState, err)
+		}
+		return msg, nil
+	}
+
+	clientFirstMsg, err := parse(state.ClientFirstMsg, true)
+	if err != nil {
+		return err
+	}
+	serverFirstMsg, err := parse(state.ServerFirstMsg, false)
+	if err != nil {
+		return err
+	}
+	clientFinalMsg, err := parse(state.ClientFinalMsg, false)
+	if err != nil {
+		return err
+	}
+
+	client.username = state.Username
+	client.authzID = state.AuthzID
+	client.randomSequence = state.RandomSequence
+	client.serverKey = state.ServerKey
+	client.clientFirstMsg = clientFirstMsg
+	client.serverFirstMsg = serverFirstMsg
+	client.clientFinalMsg = clientFinalMsg
+	maps.Copy(client.extensionAttrs, state.ExtensionAttrs)
+
+	client.SetValue(UsernameID, client.username)
+	client.SetValue(RandomSequenceID, client.randomSequence)
+
+	return nil
+}
@@ -28,4 +28,7 @@ const (
 	SaltID            = "salt"
 	IterationCountID  = "iterationCount"
 	ClientProofID     = "clientProof"
+	SecondFactorID    = "secondFactor"
+	// MockValueID is the ID of the deterministic mock value of an unknown user, which is set only if the server has a mock secret.
+	MockValueID = "mockValue"
 )
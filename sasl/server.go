@@ -15,13 +15,19 @@
 package sasl
 
 import (
+	"github.com/cybergarage/go-sasl/sasl/audit"
 	"github.com/cybergarage/go-sasl/sasl/auth"
+	"github.com/cybergarage/go-sasl/sasl/instrument"
 )
 
 // Server represents a SASL server.
 type Server interface {
 	// Version returns the version.
 	Version() string
+	// AddMechanism adds a mechanism to the server.
+	AddMechanism(mech Mechanism)
+	// AddMechanisms adds mechanisms to the server.
+	AddMechanisms(mech ...Mechanism)
 	// Mechanisms returns the mechanisms.
 	Mechanisms() []Mechanism
 	// Mechanism returns a mechanism by name.
@@ -34,4 +40,15 @@ type Server interface {
 	CredentialStore() auth.CredentialStore
 	// VerifyCredential verifies the client credential.
 	VerifyCredential(conn auth.Conn, q auth.Query) (bool, error)
+	// SetAuthorizer sets the authorizer.
+	SetAuthorizer(authz auth.Authorizer)
+	// Authorize returns nil if the authenticated identity is allowed to act as the authorization identity.
+	Authorize(conn auth.Conn, authcid string, authzid string, mech string) error
+	// SetLimiter sets the limiter for authentication attempts.
+	SetLimiter(limiter auth.Limiter)
+	// SetObserver sets the observer which receives the audit events of the contexts of the mechanisms returned after it is set.
+	SetObserver(observer audit.Observer)
+	// SetInstrumentation sets the instrumentation which measures the exchanges of the mechanisms returned after it is set,
+	// and the credential lookups, verifications, authorizations and SCRAM salted password computations.
+	SetInstrumentation(i instrument.Instrumentation)
 }
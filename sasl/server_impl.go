@@ -15,7 +15,9 @@
 package sasl
 
 import (
+	"github.com/cybergarage/go-sasl/sasl/audit"
 	"github.com/cybergarage/go-sasl/sasl/auth"
+	"github.com/cybergarage/go-sasl/sasl/instrument"
 	"github.com/cybergarage/go-sasl/sasl/mech"
 	"github.com/cybergarage/go-sasl/sasl/mech/plugins/anonymous"
 	"github.com/cybergarage/go-sasl/sasl/mech/plugins/plain"
@@ -26,6 +28,7 @@ import (
 type server struct {
 	Provider
 	auth.Manager
+	observer audit.Observer
 }
 
 // NewServer returns a new SASL server instance.
@@ -33,6 +36,7 @@ func NewServer() Server {
 	server := &server{
 		Provider: NewProvider(),
 		Manager:  auth.NewManager(),
+		observer: nil,
 	}
 	server.loadDefaultPlugins()
 	return server
@@ -46,11 +50,10 @@ func (server *server) Version() string {
 // Mechanisms returns the mechanisms.
 func (server *server) Mechanisms() []Mechanism {
 	ms := server.Provider.Mechanisms()
-	opts := []mech.Option{
-		server.CredentialStore(),
-	}
-	for _, m := range ms {
+	opts := server.mechanismOptions()
+	for n, m := range ms {
 		m.SetOptions(opts...)
+		ms[n] = server.observed(m)
 	}
 	return ms
 }
@@ -62,12 +65,43 @@ func (server *server) Mechanism(name string) (Mechanism, error) {
 		return nil, err
 	}
 
-	opts := []mech.Option{
-		server.CredentialStore(),
+	m.SetOptions(server.mechanismOptions()...)
+
+	return server.observed(m), nil
+}
+
+// SetObserver sets the observer which receives the audit events of the contexts of the mechanisms returned after it is set.
+func (server *server) SetObserver(observer audit.Observer) {
+	server.observer = observer
+}
+
+// SetInstrumentation sets the instrumentation which measures the exchanges of the mechanisms returned after it is set,
+// and the credential lookups, verifications, authorizations and SCRAM salted password computations.
+func (server *server) SetInstrumentation(i instrument.Instrumentation) {
+	server.Manager.SetInstrumentation(i)
+}
+
+// observed returns the mechanism which reports the audit events to the observer and the instrumentation if either is set.
+func (server *server) observed(m Mechanism) Mechanism {
+	i := server.Instrumentation()
+	switch {
+	case server.observer == nil && i == nil:
+		return m
+	case i == nil:
+		return audit.NewMechanism(m, server.observer)
+	case server.observer == nil:
+		return audit.NewMechanism(m, audit.NewInstrumentationObserver(i))
 	}
-	m.SetOptions(opts...)
+	return audit.NewMechanism(m, audit.NewMultiObserver(server.observer, audit.NewInstrumentationObserver(i)))
+}
 
-	return m, nil
+func (server *server) mechanismOptions() []mech.Option {
+	opts := []mech.Option{}
+	if credStore := server.CredentialStore(); credStore != nil {
+		opts = append(opts, credStore)
+	}
+	opts = append(opts, server.Manager)
+	return opts
 }
 
 func (server *server) loadDefaultPlugins() {
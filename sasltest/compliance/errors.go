@@ -0,0 +1,34 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"errors"
+)
+
+// ErrPanic is returned when a context panics.
+var ErrPanic = errors.New("panic")
+
+// ErrStep is returned when a context does not increment the step number by one after a successful Next.
+var ErrStep = errors.New("invalid step number")
+
+// ErrInitialState is returned when a started context is not at the initial step.
+var ErrInitialState = errors.New("invalid initial state")
+
+// ErrTooManySteps is returned when an exchange does not complete within the maximum number of steps.
+var ErrTooManySteps = errors.New("too many steps")
+
+// ErrNotCompleted is returned when a context is not completed after the exchange.
+var ErrNotCompleted = errors.New("exchange not completed")
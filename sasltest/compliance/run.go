@@ -0,0 +1,369 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+// transcript represents the messages of a completed exchange.
+type transcript struct {
+	clientMsgs [][]byte
+	serverMsgs [][]byte
+}
+
+// Run runs the compliance tests of the mechanism pair as subtests.
+func (suite *Suite) Run(t *testing.T) {
+	t.Helper()
+	t.Run("exchange", suite.testExchange)
+	t.Run("after-done", suite.testAfterDone)
+	t.Run("no-parameters", suite.testNoParameters)
+	t.Run("negative", suite.testNegative)
+	t.Run("replay", suite.testReplay)
+	t.Run("out-of-order", suite.testOutOfOrder)
+	t.Run("dispose", suite.testDispose)
+}
+
+// next calls Next of the context, and verifies that the step number is incremented by one if it succeeds.
+// A panic of the context is returned as ErrPanic.
+func next(ctx mech.Context, params ...mech.Parameter) (res []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			res = nil
+			err = fmt.Errorf("%w : %v", ErrPanic, r)
+		}
+	}()
+	step := ctx.Step()
+	r, err := ctx.Next(params...)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Step() != step+1 {
+		return nil, fmt.Errorf("%w : %d after step %d", ErrStep, ctx.Step(), step)
+	}
+	if r == nil {
+		return nil, nil
+	}
+	return r.Bytes(), nil
+}
+
+// start starts a context of the mechanism, and verifies the initial state.
+func start(m mech.Mechanism, opts ...mech.Option) (mech.Context, error) {
+	ctx, err := m.Start(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Step() != 0 || ctx.Done() {
+		return nil, fmt.Errorf("%w : step %d, done %t", ErrInitialState, ctx.Step(), ctx.Done())
+	}
+	return ctx, nil
+}
+
+// exchange runs an exchange between new client and server contexts, and returns the contexts and the transcript.
+func (suite *Suite) exchange(clientOpts ...mech.Option) (mech.Context, mech.Context, *transcript, error) {
+	tr := &transcript{
+		clientMsgs: [][]byte{},
+		serverMsgs: [][]byte{},
+	}
+
+	clientCtx, err := start(suite.client, clientOpts...)
+	if err != nil {
+		return nil, nil, tr, err
+	}
+	serverCtx, err := start(suite.server, suite.serverOpts...)
+	if err != nil {
+		return clientCtx, nil, tr, err
+	}
+
+	params := []mech.Parameter{}
+	for n := 0; ; n++ {
+		if suite.maxSteps <= n {
+			return clientCtx, serverCtx, tr, fmt.Errorf("%w : %d", ErrTooManySteps, n)
+		}
+		clientRes, err := next(clientCtx, params...)
+		if err != nil {
+			return clientCtx, serverCtx, tr, err
+		}
+		if serverCtx.Done() {
+			break
+		}
+		tr.clientMsgs = append(tr.clientMsgs, clientRes)
+		serverRes, err := next(serverCtx, clientRes)
+		if err != nil {
+			return clientCtx, serverCtx, tr, err
+		}
+		tr.serverMsgs = append(tr.serverMsgs, serverRes)
+		if clientCtx.Done() {
+			break
+		}
+		params = []mech.Parameter{serverRes}
+	}
+
+	if !clientCtx.Done() {
+		return clientCtx, serverCtx, tr, fmt.Errorf("%w : client", ErrNotCompleted)
+	}
+	if !serverCtx.Done() {
+		return clientCtx, serverCtx, tr, fmt.Errorf("%w : server", ErrNotCompleted)
+	}
+
+	return clientCtx, serverCtx, tr, nil
+}
+
+// expectError reports an error if the error is nil, a panic, or not a mechanism error which protocol adapters can map.
+func expectError(t *testing.T, name string, err error) {
+	t.Helper()
+	if err == nil {
+		t.Errorf("%s: no error", name)
+		return
+	}
+	var mechErr *mech.Error
+	if !errors.As(err, &mechErr) {
+		t.Errorf("%s: untyped error %v (%T)", name, err, err)
+	}
+}
+
+func dispose(t *testing.T, ctxs ...mech.Context) {
+	t.Helper()
+	for _, ctx := range ctxs {
+		if ctx == nil {
+			continue
+		}
+		if err := ctx.Dispose(); err != nil {
+			t.Errorf("%T.Dispose() = %v", ctx, err)
+		}
+	}
+}
+
+func (suite *Suite) testExchange(t *testing.T) {
+	clientCtx, serverCtx, _, err := suite.exchange(suite.clientOpts...)
+	defer dispose(t, clientCtx, serverCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ctx := range []mech.Context{clientCtx, serverCtx} {
+		if ctx.Mechanism() == nil || ctx.Mechanism().Name() != suite.Name() {
+			t.Errorf("%T.Mechanism() is not %s", ctx, suite.Name())
+		}
+		resultCtx, ok := ctx.(mech.AuthResultContext)
+		if !ok {
+			continue
+		}
+		result, ok := resultCtx.AuthResult()
+		if !ok {
+			t.Errorf("%T has no authentication result", ctx)
+			continue
+		}
+		if result.Mechanism() != suite.Name() {
+			t.Errorf("%T.AuthResult().Mechanism() = %s, want %s", ctx, result.Mechanism(), suite.Name())
+		}
+	}
+
+	if authzCtx, ok := serverCtx.(mech.AuthorizedContext); ok {
+		if len(authzCtx.AuthorizedID()) == 0 {
+			t.Errorf("%T.AuthorizedID() is empty", serverCtx)
+		}
+	}
+}
+
+func (suite *Suite) testAfterDone(t *testing.T) {
+	clientCtx, serverCtx, tr, err := suite.exchange(suite.clientOpts...)
+	defer dispose(t, clientCtx, serverCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peerMsgs := map[mech.Context][][]byte{
+		clientCtx: tr.serverMsgs,
+		serverCtx: tr.clientMsgs,
+	}
+	for _, ctx := range []mech.Context{clientCtx, serverCtx} {
+		params := []mech.Parameter{}
+		if msgs := peerMsgs[ctx]; 0 < len(msgs) {
+			params = append(params, msgs[0])
+		}
+		step := ctx.Step()
+		_, err := next(ctx, params...)
+		expectError(t, fmt.Sprintf("%T.Next() after completion", ctx), err)
+		if ctx.Step() != step {
+			t.Errorf("%T.Step() = %d after completion, want %d", ctx, ctx.Step(), step)
+		}
+		if !ctx.Done() {
+			t.Errorf("%T.Done() is false after completion", ctx)
+		}
+	}
+}
+
+func (suite *Suite) testNoParameters(t *testing.T) {
+	serverCtx, err := start(suite.server, suite.serverOpts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dispose(t, serverCtx)
+
+	_, err = next(serverCtx)
+	expectError(t, "server Next() without parameters", err)
+	if serverCtx.Done() {
+		t.Errorf("server context is completed without parameters")
+	}
+
+	clientCtx, err := start(suite.client, suite.clientOpts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dispose(t, clientCtx)
+
+	// The client sends the initial response without parameters.
+
+	if _, err := next(clientCtx); err != nil {
+		t.Fatal(err)
+	}
+	if clientCtx.Done() {
+		return
+	}
+	_, err = next(clientCtx)
+	expectError(t, "client Next() without a server message", err)
+	if clientCtx.Done() {
+		t.Errorf("client context is completed without a server message")
+	}
+}
+
+func (suite *Suite) testNegative(t *testing.T) {
+	if suite.invalidClientOpts == nil {
+		t.Skip("no invalid client options")
+	}
+
+	clientCtx, serverCtx, _, err := suite.exchange(suite.invalidClientOpts...)
+	defer dispose(t, clientCtx, serverCtx)
+	expectError(t, "exchange with invalid client options", err)
+
+	if serverCtx == nil {
+		return
+	}
+	if serverCtx.Done() {
+		t.Errorf("server context is completed with invalid client options")
+	}
+	if resultCtx, ok := serverCtx.(mech.AuthResultContext); ok {
+		if _, ok := resultCtx.AuthResult(); ok {
+			t.Errorf("server context has an authentication result with invalid client options")
+		}
+	}
+}
+
+func (suite *Suite) testReplay(t *testing.T) {
+	if suite.replayable {
+		t.Skip("replays are detected by the underlying security service")
+	}
+
+	clientCtx, serverCtx, tr, err := suite.exchange(suite.clientOpts...)
+	dispose(t, clientCtx, serverCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tr.clientMsgs) < 2 {
+		t.Skip("no server challenge")
+	}
+
+	// The server challenge of a new exchange differs, and the replayed client messages must be rejected.
+
+	replayCtx, err := start(suite.server, suite.serverOpts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dispose(t, replayCtx)
+
+	for n, msg := range tr.clientMsgs {
+		_, err := next(replayCtx, msg)
+		if err != nil {
+			expectError(t, fmt.Sprintf("replayed message %d", n), err)
+			return
+		}
+	}
+	t.Errorf("replayed exchange is accepted")
+}
+
+func (suite *Suite) testOutOfOrder(t *testing.T) {
+	clientCtx, serverCtx, tr, err := suite.exchange(suite.clientOpts...)
+	dispose(t, clientCtx, serverCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tr.clientMsgs) < 2 {
+		t.Skip("single message exchange")
+	}
+
+	// The server must reject a client message of a later step as the first message.
+
+	ctx, err := start(suite.server, suite.serverOpts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = next(ctx, tr.clientMsgs[len(tr.clientMsgs)-1])
+	expectError(t, "server Next() with the last client message first", err)
+	dispose(t, ctx)
+
+	// The server must reject the first client message twice.
+
+	ctx, err = start(suite.server, suite.serverOpts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := next(ctx, tr.clientMsgs[0]); err != nil {
+		t.Fatal(err)
+	}
+	_, err = next(ctx, tr.clientMsgs[0])
+	expectError(t, "server Next() with the first client message twice", err)
+	dispose(t, ctx)
+
+	// The client must reject the last server message as the first server message.
+
+	ctx, err = start(suite.client, suite.clientOpts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := next(ctx); err != nil {
+		t.Fatal(err)
+	}
+	_, err = next(ctx, tr.serverMsgs[len(tr.serverMsgs)-1])
+	expectError(t, "client Next() with the last server message first", err)
+	dispose(t, ctx)
+}
+
+func (suite *Suite) testDispose(t *testing.T) {
+	clientCtx, serverCtx, _, err := suite.exchange(suite.clientOpts...)
+	if err != nil {
+		dispose(t, clientCtx, serverCtx)
+		t.Fatal(err)
+	}
+	dispose(t, clientCtx, serverCtx)
+	dispose(t, clientCtx, serverCtx)
+
+	// The contexts are disposable before the exchange is completed.
+
+	clientCtx, err = start(suite.client, suite.clientOpts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverCtx, err = start(suite.server, suite.serverOpts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dispose(t, clientCtx, serverCtx)
+	dispose(t, clientCtx, serverCtx)
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compliance
+
+import (
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+// DefaultMaxSteps is the default maximum number of steps of an exchange.
+const DefaultMaxSteps = 32
+
+// Suite represents a compliance test suite of a client and server mechanism pair.
+type Suite struct {
+	client            mech.Mechanism
+	server            mech.Mechanism
+	clientOpts        []mech.Option
+	serverOpts        []mech.Option
+	invalidClientOpts []mech.Option
+	replayable        bool
+	maxSteps          int
+}
+
+// SuiteOption represents a suite option.
+type SuiteOption func(*Suite)
+
+// NewSuite returns a new compliance test suite of the client and server mechanisms.
+func NewSuite(client mech.Mechanism, server mech.Mechanism, opts ...SuiteOption) *Suite {
+	suite := &Suite{
+		client:            client,
+		server:            server,
+		clientOpts:        []mech.Option{},
+		serverOpts:        []mech.Option{},
+		invalidClientOpts: nil,
+		replayable:        false,
+		maxSteps:          DefaultMaxSteps,
+	}
+	for _, opt := range opts {
+		opt(suite)
+	}
+	return suite
+}
+
+// WithClientOptions returns a suite option to set the client options such as the credentials.
+func WithClientOptions(opts ...mech.Option) SuiteOption {
+	return func(suite *Suite) {
+		suite.clientOpts = opts
+	}
+}
+
+// WithServerOptions returns a suite option to set the server options.
+func WithServerOptions(opts ...mech.Option) SuiteOption {
+	return func(suite *Suite) {
+		suite.serverOpts = opts
+	}
+}
+
+// WithInvalidClientOptions returns a suite option to set the client options such as a wrong password which the exchange must reject.
+// The negative test is skipped unless the options are specified.
+func WithInvalidClientOptions(opts ...mech.Option) SuiteOption {
+	return func(suite *Suite) {
+		suite.invalidClientOpts = opts
+	}
+}
+
+// WithReplayable returns a suite option to skip the replay test for mechanisms which rely on the underlying security service
+// such as a Kerberos replay cache to detect replayed exchanges.
+func WithReplayable() SuiteOption {
+	return func(suite *Suite) {
+		suite.replayable = true
+	}
+}
+
+// WithMaxSteps returns a suite option to set the maximum number of steps of an exchange.
+func WithMaxSteps(n int) SuiteOption {
+	return func(suite *Suite) {
+		suite.maxSteps = n
+	}
+}
+
+// Name returns the mechanism name.
+func (suite *Suite) Name() string {
+	return suite.client.Name()
+}
@@ -0,0 +1,31 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"errors"
+)
+
+// ErrMismatch is returned when a message differs from the recorded one.
+var ErrMismatch = errors.New("message mismatch")
+
+// ErrUnexpectedMessage is returned when a context returns a message which is not recorded.
+var ErrUnexpectedMessage = errors.New("unexpected message")
+
+// ErrNotCompleted is returned when a context is not completed after the recorded exchange.
+var ErrNotCompleted = errors.New("exchange not completed")
+
+// ErrAuthorizedID is returned when the authorized identity differs from the expected one.
+var ErrAuthorizedID = errors.New("authorized identity mismatch")
@@ -0,0 +1,86 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+// Party represents the sender of a message.
+type Party int
+
+const (
+	// Client represents the client.
+	Client Party = iota
+	// Server represents the server.
+	Server
+)
+
+// String returns the party name.
+func (party Party) String() string {
+	switch party {
+	case Client:
+		return "client"
+	case Server:
+		return "server"
+	}
+	return "unknown"
+}
+
+// Message represents a message of a recorded exchange.
+type Message struct {
+	From Party
+	Data []byte
+}
+
+// C returns a new client message.
+func C(data string) Message {
+	return Message{From: Client, Data: []byte(data)}
+}
+
+// S returns a new server message.
+func S(data string) Message {
+	return Message{From: Server, Data: []byte(data)}
+}
+
+// Vector represents a recorded exchange which is replayed against client and server contexts.
+// The client and server options fix the random values such as nonces and salts so that the contexts reproduce the messages.
+type Vector struct {
+	// Name is the vector name.
+	Name string
+	// Source is the specification or client which the vector is taken from.
+	Source string
+	// Mechanism is the mechanism name.
+	Mechanism string
+	// AuthzID is the authorization identity sent by the client.
+	AuthzID string
+	// Username is the authentication identity registered in the server credential store.
+	Username string
+	// Password is the password registered in the server credential store.
+	Password string
+	// AuthorizedID is the identity expected to be authorized, or empty if the vector does not check it.
+	AuthorizedID string
+	// ClientOptions are the client options appended after the credential options.
+	ClientOptions []mech.Option
+	// ServerOptions are the server options appended after the auth manager option.
+	ServerOptions []mech.Option
+	// Messages are the recorded messages in order.
+	Messages []Message
+}
+
+// String returns the vector name.
+func (v *Vector) String() string {
+	return v.Mechanism + "/" + v.Name
+}
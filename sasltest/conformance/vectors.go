@@ -0,0 +1,222 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"encoding/base64"
+
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+// RFC5802 returns the SCRAM-SHA-1 example exchange of RFC 5802 Section 5.
+func RFC5802() *Vector {
+	return &Vector{
+		Name:         "rfc5802",
+		Source:       "RFC 5802 Section 5",
+		Mechanism:    "SCRAM-SHA-1",
+		Username:     "user",
+		Password:     "pencil",
+		AuthorizedID: "user",
+		ClientOptions: []mech.Option{
+			mech.RandomSequence("fyko+d2lbbFgONRv9qkxdawL"),
+		},
+		ServerOptions: []mech.Option{
+			mech.RandomSequence("3rfcNHYJY1ZVvWVs7j"),
+			mech.Salt("QSXCR+Q6sek8bf92"),
+			mech.IterationCount(4096),
+		},
+		Messages: []Message{
+			C("n,,n=user,r=fyko+d2lbbFgONRv9qkxdawL"),
+			S("r=fyko+d2lbbFgONRv9qkxdawL3rfcNHYJY1ZVvWVs7j,s=QSXCR+Q6sek8bf92,i=4096"),
+			C("c=biws,r=fyko+d2lbbFgONRv9qkxdawL3rfcNHYJY1ZVvWVs7j,p=v0X8v3Bz2T0CJGbJQyF0X+HI4Ts="),
+			S("v=rmF9pqV8S7suAoZWja4dJRkFsKQ="),
+		},
+	}
+}
+
+// RFC7677 returns the SCRAM-SHA-256 example exchange of RFC 7677 Section 3.
+func RFC7677() *Vector {
+	return &Vector{
+		Name:         "rfc7677",
+		Source:       "RFC 7677 Section 3",
+		Mechanism:    "SCRAM-SHA-256",
+		Username:     "user",
+		Password:     "pencil",
+		AuthorizedID: "user",
+		ClientOptions: []mech.Option{
+			mech.RandomSequence("rOprNGfwEbeRWgbNEkqO"),
+		},
+		ServerOptions: []mech.Option{
+			mech.RandomSequence("%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0"),
+			mech.Salt("W22ZaJ0SNY7soEsUEjb6gQ=="),
+			mech.IterationCount(4096),
+		},
+		Messages: []Message{
+			C("n,,n=user,r=rOprNGfwEbeRWgbNEkqO"),
+			S("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"),
+			C("c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ="),
+			S("v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4="),
+		},
+	}
+}
+
+// RFC4616 returns the PLAIN example exchanges of RFC 4616 Section 4.
+func RFC4616() []*Vector {
+	return []*Vector{
+		{
+			Name:          "rfc4616-tim",
+			Source:        "RFC 4616 Section 4",
+			Mechanism:     "PLAIN",
+			Username:      "tim",
+			Password:      "tanstaaftanstaaf",
+			AuthorizedID:  "tim",
+			ClientOptions: []mech.Option{},
+			ServerOptions: []mech.Option{},
+			Messages: []Message{
+				C("\x00tim\x00tanstaaftanstaaf"),
+			},
+		},
+		{
+			Name:          "rfc4616-kurt",
+			Source:        "RFC 4616 Section 4",
+			Mechanism:     "PLAIN",
+			AuthzID:       "Ursel",
+			Username:      "Kurt",
+			Password:      "xipj3plmq",
+			AuthorizedID:  "Ursel",
+			ClientOptions: []mech.Option{},
+			ServerOptions: []mech.Option{},
+			Messages: []Message{
+				C("Ursel\x00Kurt\x00xipj3plmq"),
+			},
+		},
+	}
+}
+
+// RFC4505 returns the ANONYMOUS example exchange of RFC 4505 Section 4.
+func RFC4505() *Vector {
+	return &Vector{
+		Name:         "rfc4505",
+		Source:       "RFC 4505 Section 4",
+		Mechanism:    "ANONYMOUS",
+		AuthorizedID: "anonymous",
+		ClientOptions: []mech.Option{
+			mech.Token("sirhc"),
+		},
+		ServerOptions: []mech.Option{},
+		Messages: []Message{
+			C("sirhc"),
+		},
+	}
+}
+
+// SyntheticSHA256 returns a synthetic SCRAM-SHA-256 exchange generated by this library, not captured from another implementation.
+// The client sends an empty username as PostgreSQL clients do, and the server authenticates the username set by the application protocol.
+func SyntheticSHA256() *Vector {
+	return &Vector{
+		Name:         "synthetic-sha256",
+		Source:       "synthetic (generated by go-sasl)",
+		Mechanism:    "SCRAM-SHA-256",
+		Username:     "user",
+		Password:     "pencil",
+		AuthorizedID: "user",
+		ClientOptions: []mech.Option{
+			mech.Username(""),
+			mech.RandomSequence("9IZ2O01zb9IgiIZ1WJ/zgpJB"),
+		},
+		ServerOptions: []mech.Option{
+			mech.Username("user"),
+			mech.RandomSequence("jBFBhLtVTr+KqGDPxKoIbCXr"),
+			mech.Salt("v8l6ghNXvGXUkmnZjCfeUw=="),
+			mech.IterationCount(4096),
+		},
+		Messages: []Message{
+			C("n,,n=,r=9IZ2O01zb9IgiIZ1WJ/zgpJB"),
+			S("r=9IZ2O01zb9IgiIZ1WJ/zgpJBjBFBhLtVTr+KqGDPxKoIbCXr,s=v8l6ghNXvGXUkmnZjCfeUw==,i=4096"),
+			C("c=biws,r=9IZ2O01zb9IgiIZ1WJ/zgpJBjBFBhLtVTr+KqGDPxKoIbCXr,p=iMoiZJaZtBw+eflu1s6Wz7F40nfn/7N0xxhE7BgwEDc="),
+			S("v=99Fg2iF54INGQzwcqRFuQlGlnTeh6Q4TNL9C3c4MJb0="),
+		},
+	}
+}
+
+// MongoDB returns the SCRAM-SHA-1 example exchange of the MongoDB authentication specification.
+// MongoDB clients use the hex encoded MD5 digest of "<username>:mongo:<password>" as the SCRAM password.
+func MongoDB() *Vector {
+	return &Vector{
+		Name:         "mongodb",
+		Source:       "MongoDB authentication specification",
+		Mechanism:    "SCRAM-SHA-1",
+		Username:     "user",
+		Password:     "1c33006ec1ffd90f9cadcbcc0e118200",
+		AuthorizedID: "user",
+		ClientOptions: []mech.Option{
+			mech.RandomSequence("fyko+d2lbbFgONRv9qkxdawL"),
+		},
+		ServerOptions: []mech.Option{
+			mech.RandomSequence("Ho+Vgk7qvUOKUwuWLIWg4l/9SraGMHEE"),
+			mech.Salt("rQ9ZY3MntBeuP3E1TDVC4w=="),
+			mech.IterationCount(10000),
+		},
+		Messages: []Message{
+			C("n,,n=user,r=fyko+d2lbbFgONRv9qkxdawL"),
+			S("r=fyko+d2lbbFgONRv9qkxdawLHo+Vgk7qvUOKUwuWLIWg4l/9SraGMHEE,s=rQ9ZY3MntBeuP3E1TDVC4w==,i=10000"),
+			C("c=biws,r=fyko+d2lbbFgONRv9qkxdawLHo+Vgk7qvUOKUwuWLIWg4l/9SraGMHEE,p=MC2T8BvbmWRckDw8oWl5IVghwCY="),
+			S("v=UMWeI25JD1yNYZRMpZ4VHvhZ9e0="),
+		},
+	}
+}
+
+// SyntheticSHA512 returns a synthetic SCRAM-SHA-512 exchange generated by this library, not captured from another implementation.
+// The nonces and the salt are base-36 strings as Kafka clients generate them.
+func SyntheticSHA512() *Vector {
+	return &Vector{
+		Name:         "synthetic-sha512",
+		Source:       "synthetic (generated by go-sasl)",
+		Mechanism:    "SCRAM-SHA-512",
+		Username:     "user",
+		Password:     "pencil",
+		AuthorizedID: "user",
+		ClientOptions: []mech.Option{
+			mech.RandomSequence("1rnf6ptoyptz3zwlc5tfjd5pbr"),
+		},
+		ServerOptions: []mech.Option{
+			mech.RandomSequence("4ix4f2jtdpkyqdsmqwd7r6x7p"),
+			mech.Salt(base64.StdEncoding.EncodeToString([]byte("2xf4kzy5nfm3nt6t0vz0vknjbp"))),
+			mech.IterationCount(4096),
+		},
+		Messages: []Message{
+			C("n,,n=user,r=1rnf6ptoyptz3zwlc5tfjd5pbr"),
+			S("r=1rnf6ptoyptz3zwlc5tfjd5pbr4ix4f2jtdpkyqdsmqwd7r6x7p,s=MnhmNGt6eTVuZm0zbnQ2dDB2ejB2a25qYnA=,i=4096"),
+			C("c=biws,r=1rnf6ptoyptz3zwlc5tfjd5pbr4ix4f2jtdpkyqdsmqwd7r6x7p,p=QkTT9RNd3cFR0Ql3spBGNVO8gB9s+xh0IN6yYokHX4mOKkv+PZ/emEyJs6rGFivcC3B2v3YNLAnEcWsuOQXveA=="),
+			S("v=6CGGDt86LcHUa95CbSUnXXsrM94TNKw2/rjOHEtoph58DTFUaFepDioyehYFlqtWSImlxAgOoufnyu0pn6INcg=="),
+		},
+	}
+}
+
+// Vectors returns all the built-in vectors.
+func Vectors() []*Vector {
+	vectors := []*Vector{
+		RFC5802(),
+		RFC7677(),
+	}
+	vectors = append(vectors, RFC4616()...)
+	vectors = append(vectors,
+		RFC4505(),
+		MongoDB(),
+		SyntheticSHA256(),
+		SyntheticSHA512(),
+	)
+	return vectors
+}
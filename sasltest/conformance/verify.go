@@ -0,0 +1,161 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/cybergarage/go-sasl/sasl/auth"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+// VerifyClient replays the vector against a client context of the mechanism,
+// and returns an error if the context does not reproduce the recorded client messages.
+func VerifyClient(m mech.Mechanism, v *Vector) error {
+	opts := []mech.Option{}
+	if 0 < len(v.AuthzID) {
+		opts = append(opts, mech.AuthzID(v.AuthzID))
+	}
+	if 0 < len(v.Username) {
+		opts = append(opts, mech.Username(v.Username))
+	}
+	if 0 < len(v.Password) {
+		opts = append(opts, mech.Password(v.Password))
+	}
+	opts = append(opts, v.ClientOptions...)
+
+	ctx, err := m.Start(opts...)
+	if err != nil {
+		return err
+	}
+	defer ctx.Dispose()
+
+	params := []mech.Parameter{}
+	for n, msg := range v.Messages {
+		if msg.From == Server {
+			params = []mech.Parameter{msg.Data}
+			continue
+		}
+		res, err := ctx.Next(params...)
+		if err != nil {
+			return fmt.Errorf("message %d : %w", n, err)
+		}
+		if err := compare(n, msg, res); err != nil {
+			return err
+		}
+		params = []mech.Parameter{}
+	}
+
+	// The client verifies the last server message without responding.
+
+	if 0 < len(params) {
+		res, err := ctx.Next(params...)
+		if err != nil {
+			return fmt.Errorf("message %d : %w", len(v.Messages), err)
+		}
+		if res != nil && 0 < len(res.Bytes()) {
+			return fmt.Errorf("%w : %s %q", ErrUnexpectedMessage, Client, res.Bytes())
+		}
+	}
+
+	if !ctx.Done() {
+		return fmt.Errorf("%w : %s", ErrNotCompleted, Client)
+	}
+
+	return nil
+}
+
+// VerifyServer replays the vector against a server context of the mechanism,
+// and returns an error if the context does not reproduce the recorded server messages.
+// The server context is started with an auth manager which has the vector credential,
+// and allows the vector username to act as the vector authorization identity.
+func VerifyServer(m mech.Mechanism, v *Vector) error {
+	mgr := auth.NewManager()
+	mgr.SetCredentialStore(v)
+	mgr.SetAuthorizer(auth.NewRuleAuthorizer(
+		func(_ auth.Conn, authcid string, authzid string, _ string) bool {
+			return authcid == v.Username && authzid == v.AuthzID
+		},
+	))
+
+	opts := []mech.Option{mgr}
+	opts = append(opts, v.ServerOptions...)
+
+	ctx, err := m.Start(opts...)
+	if err != nil {
+		return err
+	}
+	defer ctx.Dispose()
+
+	for n := 0; n < len(v.Messages); n++ {
+		msg := v.Messages[n]
+		if msg.From == Server {
+			return fmt.Errorf("%w : message %d is not a server response", ErrUnexpectedMessage, n)
+		}
+		res, err := ctx.Next(msg.Data)
+		if err != nil {
+			return fmt.Errorf("message %d : %w", n, err)
+		}
+		if n+1 < len(v.Messages) && v.Messages[n+1].From == Server {
+			n++
+			if err := compare(n, v.Messages[n], res); err != nil {
+				return err
+			}
+			continue
+		}
+		if res != nil && 0 < len(res.Bytes()) {
+			return fmt.Errorf("%w : %s %q", ErrUnexpectedMessage, Server, res.Bytes())
+		}
+	}
+
+	if !ctx.Done() {
+		return fmt.Errorf("%w : %s", ErrNotCompleted, Server)
+	}
+
+	if 0 < len(v.AuthorizedID) {
+		authzCtx, ok := ctx.(mech.AuthorizedContext)
+		if !ok {
+			return fmt.Errorf("%w : %T is not an authorized context", ErrAuthorizedID, ctx)
+		}
+		if authzCtx.AuthorizedID() != v.AuthorizedID {
+			return fmt.Errorf("%w : %s != %s", ErrAuthorizedID, authzCtx.AuthorizedID(), v.AuthorizedID)
+		}
+	}
+
+	return nil
+}
+
+// LookupCredential returns the vector credential if the query username matches the vector username.
+func (v *Vector) LookupCredential(q auth.Query) (auth.Credential, bool, error) {
+	if q.Username() != v.Username {
+		return nil, false, nil
+	}
+	return auth.NewCredential(
+		auth.WithCredentialUsername(v.Username),
+		auth.WithCredentialPassword(v.Password),
+	), true, nil
+}
+
+func compare(n int, msg Message, res mech.Response) error {
+	var data []byte
+	if res != nil {
+		data = res.Bytes()
+	}
+	if !bytes.Equal(data, msg.Data) {
+		return fmt.Errorf("%w : message %d from %s %q != %q", ErrMismatch, n, msg.From, data, msg.Data)
+	}
+	return nil
+}
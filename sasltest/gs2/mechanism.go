@@ -0,0 +1,203 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gs2
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/cybergarage/go-sasl/sasl/auth"
+	"github.com/cybergarage/go-sasl/sasl/gss"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+// MechanismName is the name of the test GSS-API mechanism.
+const MechanismName = "TEST"
+
+var (
+	// ErrInvalidToken is returned when the token is invalid.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrInvalidProof is returned when the password or the channel bindings of the peers do not match.
+	ErrInvalidProof = errors.New("invalid proof")
+)
+
+var (
+	wrapPrefix = []byte("wrapped:")
+	sealPrefix = []byte("sealed:")
+)
+
+// Mechanism represents a test GSS-API mechanism which authenticates both peers with a shared password
+// bound to the channel bindings in two context tokens.
+type Mechanism struct{}
+
+// NewMechanism returns a new test GSS-API mechanism.
+func NewMechanism() *Mechanism {
+	return &Mechanism{}
+}
+
+// Name returns the mechanism name.
+func (m *Mechanism) Name() string {
+	return MechanismName
+}
+
+// NewInitiator returns a new initiator context with the mech.Username and mech.Password options.
+func (m *Mechanism) NewInitiator(opts ...any) (gss.InitiatorContext, error) {
+	ctx := &initiator{
+		secContext: secContext{
+			established: false,
+			password:    "",
+		},
+		username: "",
+	}
+	for _, opt := range opts {
+		switch v := opt.(type) {
+		case mech.Username:
+			ctx.username = string(v)
+		case mech.Password:
+			ctx.password = string(v)
+		}
+	}
+	return ctx, nil
+}
+
+// NewAcceptor returns a new acceptor context with the auth.CredentialStore option.
+func (m *Mechanism) NewAcceptor(opts ...any) (gss.AcceptorContext, error) {
+	ctx := &acceptor{
+		secContext: secContext{
+			established: false,
+			password:    "",
+		},
+		credStore:  nil,
+		sourceName: "",
+	}
+	for _, opt := range opts {
+		if v, ok := opt.(auth.CredentialStore); ok {
+			ctx.credStore = v
+		}
+	}
+	if ctx.credStore == nil {
+		return nil, auth.ErrNoCredential
+	}
+	return ctx, nil
+}
+
+func mac(password string, label string, cb []byte) []byte {
+	h := hmac.New(sha256.New, []byte(password))
+	h.Write([]byte(label))
+	h.Write(cb)
+	return h.Sum(nil)
+}
+
+type secContext struct {
+	established bool
+	password    string
+}
+
+// Wrap returns the message with a prefix, and XORs the message with the MAC of the password if confidential is true.
+func (ctx *secContext) Wrap(msg []byte, confidential bool) ([]byte, error) {
+	if !ctx.established {
+		return nil, gss.ErrContextNotEstablished
+	}
+	if confidential {
+		return append(append([]byte{}, sealPrefix...), ctx.seal(msg)...), nil
+	}
+	return append(append([]byte{}, wrapPrefix...), msg...), nil
+}
+
+func (ctx *secContext) Unwrap(token []byte) ([]byte, bool, error) {
+	if !ctx.established {
+		return nil, false, gss.ErrContextNotEstablished
+	}
+	switch {
+	case bytes.HasPrefix(token, wrapPrefix):
+		return token[len(wrapPrefix):], false, nil
+	case bytes.HasPrefix(token, sealPrefix):
+		return ctx.seal(token[len(sealPrefix):]), true, nil
+	}
+	return nil, false, ErrInvalidToken
+}
+
+func (ctx *secContext) seal(msg []byte) []byte {
+	key := mac(ctx.password, "seal", nil)
+	sealed := make([]byte, len(msg))
+	for n := range msg {
+		sealed[n] = msg[n] ^ key[n%len(key)]
+	}
+	return sealed
+}
+
+func (ctx *secContext) Delete() error {
+	ctx.established = false
+	return nil
+}
+
+type initiator struct {
+	secContext
+	username string
+}
+
+func (ctx *initiator) InitSecContext(input []byte, cb []byte) ([]byte, bool, error) {
+	if input == nil {
+		token := append([]byte(ctx.username+"\x00"), mac(ctx.password, "c", cb)...)
+		return token, false, nil
+	}
+	if !hmac.Equal(input, mac(ctx.password, "s", cb)) {
+		return nil, false, ErrInvalidToken
+	}
+	ctx.established = true
+	return nil, true, nil
+}
+
+type acceptor struct {
+	secContext
+	credStore  auth.CredentialStore
+	sourceName string
+}
+
+func (ctx *acceptor) AcceptSecContext(input []byte, cb []byte) ([]byte, bool, error) {
+	username, proof, ok := bytes.Cut(input, []byte{0x00})
+	if !ok {
+		return nil, false, ErrInvalidToken
+	}
+	q, err := auth.NewQuery(auth.WithQueryUsername(string(username)))
+	if err != nil {
+		return nil, false, err
+	}
+	cred, ok, err := ctx.credStore.LookupCredential(q)
+	if !ok {
+		if err == nil {
+			err = auth.ErrNoCredential
+		}
+		return nil, false, err
+	}
+	password, ok := cred.Password().(string)
+	if !ok {
+		return nil, false, fmt.Errorf("%w : %s", auth.ErrInvalidCredential, username)
+	}
+	if !hmac.Equal(proof, mac(password, "c", cb)) {
+		return nil, false, ErrInvalidProof
+	}
+	ctx.password = password
+	ctx.sourceName = string(username)
+	ctx.established = true
+	return mac(password, "s", cb), true, nil
+}
+
+func (ctx *acceptor) SourceName() string {
+	return ctx.sourceName
+}
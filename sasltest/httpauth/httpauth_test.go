@@ -0,0 +1,256 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpauth
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/httpauth"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasltest"
+)
+
+// echoHandler responds with the authenticated identity and the request body.
+func echoHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result, ok := httpauth.AuthResultFrom(r.Context())
+		if !ok {
+			http.Error(w, "no authentication result", http.StatusInternalServerError)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		io.WriteString(w, result.AuthcID()+":"+result.Mechanism()+":"+string(body))
+	})
+}
+
+// roundRobin dispatches requests to the handlers in turn to simulate horizontally scaled servers.
+func roundRobin(handlers ...http.Handler) http.Handler {
+	var n atomic.Int64
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlers[int(n.Add(1))%len(handlers)].ServeHTTP(w, r)
+	})
+}
+
+// tamperTransport modifies the Authentication-Info header of successful responses.
+type tamperTransport struct{}
+
+func (tamperTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := http.DefaultTransport.RoundTrip(req)
+	if err == nil && res.StatusCode == http.StatusOK {
+		params, _ := httpauth.ParseParams(res.Header.Get("Authentication-Info"))
+		params.SetToken(httpauth.S2CParam, []byte("v=AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="))
+		res.Header.Set("Authentication-Info", params.String())
+	}
+	return res, err
+}
+
+// recordTransport records the Authorization header of the last request.
+type recordTransport struct {
+	base          http.RoundTripper
+	authorization string
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.authorization = req.Header.Get("Authorization")
+	return t.base.RoundTrip(req)
+}
+
+func TestHTTPAuthentication(t *testing.T) {
+	sealer, err := sasl.NewTokenSealer(bytes.Repeat([]byte{0x01}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	handlers := make([]http.Handler, 2)
+	for n := range handlers {
+		handlers[n], err = httpauth.NewHandler(sasltest.NewServer(), echoHandler(),
+			httpauth.WithHandlerRealm("example.com"),
+			httpauth.WithHandlerMechanisms("SCRAM-SHA-256", "SCRAM-SHA-1", "PLAIN"),
+			httpauth.WithHandlerTokenSealer(sealer),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	ts := httptest.NewServer(roundRobin(handlers...))
+	defer ts.Close()
+
+	newClient := func(base http.RoundTripper, mechName string, password string) *http.Client {
+		return &http.Client{
+			Transport: httpauth.NewTransport(sasl.NewClient(),
+				httpauth.WithTransportBase(base),
+				httpauth.WithTransportMechanisms(mechName),
+				httpauth.WithTransportOptions(mech.Username(sasltest.Username), mech.Password(password)),
+			),
+		}
+	}
+
+	t.Run("challenge", func(t *testing.T) {
+		res, err := http.Get(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Errorf("status = %d", res.StatusCode)
+		}
+		params, ok := httpauth.ParseParams(res.Header.Get("WWW-Authenticate"))
+		if !ok {
+			t.Fatalf("challenge = %q", res.Header.Get("WWW-Authenticate"))
+		}
+		if params[httpauth.RealmParam] != "example.com" || params[httpauth.MechParam] != "SCRAM-SHA-256 SCRAM-SHA-1 PLAIN" {
+			t.Errorf("challenge = %v", params)
+		}
+	})
+
+	for _, mechName := range []string{"SCRAM-SHA-256", "SCRAM-SHA-1", "PLAIN"} {
+		t.Run(mechName, func(t *testing.T) {
+			client := newClient(http.DefaultTransport, mechName, sasltest.Password)
+			req, err := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader("hello"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+			body, _ := io.ReadAll(res.Body)
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d : %s", res.StatusCode, body)
+			}
+			if expected := sasltest.Username + ":" + mechName + ":hello"; string(body) != expected {
+				t.Errorf("body = %q, want %q", body, expected)
+			}
+		})
+	}
+
+	t.Run("invalid-password", func(t *testing.T) {
+		for _, mechName := range []string{"SCRAM-SHA-256", "PLAIN"} {
+			res, err := newClient(http.DefaultTransport, mechName, "invalid").Get(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if res.StatusCode != http.StatusUnauthorized {
+				t.Errorf("%s status = %d", mechName, res.StatusCode)
+			}
+			if mechName != "SCRAM-SHA-256" {
+				continue
+			}
+			params, _ := httpauth.ParseParams(res.Header.Get("WWW-Authenticate"))
+			s2c, _, _ := params.Token(httpauth.S2CParam)
+			if string(s2c) != "e=invalid-proof" {
+				t.Errorf("%s server-error = %q", mechName, s2c)
+			}
+		}
+	})
+
+	t.Run("replayed-authorization", func(t *testing.T) {
+		recorder := &recordTransport{base: http.DefaultTransport, authorization: ""}
+		res, err := newClient(recorder, "SCRAM-SHA-256", sasltest.Password).Get(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d", res.StatusCode)
+		}
+		for range 3 {
+			req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", recorder.authorization)
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if res.StatusCode != http.StatusUnauthorized {
+				t.Errorf("replayed status = %d", res.StatusCode)
+			}
+		}
+	})
+
+	t.Run("unsupported-mechanism", func(t *testing.T) {
+		res, err := newClient(http.DefaultTransport, "ANONYMOUS", sasltest.Password).Get(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Errorf("status = %d", res.StatusCode)
+		}
+	})
+
+	t.Run("server-signature", func(t *testing.T) {
+		res, err := newClient(tamperTransport{}, "SCRAM-SHA-256", sasltest.Password).Get(ts.URL)
+		if err == nil {
+			res.Body.Close()
+			t.Fatal("tampered server signature is accepted")
+		}
+		if !errors.Is(err, mech.ErrMalformed) {
+			t.Errorf("error = %v", err)
+		}
+	})
+
+	t.Run("unsealed-server", func(t *testing.T) {
+		// The handlers with random sealer keys cannot resume the exchanges of each other.
+		handlers := make([]http.Handler, 2)
+		for n := range handlers {
+			handlers[n], err = httpauth.NewHandler(sasltest.NewServer(), echoHandler())
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		ts := httptest.NewServer(roundRobin(handlers...))
+		defer ts.Close()
+		res, err := newClient(http.DefaultTransport, "SCRAM-SHA-256", sasltest.Password).Get(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Errorf("status = %d", res.StatusCode)
+		}
+	})
+}
+
+func TestHandlerDefaultMechanisms(t *testing.T) {
+	const expected = "SCRAM-SHA-512 SCRAM-SHA-256 SCRAM-SHA-1 PLAIN ANONYMOUS"
+	for range 10 {
+		handler, err := httpauth.NewHandler(sasltest.NewServer(), echoHandler())
+		if err != nil {
+			t.Fatal(err)
+		}
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/", nil))
+		params, ok := httpauth.ParseParams(res.Header().Get("WWW-Authenticate"))
+		if !ok {
+			t.Fatalf("challenge = %q", res.Header().Get("WWW-Authenticate"))
+		}
+		if params[httpauth.MechParam] != expected {
+			t.Fatalf("mechanisms = %q, want %q", params[httpauth.MechParam], expected)
+		}
+	}
+}
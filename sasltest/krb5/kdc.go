@@ -0,0 +1,363 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package krb5
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/cybergarage/go-sasl/sasl/gss"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+)
+
+// Realm is the default realm of the fake KDC.
+const Realm = "EXAMPLE.COM"
+
+// MechanismName is the name of the fake Kerberos V5 GSS-API mechanism.
+const MechanismName = "KRB5"
+
+var (
+	// ErrUnknownPrincipal is returned when the principal is not registered in the KDC.
+	ErrUnknownPrincipal = errors.New("unknown principal")
+	// ErrPreauthFailed is returned when the password of the client principal is invalid.
+	ErrPreauthFailed = errors.New("preauthentication failed")
+	// ErrInvalidToken is returned when the context token is invalid.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrModified is returned when the wrapped message is modified, replayed or reordered.
+	ErrModified = errors.New("message modified")
+)
+
+const macSize = sha256.Size
+
+// ServicePrincipal represents an option of the service principal name such as "ldap/host.example.com".
+type ServicePrincipal string
+
+// MutualAuth represents an option whether the initiator requests mutual authentication. It is enabled by default.
+type MutualAuth bool
+
+// KDC represents a deterministic fake Kerberos key distribution center which issues tickets in process.
+type KDC struct {
+	sync.Mutex
+	realm    string
+	users    map[string]string
+	services map[string][]byte
+}
+
+// NewKDC returns a new fake KDC for the realm.
+func NewKDC(realm string) *KDC {
+	return &KDC{
+		Mutex:    sync.Mutex{},
+		realm:    realm,
+		users:    map[string]string{},
+		services: map[string][]byte{},
+	}
+}
+
+// Realm returns the realm of the KDC.
+func (kdc *KDC) Realm() string {
+	return kdc.realm
+}
+
+// Principal returns the principal name qualified with the realm.
+func (kdc *KDC) Principal(name string) string {
+	if strings.Contains(name, "@") {
+		return name
+	}
+	return name + "@" + kdc.realm
+}
+
+// AddUser adds the client principal with the password.
+func (kdc *KDC) AddUser(name string, password string) {
+	kdc.Lock()
+	defer kdc.Unlock()
+	kdc.users[kdc.Principal(name)] = password
+}
+
+// AddService adds the service principal whose key is derived deterministically from the name.
+func (kdc *KDC) AddService(name string) {
+	kdc.Lock()
+	defer kdc.Unlock()
+	principal := kdc.Principal(name)
+	key := sha256.Sum256([]byte("service-key:" + principal))
+	kdc.services[principal] = key[:]
+}
+
+func (kdc *KDC) serviceKey(name string) ([]byte, error) {
+	kdc.Lock()
+	defer kdc.Unlock()
+	principal := kdc.Principal(name)
+	key, ok := kdc.services[principal]
+	if !ok {
+		return nil, fmt.Errorf("%w : %s", ErrUnknownPrincipal, principal)
+	}
+	return key, nil
+}
+
+// ticket returns a service ticket and its session key for the client principal.
+func (kdc *KDC) ticket(client string, password string, service string) ([]byte, []byte, error) {
+	client = kdc.Principal(client)
+	kdc.Lock()
+	userPassword, ok := kdc.users[client]
+	kdc.Unlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("%w : %s", ErrUnknownPrincipal, client)
+	}
+	if !hmac.Equal([]byte(userPassword), []byte(password)) {
+		return nil, nil, fmt.Errorf("%w : %s", ErrPreauthFailed, client)
+	}
+	key, err := kdc.serviceKey(service)
+	if err != nil {
+		return nil, nil, err
+	}
+	service = kdc.Principal(service)
+	ticket := []byte(client + "\x00" + service + "\x00")
+	ticket = append(ticket, mac(key, "ticket", ticket)...)
+	return ticket, mac(key, "session", ticket), nil
+}
+
+func mac(key []byte, label string, data ...[]byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(label))
+	for _, b := range data {
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// seal returns the message XORed with the keystream of the key, the label and the sequence number.
+func seal(key []byte, label string, seq []byte, msg []byte) []byte {
+	sealed := make([]byte, len(msg))
+	var stream []byte
+	for n := range msg {
+		if n%macSize == 0 {
+			stream = mac(key, "seal:"+label, seq, binary.BigEndian.AppendUint64(nil, uint64(n/macSize)))
+		}
+		sealed[n] = msg[n] ^ stream[n%macSize]
+	}
+	return sealed
+}
+
+// Mechanism represents a fake Kerberos V5 GSS-API mechanism backed by the fake KDC.
+type Mechanism struct {
+	kdc *KDC
+}
+
+// NewMechanism returns a new fake Kerberos V5 GSS-API mechanism.
+func NewMechanism(kdc *KDC) *Mechanism {
+	return &Mechanism{
+		kdc: kdc,
+	}
+}
+
+// Name returns the mechanism name.
+func (m *Mechanism) Name() string {
+	return MechanismName
+}
+
+// NewInitiator returns a new initiator context with the mech.Username, mech.Password, ServicePrincipal and MutualAuth options.
+func (m *Mechanism) NewInitiator(opts ...any) (gss.InitiatorContext, error) {
+	ctx := &initiator{
+		secContext: newSecContext("initiator", "acceptor"),
+		kdc:        m.kdc,
+		username:   "",
+		password:   "",
+		service:    "",
+		mutual:     true,
+	}
+	for _, opt := range opts {
+		switch v := opt.(type) {
+		case mech.Username:
+			ctx.username = string(v)
+		case mech.Password:
+			ctx.password = string(v)
+		case ServicePrincipal:
+			ctx.service = string(v)
+		case MutualAuth:
+			ctx.mutual = bool(v)
+		}
+	}
+	if len(ctx.service) == 0 {
+		return nil, fmt.Errorf("%w : no service principal", ErrUnknownPrincipal)
+	}
+	return ctx, nil
+}
+
+// NewAcceptor returns a new acceptor context with the ServicePrincipal option.
+func (m *Mechanism) NewAcceptor(opts ...any) (gss.AcceptorContext, error) {
+	ctx := &acceptor{
+		secContext: newSecContext("acceptor", "initiator"),
+		key:        nil,
+		sourceName: "",
+	}
+	var service string
+	for _, opt := range opts {
+		if v, ok := opt.(ServicePrincipal); ok {
+			service = string(v)
+		}
+	}
+	key, err := m.kdc.serviceKey(service)
+	if err != nil {
+		return nil, err
+	}
+	ctx.key = key
+	return ctx, nil
+}
+
+type secContext struct {
+	established bool
+	sessionKey  []byte
+	sendLabel   string
+	recvLabel   string
+	sendSeq     uint64
+	recvSeq     uint64
+}
+
+func newSecContext(sendLabel string, recvLabel string) secContext {
+	return secContext{
+		established: false,
+		sessionKey:  nil,
+		sendLabel:   sendLabel,
+		recvLabel:   recvLabel,
+		sendSeq:     0,
+		recvSeq:     0,
+	}
+}
+
+// Wrap returns the sequence number, the confidentiality flag, the message and the MAC of them.
+// The message is encrypted with a keystream of the session key if confidential is true.
+func (ctx *secContext) Wrap(msg []byte, confidential bool) ([]byte, error) {
+	if !ctx.established {
+		return nil, gss.ErrContextNotEstablished
+	}
+	token := binary.BigEndian.AppendUint64(nil, ctx.sendSeq)
+	if confidential {
+		token = append(token, 1)
+		token = append(token, seal(ctx.sessionKey, ctx.sendLabel, token[:8], msg)...)
+	} else {
+		token = append(token, 0)
+		token = append(token, msg...)
+	}
+	token = append(token, mac(ctx.sessionKey, ctx.sendLabel, token)...)
+	ctx.sendSeq++
+	return token, nil
+}
+
+func (ctx *secContext) Unwrap(token []byte) ([]byte, bool, error) {
+	if !ctx.established {
+		return nil, false, gss.ErrContextNotEstablished
+	}
+	if len(token) < 9+macSize {
+		return nil, false, ErrInvalidToken
+	}
+	body, sum := token[:len(token)-macSize], token[len(token)-macSize:]
+	if !hmac.Equal(sum, mac(ctx.sessionKey, ctx.recvLabel, body)) {
+		return nil, false, ErrModified
+	}
+	if binary.BigEndian.Uint64(body) != ctx.recvSeq {
+		return nil, false, ErrModified
+	}
+	ctx.recvSeq++
+	switch body[8] {
+	case 0:
+		return body[9:], false, nil
+	case 1:
+		return seal(ctx.sessionKey, ctx.recvLabel, body[:8], body[9:]), true, nil
+	}
+	return nil, false, ErrInvalidToken
+}
+
+func (ctx *secContext) Delete() error {
+	ctx.established = false
+	ctx.sessionKey = nil
+	return nil
+}
+
+type initiator struct {
+	secContext
+	kdc      *KDC
+	username string
+	password string
+	service  string
+	mutual   bool
+}
+
+// InitSecContext sends the AP-REQ which is the mutual flag, the ticket and the authenticator,
+// and verifies the AP-REP from the acceptor if mutual authentication is requested.
+func (ctx *initiator) InitSecContext(input []byte, cb []byte) ([]byte, bool, error) {
+	if ctx.sessionKey == nil {
+		ticket, sessionKey, err := ctx.kdc.ticket(ctx.username, ctx.password, ctx.service)
+		if err != nil {
+			return nil, false, err
+		}
+		ctx.sessionKey = sessionKey
+		flag := byte(0)
+		if ctx.mutual {
+			flag = 1
+		}
+		token := append([]byte{flag}, ticket...)
+		token = append(token, mac(sessionKey, "ap-req", cb)...)
+		ctx.established = !ctx.mutual
+		return token, ctx.established, nil
+	}
+	if ctx.established || !hmac.Equal(input, mac(ctx.sessionKey, "ap-rep", cb)) {
+		return nil, false, ErrInvalidToken
+	}
+	ctx.established = true
+	return nil, true, nil
+}
+
+type acceptor struct {
+	secContext
+	key        []byte
+	sourceName string
+}
+
+// AcceptSecContext verifies the AP-REQ, and returns the AP-REP if mutual authentication is requested.
+func (ctx *acceptor) AcceptSecContext(input []byte, cb []byte) ([]byte, bool, error) {
+	if ctx.established || len(input) < 1+2*macSize {
+		return nil, false, ErrInvalidToken
+	}
+	mutual := input[0] == 1
+	ticket, authenticator := input[1:len(input)-macSize], input[len(input)-macSize:]
+	names, sum := ticket[:len(ticket)-macSize], ticket[len(ticket)-macSize:]
+	if !hmac.Equal(sum, mac(ctx.key, "ticket", names)) {
+		return nil, false, ErrInvalidToken
+	}
+	client, _, ok := bytes.Cut(names, []byte{0x00})
+	if !ok {
+		return nil, false, ErrInvalidToken
+	}
+	sessionKey := mac(ctx.key, "session", ticket)
+	if !hmac.Equal(authenticator, mac(sessionKey, "ap-req", cb)) {
+		return nil, false, ErrInvalidToken
+	}
+	ctx.sessionKey = sessionKey
+	ctx.sourceName = string(client)
+	ctx.established = true
+	if !mutual {
+		return nil, true, nil
+	}
+	return mac(sessionKey, "ap-rep", cb), true, nil
+}
+
+func (ctx *acceptor) SourceName() string {
+	return ctx.sourceName
+}
@@ -0,0 +1,116 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/auth"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasl/mech/plugins/anonymous"
+	"github.com/cybergarage/go-sasl/sasltest"
+)
+
+type anonymousTestConn struct {
+	addr net.Addr
+}
+
+func (conn *anonymousTestConn) RemoteAddr() net.Addr {
+	return conn.addr
+}
+
+func TestAnonymousTrace(t *testing.T) {
+	server := sasltest.NewServer()
+
+	errDisabled := errors.New("anonymous login disabled")
+	policy := anonymous.Policy(func(conn auth.Conn, trace string) error {
+		addr, ok := conn.RemoteAddr().(*net.TCPAddr)
+		if !ok || !addr.IP.IsLoopback() {
+			return errDisabled
+		}
+		return nil
+	})
+	loopback := &anonymousTestConn{addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1234}}
+	remote := &anonymousTestConn{addr: &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1234}}
+
+	tests := []struct {
+		name       string
+		trace      string
+		serverOpts []mech.Option
+		reason     sasl.ErrorReason
+		cause      error
+	}{
+		{"token", "sirhc", nil, 0, nil},
+		{"email", "sirhc@example.com", []mech.Option{anonymous.EmailTrace}, 0, nil},
+		{"token-for-email", "sirhc", []mech.Option{anonymous.EmailTrace}, sasl.ReasonMalformed, anonymous.ErrInvalidTrace},
+		{"invalid-email", "sirhc@", nil, sasl.ReasonMalformed, anonymous.ErrInvalidTrace},
+		{"invalid-utf8", "\xff\xfe", nil, sasl.ReasonMalformed, anonymous.ErrInvalidTrace},
+		{"policy-allowed", "sirhc", []mech.Option{policy, loopback}, 0, nil},
+		{"policy-denied", "sirhc", []mech.Option{policy, remote}, sasl.ReasonAuthorizationDenied, errDisabled},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			serverMech, err := server.Mechanism(anonymous.Type)
+			if err != nil {
+				t.Fatal(err)
+			}
+			serverCtx, err := serverMech.Start(test.serverOpts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = serverCtx.Next([]byte(test.trace))
+			if test.cause != nil {
+				if reason, ok := sasl.ReasonOf(err); !ok || reason != test.reason {
+					t.Errorf("ReasonOf(%v) = %s, want %s", err, reason, test.reason)
+				}
+				if !errors.Is(err, test.cause) {
+					t.Errorf("%v is not %v", err, test.cause)
+				}
+				if serverCtx.Done() {
+					t.Error("server context is done")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			anonymousCtx, ok := serverCtx.(*anonymous.ServerContext)
+			if !ok {
+				t.Fatalf("%T is not %T", serverCtx, anonymousCtx)
+			}
+			if anonymousCtx.Trace() != test.trace {
+				t.Errorf("trace = %s, want %s", anonymousCtx.Trace(), test.trace)
+			}
+		})
+	}
+
+	t.Run("client", func(t *testing.T) {
+		clientMech, err := sasl.NewClient().Mechanism(anonymous.Type)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientCtx, err := clientMech.Start(mech.Token("\xff\xfe"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := clientCtx.Next(); !errors.Is(err, anonymous.ErrInvalidTrace) {
+			t.Errorf("%v is not %v", err, anonymous.ErrInvalidTrace)
+		}
+	})
+}
@@ -0,0 +1,355 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/audit"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasl/mech/plugins/anonymous"
+	"github.com/cybergarage/go-sasl/sasl/mech/plugins/gssapi"
+	"github.com/cybergarage/go-sasl/sasl/mech/plugins/scram"
+	"github.com/cybergarage/go-sasl/sasltest"
+	"github.com/cybergarage/go-sasl/sasltest/compliance"
+)
+
+type auditConn struct{}
+
+func (conn *auditConn) RemoteAddr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 12345}
+}
+
+// auditExchange runs an exchange, and returns the exchanged messages.
+func auditExchange(t *testing.T, clientCtx mech.Context, serverCtx mech.Context) ([][]byte, error) {
+	t.Helper()
+	msgs := [][]byte{}
+	var lastResponse sasl.Response
+	for !clientCtx.Done() || !serverCtx.Done() {
+		clientResponse, err := clientCtx.Next(lastResponse)
+		if err != nil {
+			return msgs, err
+		}
+		if clientResponse != nil {
+			msgs = append(msgs, clientResponse.Bytes())
+		}
+		if serverCtx.Done() {
+			break
+		}
+		serverResponse, err := serverCtx.Next(clientResponse)
+		if serverResponse != nil {
+			msgs = append(msgs, serverResponse.Bytes())
+		}
+		if err != nil {
+			return msgs, err
+		}
+		if clientCtx.Done() {
+			break
+		}
+		lastResponse = serverResponse
+	}
+	return msgs, nil
+}
+
+func readAuditEvents(t *testing.T, b []byte) []*audit.Event {
+	t.Helper()
+	events := []*audit.Event{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		event := &audit.Event{}
+		if err := json.Unmarshal(scanner.Bytes(), event); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// expectNoSensitiveData reports an error if the output includes the password or any exchanged message.
+func expectNoSensitiveData(t *testing.T, out []byte, password string, msgs [][]byte) {
+	t.Helper()
+	if bytes.Contains(out, []byte(password)) {
+		t.Errorf("password is included : %s", out)
+	}
+	for _, msg := range msgs {
+		for _, field := range strings.FieldsFunc(string(msg), func(r rune) bool { return r == ',' || r == 0 }) {
+			if len(field) < 8 {
+				continue
+			}
+			if bytes.Contains(out, []byte(field)) {
+				t.Errorf("message %q is included : %s", field, out)
+			}
+		}
+	}
+}
+
+func TestAuditEvents(t *testing.T) {
+	var out bytes.Buffer
+	observer := audit.NewJSONObserver(&out)
+
+	client := sasl.NewClient()
+	server := sasltest.NewServer()
+	server.SetObserver(observer)
+
+	tests := []struct {
+		name     string
+		password string
+		reason   string
+	}{
+		{"PLAIN", sasltest.Password, ""},
+		{"PLAIN", "invalid-password", mech.ReasonInvalidCredentials.String()},
+		{"SCRAM-SHA-256", sasltest.Password, ""},
+		{"SCRAM-SHA-256", "invalid-password", mech.ReasonInvalidCredentials.String()},
+		{"ANONYMOUS", sasltest.Password, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name+"/"+test.password, func(t *testing.T) {
+			out.Reset()
+
+			clientMech, err := client.Mechanism(test.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			serverMech, err := server.Mechanism(test.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			clientCtx, err := clientMech.Start(
+				mech.Username(sasltest.Username),
+				mech.Password(test.password),
+				mech.Token("trace"),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			serverCtx, err := serverMech.Start(&auditConn{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			msgs, err := auditExchange(t, clientCtx, serverCtx)
+			if (err == nil) != (len(test.reason) == 0) {
+				t.Fatalf("exchange() = %v", err)
+			}
+			if err := serverCtx.Dispose(); err != nil {
+				t.Error(err)
+			}
+			if err := observer.Err(); err != nil {
+				t.Fatal(err)
+			}
+
+			events := readAuditEvents(t, out.Bytes())
+			if len(events) < 2 {
+				t.Fatalf("events = %d : %s", len(events), out.String())
+			}
+			if events[0].Type != audit.MechanismSelected {
+				t.Errorf("first event = %s, want %s", events[0].Type, audit.MechanismSelected)
+			}
+			for n, event := range events {
+				if event.ID != events[0].ID || len(event.ID) == 0 {
+					t.Errorf("event %d ID = %q, want %q", n, event.ID, events[0].ID)
+				}
+				if event.Mechanism != test.name {
+					t.Errorf("event %d mechanism = %s, want %s", n, event.Mechanism, test.name)
+				}
+				if event.RemoteAddr != "192.0.2.1:12345" {
+					t.Errorf("event %d remote address = %s", n, event.RemoteAddr)
+				}
+				if 0 < n && n < len(events)-1 && event.Type != audit.StepCompleted {
+					t.Errorf("event %d = %s, want %s", n, event.Type, audit.StepCompleted)
+				}
+			}
+
+			last := events[len(events)-1]
+			if len(test.reason) == 0 {
+				if last.Type != audit.Succeeded {
+					t.Fatalf("last event = %s, want %s", last.Type, audit.Succeeded)
+				}
+				if test.name != "ANONYMOUS" && last.AuthcID != sasltest.Username {
+					t.Errorf("authcid = %s, want %s", last.AuthcID, sasltest.Username)
+				}
+			} else {
+				if last.Type != audit.Failed {
+					t.Fatalf("last event = %s, want %s", last.Type, audit.Failed)
+				}
+				if last.Reason != test.reason {
+					t.Errorf("reason = %s, want %s", last.Reason, test.reason)
+				}
+			}
+
+			expectNoSensitiveData(t, out.Bytes(), test.password, msgs)
+		})
+	}
+}
+
+func TestAuditAbortedEvent(t *testing.T) {
+	var events []*audit.Event
+	server := sasltest.NewServer()
+	server.SetObserver(audit.ObserverFunc(func(event *audit.Event) {
+		events = append(events, event)
+	}))
+
+	m, err := server.Mechanism("SCRAM-SHA-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := m.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ctx.(mech.SuspendableContext); !ok {
+		t.Errorf("%T is not suspendable", ctx)
+	}
+	if err := ctx.Dispose(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("events = %d", len(events))
+	}
+	if events[1].Type != audit.Failed || events[1].Reason != mech.ReasonAborted.String() {
+		t.Errorf("event = %s %s, want %s %s", events[1].Type, events[1].Reason, audit.Failed, mech.ReasonAborted)
+	}
+}
+
+func TestAuditInterfaces(t *testing.T) {
+	server := sasltest.NewServer()
+	server.SetObserver(audit.NewMultiObserver())
+
+	tests := []struct {
+		name      string
+		resumable bool
+	}{
+		{"PLAIN", false},
+		{"ANONYMOUS", false},
+		{"SCRAM-SHA-256", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := server.Mechanism(test.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := m.(mech.ResumableMechanism); ok != test.resumable {
+				t.Errorf("%T is resumable = %t, want %t", m, ok, test.resumable)
+			}
+			ctx, err := m.Start()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ctx.Dispose()
+			if _, ok := ctx.(mech.SuspendableContext); ok != test.resumable {
+				t.Errorf("%T is suspendable = %t, want %t", ctx, ok, test.resumable)
+			}
+		})
+	}
+
+	t.Run("unwrap", func(t *testing.T) {
+		m, err := server.Mechanism(anonymous.Type)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := mech.MechanismAs[*anonymous.Server](m); !ok {
+			t.Errorf("%T does not wrap %T", m, &anonymous.Server{})
+		}
+		ctx, err := m.Start()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ctx.Next([]byte("sirhc")); err != nil {
+			t.Fatal(err)
+		}
+		traceCtx, ok := sasl.ContextAs[interface{ Trace() string }](ctx)
+		if !ok || traceCtx.Trace() != "sirhc" {
+			t.Errorf("%T does not wrap the trace", ctx)
+		}
+		if _, ok := sasl.ContextAs[*scram.ServerContext](ctx); ok {
+			t.Errorf("%T wraps a SCRAM context", ctx)
+		}
+	})
+}
+
+func TestAuditSlogObserver(t *testing.T) {
+	var out bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&out, nil))
+
+	client := sasl.NewClient()
+	server := sasltest.NewServer()
+	server.SetObserver(audit.NewSlogObserver(logger))
+
+	const password = "invalid-password"
+
+	clientMech, err := client.Mechanism("SCRAM-SHA-512")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverMech, err := server.Mechanism("SCRAM-SHA-512")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCtx, err := clientMech.Start(mech.Username(sasltest.Username), mech.Password(password))
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverCtx, err := serverMech.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs, err := auditExchange(t, clientCtx, serverCtx)
+	if err == nil {
+		t.Fatal("exchange() succeeded with an invalid password")
+	}
+
+	if !strings.Contains(out.String(), `"level":"WARN","msg":"sasl failed"`) {
+		t.Errorf("no failed event : %s", out.String())
+	}
+	expectNoSensitiveData(t, out.Bytes(), password, msgs)
+}
+
+func TestAuditCompliance(t *testing.T) {
+	client, server, mechs := newFuzzPeers()
+	server.SetObserver(audit.NewMultiObserver())
+
+	for _, m := range mechs {
+		t.Run(m.name, func(t *testing.T) {
+			clientMech, err := client.Mechanism(m.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			serverMech, err := server.Mechanism(m.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := mech.MechanismAs[*audit.Mechanism](serverMech); !ok {
+				t.Fatalf("%T is not observed", serverMech)
+			}
+			suiteOpts := []compliance.SuiteOption{
+				compliance.WithClientOptions(m.clientOpts...),
+				compliance.WithServerOptions(m.serverOpts...),
+			}
+			if m.name == gssapi.Type {
+				suiteOpts = append(suiteOpts, compliance.WithReplayable())
+			}
+			compliance.NewSuite(clientMech, serverMech, suiteOpts...).Run(t)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/auth"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasltest"
+)
+
+func exchange(clientCtx mech.Context, serverCtx mech.Context) error {
+	var lastResponse sasl.Response
+	for !clientCtx.Done() || !serverCtx.Done() {
+		clientResponse, err := clientCtx.Next(lastResponse)
+		if err != nil {
+			return err
+		}
+		if serverCtx.Done() {
+			break
+		}
+		serverResponse, err := serverCtx.Next(clientResponse)
+		if err != nil {
+			return err
+		}
+		if clientCtx.Done() {
+			break
+		}
+		lastResponse = serverResponse
+	}
+	return nil
+}
+
+func TestAuthorization(t *testing.T) {
+	const authzID = "other"
+
+	client := sasl.NewClient()
+	server := sasltest.NewServer()
+
+	clientOpts := []mech.Option{
+		mech.AuthzID(authzID),
+		mech.Username(sasltest.Username),
+		mech.Password(sasltest.Password),
+	}
+
+	mechNames := []string{"PLAIN", "SCRAM-SHA-1", "SCRAM-SHA-256", "SCRAM-SHA-512"}
+
+	tests := []struct {
+		name    string
+		authz   auth.Authorizer
+		allowed bool
+	}{
+		{"default", auth.NewDefaultAuthorizer(), false},
+		{"admin", auth.NewRuleAuthorizer(auth.AllowAdmins(sasltest.Username)), true},
+	}
+
+	for _, test := range tests {
+		server.SetAuthorizer(test.authz)
+		for _, mechName := range mechNames {
+			t.Run(test.name+"/"+mechName, func(t *testing.T) {
+				clientMech, err := client.Mechanism(mechName)
+				if err != nil {
+					t.Fatal(err)
+				}
+				serverMech, err := server.Mechanism(mechName)
+				if err != nil {
+					t.Fatal(err)
+				}
+				clientCtx, err := clientMech.Start(clientOpts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				serverCtx, err := serverMech.Start()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				err = exchange(clientCtx, serverCtx)
+				if !test.allowed {
+					if !errors.Is(err, auth.ErrAuthorizationDenied) {
+						t.Errorf("exchange() = %v, want %v", err, auth.ErrAuthorizationDenied)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				authzCtx, ok := serverCtx.(mech.AuthorizedContext)
+				if !ok {
+					t.Fatalf("%T is not an authorized context", serverCtx)
+				}
+				if authzCtx.AuthorizedID() != authzID {
+					t.Errorf("AuthorizedID() = %s, want %s", authzCtx.AuthorizedID(), authzID)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasl/mech/plugins/anonymous"
+	"github.com/cybergarage/go-sasl/sasl/mech/plugins/gssapi"
+	"github.com/cybergarage/go-sasl/sasltest/compliance"
+)
+
+func TestCompliance(t *testing.T) {
+	client, server, mechs := newFuzzPeers()
+
+	for _, m := range mechs {
+		t.Run(m.name, func(t *testing.T) {
+			clientMech, err := client.Mechanism(m.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			serverMech, err := server.Mechanism(m.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			invalidOpts := []mech.Option{}
+			for _, opt := range m.clientOpts {
+				switch opt.(type) {
+				case mech.Password:
+					invalidOpts = append(invalidOpts, mech.Password("invalid"))
+				case mech.Token:
+					invalidOpts = append(invalidOpts, mech.Token(strings.Repeat("a", anonymous.MaxTraceLength+1)))
+				default:
+					invalidOpts = append(invalidOpts, opt)
+				}
+			}
+
+			suiteOpts := []compliance.SuiteOption{
+				compliance.WithClientOptions(m.clientOpts...),
+				compliance.WithServerOptions(m.serverOpts...),
+				compliance.WithInvalidClientOptions(invalidOpts...),
+			}
+			// The fake Kerberos backend has no replay cache.
+			if m.name == gssapi.Type {
+				suiteOpts = append(suiteOpts, compliance.WithReplayable())
+			}
+			compliance.NewSuite(clientMech, serverMech, suiteOpts...).Run(t)
+		})
+	}
+}
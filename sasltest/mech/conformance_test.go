@@ -0,0 +1,73 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasltest/conformance"
+)
+
+func TestConformanceVectors(t *testing.T) {
+	client := sasl.NewClient()
+	server := sasl.NewServer()
+
+	for _, v := range conformance.Vectors() {
+		t.Run(v.String()+"/client", func(t *testing.T) {
+			m, err := client.Mechanism(v.Mechanism)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := conformance.VerifyClient(m, v); err != nil {
+				t.Errorf("%s : %v", v.Source, err)
+			}
+		})
+		t.Run(v.String()+"/server", func(t *testing.T) {
+			m, err := server.Mechanism(v.Mechanism)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := conformance.VerifyServer(m, v); err != nil {
+				t.Errorf("%s : %v", v.Source, err)
+			}
+		})
+	}
+}
+
+func TestConformanceMismatch(t *testing.T) {
+	client := sasl.NewClient()
+	server := sasl.NewServer()
+
+	v := conformance.RFC7677()
+	v.Messages[1] = conformance.S("r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=8192")
+
+	m, err := server.Mechanism(v.Mechanism)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conformance.VerifyServer(m, v); !errors.Is(err, conformance.ErrMismatch) {
+		t.Errorf("VerifyServer() = %v, want %v", err, conformance.ErrMismatch)
+	}
+
+	m, err = client.Mechanism(v.Mechanism)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conformance.VerifyClient(m, v); !errors.Is(err, conformance.ErrMismatch) {
+		t.Errorf("VerifyClient() = %v, want %v", err, conformance.ErrMismatch)
+	}
+}
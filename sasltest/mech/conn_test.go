@@ -0,0 +1,103 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/auth"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasl/mech/plugins/gs2"
+	"github.com/cybergarage/go-sasl/sasl/mech/plugins/gssapi"
+	"github.com/cybergarage/go-sasl/sasltest"
+	gs2test "github.com/cybergarage/go-sasl/sasltest/gs2"
+	"github.com/cybergarage/go-sasl/sasltest/krb5"
+)
+
+// connAuthorizer records the connections passed to Authorize.
+type connAuthorizer struct {
+	conns []auth.Conn
+}
+
+func (authz *connAuthorizer) Authorize(conn auth.Conn, authcid string, authzid string, mech string) error {
+	authz.conns = append(authz.conns, conn)
+	return nil
+}
+
+func TestServerConn(t *testing.T) {
+	const service = krb5.ServicePrincipal("ldap/host.example.com")
+
+	kdc := krb5.NewKDC(krb5.Realm)
+	kdc.AddUser(sasltest.Username, sasltest.Password)
+	kdc.AddService(string(service))
+	krb5Mech := krb5.NewMechanism(kdc)
+	gssMech := gs2test.NewMechanism()
+
+	client := sasl.NewClient()
+	client.AddMechanisms(gssapi.NewClient(krb5Mech), gs2.NewClient(gssMech))
+	server := sasltest.NewServer()
+	server.AddMechanisms(gssapi.NewServer(krb5Mech), gs2.NewServer(gssMech))
+
+	credOpts := []mech.Option{
+		mech.Username(sasltest.Username),
+		mech.Password(sasltest.Password),
+	}
+
+	tests := []struct {
+		mechName   string
+		clientOpts []mech.Option
+		serverOpts []mech.Option
+	}{
+		{"PLAIN", credOpts, nil},
+		{"SCRAM-SHA-256", credOpts, nil},
+		{"GS2-TEST", credOpts, nil},
+		{gssapi.Type, append([]mech.Option{service}, credOpts...), []mech.Option{service}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.mechName, func(t *testing.T) {
+			authz := &connAuthorizer{}
+			server.SetAuthorizer(authz)
+
+			clientMech, err := client.Mechanism(test.mechName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			serverMech, err := server.Mechanism(test.mechName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			clientCtx, err := clientMech.Start(test.clientOpts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The connection implements only auth.Conn as the HTTP and gRPC adapters do.
+
+			conn := &auditConn{}
+			serverCtx, err := serverMech.Start(append([]mech.Option{conn}, test.serverOpts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := exchange(clientCtx, serverCtx); err != nil {
+				t.Fatal(err)
+			}
+			if len(authz.conns) != 1 || authz.conns[0] != conn {
+				t.Errorf("Authorize() conns = %v, want %v", authz.conns, conn)
+			}
+		})
+	}
+}
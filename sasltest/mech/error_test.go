@@ -0,0 +1,118 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasltest"
+)
+
+func TestErrorReasons(t *testing.T) {
+	client := sasl.NewClient()
+	server := sasltest.NewServer()
+
+	expectReason := func(t *testing.T, err error, mechName string, reason sasl.ErrorReason) {
+		t.Helper()
+		var saslErr *sasl.Error
+		if !errors.As(err, &saslErr) {
+			t.Fatalf("%v (%T) is not a SASL error", err, err)
+		}
+		if saslErr.Reason() != reason || saslErr.Mechanism() != mechName {
+			t.Errorf("%v = (%s, %s), want (%s, %s)", err, saslErr.Reason(), saslErr.Mechanism(), reason, mechName)
+		}
+		if !errors.Is(err, reason.Err()) {
+			t.Errorf("%v is not %v", err, reason.Err())
+		}
+		if got, ok := sasl.ReasonOf(err); !ok || got != reason {
+			t.Errorf("ReasonOf(%v) = %s", err, got)
+		}
+		if strings.Contains(saslErr.Message(), sasltest.Username) {
+			t.Errorf("public message %q includes the username", saslErr.Message())
+		}
+	}
+
+	authenticate := func(t *testing.T, mechName string, clientOpts ...mech.Option) error {
+		t.Helper()
+		clientMech, err := client.Mechanism(mechName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverMech, err := server.Mechanism(mechName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientCtx, err := clientMech.Start(clientOpts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverCtx, err := serverMech.Start()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return exchange(clientCtx, serverCtx)
+	}
+
+	t.Run("unsupported-mechanism", func(t *testing.T) {
+		_, err := server.Mechanism("UNKNOWN")
+		expectReason(t, err, "UNKNOWN", sasl.ReasonUnsupportedMechanism)
+		if !errors.Is(err, sasl.ErrUnsupportedMechanism) {
+			t.Errorf("%v is not %v", err, sasl.ErrUnsupportedMechanism)
+		}
+	})
+
+	for _, mechName := range []string{"PLAIN", "SCRAM-SHA-256"} {
+		t.Run(mechName, func(t *testing.T) {
+			t.Run("invalid-credentials", func(t *testing.T) {
+				err := authenticate(t, mechName, mech.Username(sasltest.Username), mech.Password("invalid"))
+				expectReason(t, err, mechName, sasl.ReasonInvalidCredentials)
+			})
+
+			t.Run("authorization-denied", func(t *testing.T) {
+				err := authenticate(t, mechName, mech.AuthzID("other"), mech.Username(sasltest.Username), mech.Password(sasltest.Password))
+				expectReason(t, err, mechName, sasl.ReasonAuthorizationDenied)
+			})
+
+			t.Run("malformed", func(t *testing.T) {
+				serverMech, err := server.Mechanism(mechName)
+				if err != nil {
+					t.Fatal(err)
+				}
+				serverCtx, err := serverMech.Start()
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, err = serverCtx.Next()
+				expectReason(t, err, mechName, sasl.ReasonMalformed)
+			})
+
+			t.Run("unknown-option", func(t *testing.T) {
+				if mechName != "SCRAM-SHA-256" {
+					t.Skip("only SCRAM rejects unknown options")
+				}
+				serverMech, err := server.Mechanism(mechName)
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, err = serverMech.Start(struct{}{})
+				expectReason(t, err, mechName, sasl.ReasonMalformed)
+			})
+		})
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasl/mech/plugins/gs2"
+	"github.com/cybergarage/go-sasl/sasl/mech/plugins/gssapi"
+	"github.com/cybergarage/go-sasl/sasltest"
+	gs2test "github.com/cybergarage/go-sasl/sasltest/gs2"
+	"github.com/cybergarage/go-sasl/sasltest/krb5"
+)
+
+type fuzzMechanism struct {
+	name       string
+	clientOpts []mech.Option
+	serverOpts []mech.Option
+}
+
+// newFuzzPeers returns a client and a server which support all mechanisms, and the options for each mechanism.
+func newFuzzPeers() (sasl.Client, sasl.Server, []fuzzMechanism) {
+	const service = krb5.ServicePrincipal("ldap/host.example.com")
+
+	kdc := krb5.NewKDC(krb5.Realm)
+	kdc.AddUser(sasltest.Username, sasltest.Password)
+	kdc.AddService(string(service))
+	krb5Mech := krb5.NewMechanism(kdc)
+	gs2Mech := gs2test.NewMechanism()
+
+	client := sasl.NewClient()
+	client.AddMechanisms(gs2.NewClient(gs2Mech), gs2.NewPlusClient(gs2Mech), gssapi.NewClient(krb5Mech))
+	server := sasltest.NewServer()
+	server.AddMechanisms(gs2.NewServer(gs2Mech), gs2.NewPlusServer(gs2Mech), gssapi.NewServer(krb5Mech))
+
+	credOpts := []mech.Option{
+		mech.Username(sasltest.Username),
+		mech.Password(sasltest.Password),
+	}
+	cb := mech.ChannelBinding{Type: "tls-exporter", Data: []byte("exporter")}
+
+	mechs := []fuzzMechanism{
+		{"ANONYMOUS", []mech.Option{mech.Token(sasltest.Username)}, nil},
+		{"PLAIN", credOpts, nil},
+		{"GS2-TEST", credOpts, nil},
+		{"GS2-TEST-PLUS", append([]mech.Option{cb}, credOpts...), []mech.Option{cb}},
+		{"GSSAPI", append([]mech.Option{service}, credOpts...), []mech.Option{service}},
+	}
+	for _, m := range client.Mechanisms() {
+		if strings.HasPrefix(m.Name(), "SCRAM-") {
+			mechs = append(mechs, fuzzMechanism{m.Name(), credOpts, nil})
+		}
+	}
+	return client, server, mechs
+}
+
+// startFuzzExchange starts the client and server contexts, and exchanges the valid messages for the steps.
+func startFuzzExchange(t *testing.T, client sasl.Client, server sasl.Server, m fuzzMechanism, steps int) (mech.Context, mech.Context) {
+	t.Helper()
+	clientMech, err := client.Mechanism(m.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverMech, err := server.Mechanism(m.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCtx, err := clientMech.Start(m.clientOpts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverCtx, err := serverMech.Start(m.serverOpts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var serverResponse mech.Response
+	for range steps {
+		if clientCtx.Done() || serverCtx.Done() {
+			break
+		}
+		clientResponse, err := clientCtx.Next(serverResponse)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverResponse, err = serverCtx.Next(clientResponse)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return clientCtx, serverCtx
+}
+
+func expectTypedError(t *testing.T, name string, data []byte, err error) {
+	t.Helper()
+	if err == nil {
+		return
+	}
+	var saslErr *sasl.Error
+	if !errors.As(err, &saslErr) {
+		t.Fatalf("%s: %q: untyped error %v (%T)", name, data, err, err)
+	}
+}
+
+func FuzzServerMechanisms(f *testing.F) {
+	client, server, mechs := newFuzzPeers()
+
+	seeds := [][]byte{
+		{},
+		[]byte("sirhc"),
+		[]byte("\x00user\x00password"),
+		[]byte("n,,n=user,r=fyko+d2lbbFgONRv9qkxdawL"),
+		[]byte("c=biws,r=fyko+d2lbbFgONRv9qkxdawL,p=v0X8v3Bz2T0CJGbJQyF0X+HI4Ts="),
+		[]byte("F,p=tls-exporter,,user\x00"),
+		{0x01, 0x00, 0x00, 0x00},
+	}
+	for n := range mechs {
+		for _, seed := range seeds {
+			f.Add(uint8(n), uint8(0), seed)
+			f.Add(uint8(n), uint8(1), seed)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, idx uint8, steps uint8, data []byte) {
+		m := mechs[int(idx)%len(mechs)]
+		_, serverCtx := startFuzzExchange(t, client, server, m, int(steps%3))
+		_, err := serverCtx.Next(data)
+		expectTypedError(t, m.name, data, err)
+	})
+}
+
+func FuzzClientMechanisms(f *testing.F) {
+	client, server, mechs := newFuzzPeers()
+
+	seeds := [][]byte{
+		{},
+		[]byte("r=fyko+d2lbbFgONRv9qkxdawL3rfcNHYJY1ZVvWVs7j,s=QSXCR+Q6sek8bf92,i=4096"),
+		[]byte("v=rmF9pqV8S7suAoZWja4dJRkFsKQ="),
+		[]byte("e=invalid-proof"),
+		{0x07, 0x01, 0x00, 0x00},
+	}
+	for n := range mechs {
+		for _, seed := range seeds {
+			f.Add(uint8(n), uint8(1), seed)
+			f.Add(uint8(n), uint8(2), seed)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, idx uint8, steps uint8, data []byte) {
+		m := mechs[int(idx)%len(mechs)]
+		clientCtx, _ := startFuzzExchange(t, client, server, m, int(steps%3))
+		_, err := clientCtx.Next(data)
+		expectTypedError(t, m.name, data, err)
+	})
+}
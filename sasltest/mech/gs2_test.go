@@ -0,0 +1,193 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/gss"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasl/mech/plugins/gs2"
+	"github.com/cybergarage/go-sasl/sasltest"
+	gs2test "github.com/cybergarage/go-sasl/sasltest/gs2"
+)
+
+func TestGS2Mechanisms(t *testing.T) {
+	gssMech := gs2test.NewMechanism()
+
+	client := sasl.NewClient()
+	client.AddMechanisms(gs2.NewClient(gssMech), gs2.NewPlusClient(gssMech))
+	server := sasltest.NewServer()
+	server.AddMechanisms(gs2.NewServer(gssMech), gs2.NewPlusServer(gssMech))
+
+	cb := mech.ChannelBinding{Type: "tls-exporter", Data: []byte("exporter")}
+
+	start := func(t *testing.T, mechName string, clientOpts []mech.Option, serverOpts []mech.Option) (mech.Context, mech.Context) {
+		t.Helper()
+		clientMech, err := client.Mechanism(mechName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverMech, err := server.Mechanism(mechName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientCtx, err := clientMech.Start(clientOpts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverCtx, err := serverMech.Start(serverOpts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return clientCtx, serverCtx
+	}
+
+	credOpts := []mech.Option{
+		mech.Username(sasltest.Username),
+		mech.Password(sasltest.Password),
+	}
+
+	t.Run("mechanisms", func(t *testing.T) {
+		for _, name := range []string{"GS2-TEST", "GS2-TEST-PLUS"} {
+			if _, err := server.Mechanism(name); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		tests := []struct {
+			mechName   string
+			clientOpts []mech.Option
+			serverOpts []mech.Option
+			cbType     string
+		}{
+			{"GS2-TEST", credOpts, nil, ""},
+			{"GS2-TEST", credOpts, []mech.Option{mech.ChannelBinding{Type: "tls-exporter", Data: nil}}, ""},
+			{"GS2-TEST-PLUS", append([]mech.Option{cb}, credOpts...), []mech.Option{cb}, cb.Type},
+		}
+		for _, test := range tests {
+			t.Run(test.mechName, func(t *testing.T) {
+				clientCtx, serverCtx := start(t, test.mechName, test.clientOpts, test.serverOpts)
+				if err := exchange(clientCtx, serverCtx); err != nil {
+					t.Fatal(err)
+				}
+				if !clientCtx.Done() || !serverCtx.Done() {
+					t.Fatalf("done = (%t, %t)", clientCtx.Done(), serverCtx.Done())
+				}
+				result, ok := serverCtx.(sasl.AuthResultContext).AuthResult()
+				if !ok {
+					t.Fatal("no auth result")
+				}
+				if result.AuthcID() != sasltest.Username || result.Mechanism() != test.mechName || result.ChannelBinding() != test.cbType {
+					t.Errorf("result = (%s, %s, %s)", result.AuthcID(), result.Mechanism(), result.ChannelBinding())
+				}
+
+				msg := []byte("hello")
+				for _, confidential := range []bool{false, true} {
+					wrapped, err := clientCtx.(*gs2.ClientContext).Wrap(msg, confidential)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if bytes.Contains(wrapped, msg) == confidential {
+						t.Errorf("wrapped message = %q (confidential %t)", wrapped, confidential)
+					}
+					unwrapped, conf, err := serverCtx.(*gs2.ServerContext).Unwrap(wrapped)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !bytes.Equal(unwrapped, msg) || conf != confidential {
+						t.Errorf("unwrapped message = (%s, %t), want (%s, %t)", unwrapped, conf, msg, confidential)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			mechName   string
+			clientOpts []mech.Option
+			serverOpts []mech.Option
+			reason     sasl.ErrorReason
+			cause      error
+		}{
+			{
+				"invalid-password", "GS2-TEST",
+				[]mech.Option{mech.Username(sasltest.Username), mech.Password("invalid")}, nil,
+				sasl.ReasonInvalidCredentials, gs2test.ErrInvalidProof,
+			},
+			{
+				"authorization-denied", "GS2-TEST",
+				append([]mech.Option{mech.AuthzID("other")}, credOpts...), nil,
+				sasl.ReasonAuthorizationDenied, nil,
+			},
+			{
+				"channel-binding-mismatch", "GS2-TEST-PLUS",
+				append([]mech.Option{mech.ChannelBinding{Type: cb.Type, Data: []byte("other")}}, credOpts...), []mech.Option{cb},
+				sasl.ReasonInvalidCredentials, gs2test.ErrInvalidProof,
+			},
+			{
+				"channel-binding-type", "GS2-TEST-PLUS",
+				append([]mech.Option{mech.ChannelBinding{Type: "tls-unique", Data: cb.Data}}, credOpts...), []mech.Option{cb},
+				sasl.ReasonEncryptionRequired, gss.ErrUnsupportedChannelBindingType,
+			},
+			{
+				"no-server-channel-binding", "GS2-TEST-PLUS",
+				append([]mech.Option{cb}, credOpts...), nil,
+				sasl.ReasonEncryptionRequired, gss.ErrNoChannelBinding,
+			},
+			{
+				"downgrade", "GS2-TEST",
+				append([]mech.Option{cb}, credOpts...), []mech.Option{cb},
+				sasl.ReasonInvalidCredentials, gss.ErrChannelBindingDowngrade,
+			},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				clientCtx, serverCtx := start(t, test.mechName, test.clientOpts, test.serverOpts)
+				err := exchange(clientCtx, serverCtx)
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if reason, ok := sasl.ReasonOf(err); !ok || reason != test.reason {
+					t.Errorf("ReasonOf(%v) = %s, want %s", err, reason, test.reason)
+				}
+				if test.cause != nil && !errors.Is(err, test.cause) {
+					t.Errorf("%v is not %v", err, test.cause)
+				}
+				if serverCtx.Done() {
+					t.Error("server context is done")
+				}
+			})
+		}
+	})
+
+	t.Run("plus-without-channel-binding", func(t *testing.T) {
+		clientMech, err := client.Mechanism("GS2-TEST-PLUS")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = clientMech.Start(credOpts...)
+		if !errors.Is(err, gss.ErrNoChannelBinding) {
+			t.Errorf("%v is not %v", err, gss.ErrNoChannelBinding)
+		}
+	})
+}
@@ -0,0 +1,264 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/gss"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasl/mech/plugins/gssapi"
+	"github.com/cybergarage/go-sasl/sasltest"
+	"github.com/cybergarage/go-sasl/sasltest/krb5"
+)
+
+// confMechanism records the confidentiality flags of the wrapped messages, and drops the flags if downgrade is true.
+type confMechanism struct {
+	gss.Mechanism
+	flags     []bool
+	downgrade bool
+}
+
+func (m *confMechanism) NewInitiator(opts ...any) (gss.InitiatorContext, error) {
+	sec, err := m.Mechanism.NewInitiator(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &confInitiator{InitiatorContext: sec, mechanism: m}, nil
+}
+
+func (m *confMechanism) NewAcceptor(opts ...any) (gss.AcceptorContext, error) {
+	sec, err := m.Mechanism.NewAcceptor(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &confAcceptor{AcceptorContext: sec, mechanism: m}, nil
+}
+
+func (m *confMechanism) wrap(sec gss.SecContext, msg []byte, confidential bool) ([]byte, error) {
+	m.flags = append(m.flags, confidential)
+	return sec.Wrap(msg, confidential && !m.downgrade)
+}
+
+type confInitiator struct {
+	gss.InitiatorContext
+	mechanism *confMechanism
+}
+
+func (sec *confInitiator) Wrap(msg []byte, confidential bool) ([]byte, error) {
+	return sec.mechanism.wrap(sec.InitiatorContext, msg, confidential)
+}
+
+type confAcceptor struct {
+	gss.AcceptorContext
+	mechanism *confMechanism
+}
+
+func (sec *confAcceptor) Wrap(msg []byte, confidential bool) ([]byte, error) {
+	return sec.mechanism.wrap(sec.AcceptorContext, msg, confidential)
+}
+
+func TestGSSAPIMechanism(t *testing.T) {
+	const service = krb5.ServicePrincipal("ldap/host.example.com")
+
+	kdc := krb5.NewKDC(krb5.Realm)
+	kdc.AddUser(sasltest.Username, sasltest.Password)
+	kdc.AddService(string(service))
+	krb5Mech := krb5.NewMechanism(kdc)
+
+	client := sasl.NewClient()
+	client.AddMechanism(gssapi.NewClient(krb5Mech))
+	server := sasltest.NewServer()
+	server.AddMechanism(gssapi.NewServer(krb5Mech))
+
+	start := func(t *testing.T, clientOpts []mech.Option, serverOpts []mech.Option) (*gssapi.ClientContext, *gssapi.ServerContext) {
+		t.Helper()
+		clientMech, err := client.Mechanism(gssapi.Type)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverMech, err := server.Mechanism(gssapi.Type)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientCtx, err := clientMech.Start(append([]mech.Option{service}, clientOpts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverCtx, err := serverMech.Start(append([]mech.Option{service}, serverOpts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return clientCtx.(*gssapi.ClientContext), serverCtx.(*gssapi.ServerContext)
+	}
+
+	credOpts := []mech.Option{
+		mech.Username(sasltest.Username),
+		mech.Password(sasltest.Password),
+	}
+
+	t.Run("success", func(t *testing.T) {
+		tests := []struct {
+			name         string
+			mutual       krb5.MutualAuth
+			serverLayers gssapi.SecurityLayer
+			layer        gssapi.SecurityLayer
+			ssf          int
+		}{
+			{"mutual-confidentiality", true, gssapi.AllSecurityLayers, gssapi.ConfidentialityLayer, 56},
+			{"mutual-integrity", true, gssapi.NoSecurityLayer | gssapi.IntegrityLayer, gssapi.IntegrityLayer, 1},
+			{"mutual-none", true, gssapi.NoSecurityLayer, gssapi.NoSecurityLayer, 0},
+			{"confidentiality", false, gssapi.AllSecurityLayers, gssapi.ConfidentialityLayer, 56},
+			{"none", false, gssapi.NoSecurityLayer, gssapi.NoSecurityLayer, 0},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				clientCtx, serverCtx := start(t,
+					append([]mech.Option{test.mutual, gssapi.MaxBufferSize(4096)}, credOpts...),
+					[]mech.Option{test.serverLayers},
+				)
+				if err := exchange(clientCtx, serverCtx); err != nil {
+					t.Fatal(err)
+				}
+				if !clientCtx.Done() || !serverCtx.Done() {
+					t.Fatalf("done = (%t, %t)", clientCtx.Done(), serverCtx.Done())
+				}
+				if clientCtx.SecurityLayer() != test.layer || serverCtx.SecurityLayer() != test.layer {
+					t.Errorf("layer = (%s, %s), want %s", clientCtx.SecurityLayer(), serverCtx.SecurityLayer(), test.layer)
+				}
+
+				result, _ := serverCtx.AuthResult()
+				if result.AuthcID() != kdc.Principal(sasltest.Username) || result.Realm() != krb5.Realm || result.SSF() != test.ssf {
+					t.Errorf("result = (%s, %s, %d)", result.AuthcID(), result.Realm(), result.SSF())
+				}
+
+				if test.layer == gssapi.NoSecurityLayer {
+					if serverCtx.MaxBufferSize() != 0 {
+						t.Errorf("max buffer size = %d", serverCtx.MaxBufferSize())
+					}
+					if _, err := clientCtx.Wrap([]byte("hello")); !errors.Is(err, gssapi.ErrNoSecurityLayer) {
+						t.Errorf("%v is not %v", err, gssapi.ErrNoSecurityLayer)
+					}
+					return
+				}
+
+				if serverCtx.MaxBufferSize() != 4096 || clientCtx.MaxBufferSize() != gssapi.DefaultMaxBufferSize {
+					t.Errorf("max buffer size = (%d, %d)", clientCtx.MaxBufferSize(), serverCtx.MaxBufferSize())
+				}
+				msg := []byte("hello")
+				wrapped, err := clientCtx.Wrap(msg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if bytes.Contains(wrapped, msg) != (test.layer == gssapi.IntegrityLayer) {
+					t.Errorf("wrapped message = %q with %s", wrapped, test.layer)
+				}
+				unwrapped, err := serverCtx.Unwrap(wrapped)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(unwrapped, msg) {
+					t.Errorf("%s != %s", unwrapped, msg)
+				}
+				if _, err := serverCtx.Unwrap(wrapped); !errors.Is(err, krb5.ErrModified) {
+					t.Errorf("replayed message: %v is not %v", err, krb5.ErrModified)
+				}
+				wrapped, err = serverCtx.Wrap(msg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				wrapped[len(wrapped)/2] ^= 0xFF
+				if _, err := clientCtx.Unwrap(wrapped); !errors.Is(err, krb5.ErrModified) {
+					t.Errorf("modified message: %v is not %v", err, krb5.ErrModified)
+				}
+			})
+		}
+	})
+
+	t.Run("authzid", func(t *testing.T) {
+		clientCtx, serverCtx := start(t, append([]mech.Option{mech.AuthzID("other")}, credOpts...), nil)
+		err := exchange(clientCtx, serverCtx)
+		if reason, ok := sasl.ReasonOf(err); !ok || reason != sasl.ReasonAuthorizationDenied {
+			t.Errorf("ReasonOf(%v) = %s", err, reason)
+		}
+	})
+
+	t.Run("invalid-password", func(t *testing.T) {
+		clientCtx, serverCtx := start(t, []mech.Option{mech.Username(sasltest.Username), mech.Password("invalid")}, nil)
+		err := exchange(clientCtx, serverCtx)
+		if reason, ok := sasl.ReasonOf(err); !ok || reason != sasl.ReasonInvalidCredentials {
+			t.Errorf("ReasonOf(%v) = %s", err, reason)
+		}
+		if !errors.Is(err, krb5.ErrPreauthFailed) {
+			t.Errorf("%v is not %v", err, krb5.ErrPreauthFailed)
+		}
+	})
+
+	t.Run("no-acceptable-layer", func(t *testing.T) {
+		clientCtx, serverCtx := start(t,
+			append([]mech.Option{gssapi.ConfidentialityLayer}, credOpts...),
+			[]mech.Option{gssapi.NoSecurityLayer | gssapi.IntegrityLayer},
+		)
+		err := exchange(clientCtx, serverCtx)
+		if reason, ok := sasl.ReasonOf(err); !ok || reason != sasl.ReasonEncryptionRequired {
+			t.Errorf("ReasonOf(%v) = %s", err, reason)
+		}
+		if !errors.Is(err, gssapi.ErrNoSecurityLayer) {
+			t.Errorf("%v is not %v", err, gssapi.ErrNoSecurityLayer)
+		}
+		if serverCtx.Done() {
+			t.Error("server context is done")
+		}
+	})
+
+	t.Run("conf-flag", func(t *testing.T) {
+		for _, downgrade := range []bool{false, true} {
+			confMech := &confMechanism{Mechanism: krb5Mech, flags: nil, downgrade: downgrade}
+			clientCtx, err := gssapi.NewClient(confMech).Start(append([]mech.Option{service}, credOpts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			serverCtx, err := gssapi.NewServer(confMech).Start(service)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := exchange(clientCtx, serverCtx); err != nil {
+				t.Fatal(err)
+			}
+
+			// RFC 4752 section 3.1: The security layer messages of both peers are wrapped with conf_flag FALSE.
+
+			if !slices.Equal(confMech.flags, []bool{false, false}) {
+				t.Errorf("conf flags = %v", confMech.flags)
+			}
+
+			wrapped, err := clientCtx.(*gssapi.ClientContext).Wrap([]byte("hello"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = serverCtx.(*gssapi.ServerContext).Unwrap(wrapped)
+			if downgrade && !errors.Is(err, gssapi.ErrConfidentialityRequired) {
+				t.Errorf("%v is not %v", err, gssapi.ErrConfidentialityRequired)
+			}
+			if !downgrade && err != nil {
+				t.Error(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,119 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/auth"
+	"github.com/cybergarage/go-sasl/sasl/instrument"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasltest"
+)
+
+func TestInstrumentation(t *testing.T) {
+	metrics := instrument.NewMetrics()
+
+	client := sasl.NewClient()
+	server := sasltest.NewServer()
+	server.SetInstrumentation(metrics)
+
+	exchanges := []struct {
+		mech     string
+		password string
+		ok       bool
+	}{
+		{"PLAIN", sasltest.Password, true},
+		{"SCRAM-SHA-256", sasltest.Password, true},
+		{"SCRAM-SHA-256", "invalid-password", false},
+	}
+	for _, e := range exchanges {
+		clientMech, err := client.Mechanism(e.mech)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverMech, err := server.Mechanism(e.mech)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientCtx, err := clientMech.Start(mech.Username(sasltest.Username), mech.Password(e.password))
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverCtx, err := serverMech.Start()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := exchange(clientCtx, serverCtx); (err == nil) != e.ok {
+			t.Fatalf("%s : exchange() = %v", e.mech, err)
+		}
+	}
+
+	tests := []struct {
+		op     instrument.Operation
+		mech   string
+		result string
+		count  uint64
+	}{
+		{instrument.Exchange, "PLAIN", instrument.Success, 1},
+		{instrument.Exchange, "SCRAM-SHA-256", instrument.Success, 1},
+		{instrument.Exchange, "SCRAM-SHA-256", mech.ReasonInvalidCredentials.String(), 1},
+		{instrument.CredentialVerification, "PLAIN", instrument.Success, 1},
+		{instrument.CredentialLookup, "PLAIN", instrument.Success, 1},
+		{instrument.CredentialLookup, "SCRAM-SHA-256", instrument.Success, 4},
+		{instrument.Authorization, "PLAIN", instrument.Success, 1},
+		{instrument.Authorization, "SCRAM-SHA-256", instrument.Success, 1},
+		{instrument.Hi, "SCRAM-SHA-256", instrument.Success, 2},
+	}
+	for _, test := range tests {
+		if n := metrics.Count(test.op, test.mech, test.result); n != test.count {
+			t.Errorf("%s %s %s = %d, want %d", test.op, test.mech, test.result, n, test.count)
+		}
+	}
+}
+
+func TestInstrumentationCredentialStore(t *testing.T) {
+	server := sasltest.NewServer()
+	server.SetCredentialStore(auth.NewCredentialCache(server.CredentialStore()))
+	server.SetInstrumentation(instrument.NewMetrics())
+
+	// The credential store is returned as it is set, and the lookups are measured by the mechanisms.
+
+	if _, ok := server.CredentialStore().(auth.CredentialCache); !ok {
+		t.Errorf("%T is not a credential cache", server.CredentialStore())
+	}
+}
+
+func TestNoInstrumentationOverhead(t *testing.T) {
+	mgr := auth.NewManager()
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := mgr.Authorize(nil, sasltest.Username, sasltest.Username, "PLAIN"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("allocs = %f, want 0", allocs)
+	}
+
+	server := sasl.NewServer()
+	m, err := server.Mechanism("PLAIN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.(interface{ Unwrap() mech.Mechanism }); ok {
+		t.Errorf("%T is wrapped without instrumentation", m)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/auth"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasltest"
+)
+
+func TestLimiter(t *testing.T) {
+	const lockoutThreshold = 3
+
+	client := sasl.NewClient()
+
+	for _, mechName := range []string{"PLAIN", "SCRAM-SHA-256"} {
+		t.Run(mechName, func(t *testing.T) {
+			server := sasltest.NewServer()
+			server.SetLimiter(auth.NewMemoryLimiter(auth.WithLimiterLockout(lockoutThreshold, time.Minute)))
+
+			authenticate := func(password string) error {
+				clientMech, err := client.Mechanism(mechName)
+				if err != nil {
+					t.Fatal(err)
+				}
+				serverMech, err := server.Mechanism(mechName)
+				if err != nil {
+					t.Fatal(err)
+				}
+				clientCtx, err := clientMech.Start(mech.Username(sasltest.Username), mech.Password(password))
+				if err != nil {
+					t.Fatal(err)
+				}
+				serverCtx, err := serverMech.Start()
+				if err != nil {
+					t.Fatal(err)
+				}
+				return exchange(clientCtx, serverCtx)
+			}
+
+			for range lockoutThreshold {
+				if err := authenticate("invalid"); err == nil || errors.Is(err, auth.ErrTooManyAttempts) {
+					t.Fatalf("authenticate() = %v", err)
+				}
+			}
+
+			if err := authenticate(sasltest.Password); !errors.Is(err, auth.ErrTooManyAttempts) {
+				t.Fatalf("authenticate() = %v, want %v", err, auth.ErrTooManyAttempts)
+			}
+		})
+	}
+}
@@ -19,6 +19,8 @@ import (
 
 	"github.com/cybergarage/go-sasl/sasl"
 	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasl/mech/plugins/anonymous"
+	"github.com/cybergarage/go-sasl/sasl/mech/plugins/plain"
 	"github.com/cybergarage/go-sasl/sasltest"
 )
 
@@ -99,6 +101,37 @@ func TestMechanism(t *testing.T) {
 				t.Error("server context is not completed")
 			}
 
+			for _, ctx := range []mech.Context{clientCtx, serverCtx} {
+				resultCtx, ok := ctx.(mech.AuthResultContext)
+				if !ok {
+					// PLAIN and ANONYMOUS clients never learn the outcome of the exchange.
+					if ctx == serverCtx || (clientMech.Name() != plain.Type && clientMech.Name() != anonymous.Type) {
+						t.Errorf("%T does not expose the authentication result", ctx)
+					}
+					continue
+				}
+				result, ok := resultCtx.AuthResult()
+				if !ok {
+					t.Errorf("%T has no authentication result", ctx)
+					continue
+				}
+				if result.Mechanism() != clientMech.Name() {
+					t.Errorf("mechanism = %s, want %s", result.Mechanism(), clientMech.Name())
+				}
+				if clientMech.Name() == anonymous.Type {
+					if trace, _ := result.Attribute(anonymous.TraceAttr); trace != sasltest.Username {
+						t.Errorf("trace = %s, want %s", trace, sasltest.Username)
+					}
+					continue
+				}
+				if result.AuthcID() != sasltest.Username {
+					t.Errorf("authcid = %s, want %s", result.AuthcID(), sasltest.Username)
+				}
+				if result.AuthzID() != sasltest.Username {
+					t.Errorf("authzid = %s, want %s", result.AuthzID(), sasltest.Username)
+				}
+			}
+
 			err = clientCtx.Dispose()
 			if err != nil {
 				t.Error(err)
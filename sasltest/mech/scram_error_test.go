@@ -0,0 +1,98 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasl/scram"
+	"github.com/cybergarage/go-sasl/sasltest"
+)
+
+func TestSCRAMServerError(t *testing.T) {
+	client := sasl.NewClient()
+	server := sasltest.NewServer()
+
+	tests := []struct {
+		username string
+		password string
+		step     int
+		err      error
+	}{
+		{sasltest.Username, "invalid", 1, scram.ErrInvalidProof},
+		{"unknown", sasltest.Password, 0, scram.ErrUnknownUser},
+	}
+
+	for _, mechName := range []string{"SCRAM-SHA-1", "SCRAM-SHA-256", "SCRAM-SHA-512"} {
+		for _, test := range tests {
+			t.Run(mechName+"/"+test.err.Error(), func(t *testing.T) {
+				clientMech, err := client.Mechanism(mechName)
+				if err != nil {
+					t.Fatal(err)
+				}
+				serverMech, err := server.Mechanism(mechName)
+				if err != nil {
+					t.Fatal(err)
+				}
+				clientCtx, err := clientMech.Start(mech.Username(test.username), mech.Password(test.password))
+				if err != nil {
+					t.Fatal(err)
+				}
+				serverCtx, err := serverMech.Start()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var serverRes sasl.Response
+				for {
+					clientRes, err := clientCtx.Next(serverRes)
+					if err != nil {
+						if !errors.Is(err, test.err) {
+							t.Fatalf("client error = %v, want %v", err, test.err)
+						}
+						break
+					}
+					step := serverCtx.Step()
+					serverRes, err = serverCtx.Next(clientRes)
+					if err == nil {
+						continue
+					}
+					if !errors.Is(err, test.err) || step != test.step {
+						t.Fatalf("server error = %v at step %d, want %v at step %d", err, step, test.err, test.step)
+					}
+					if serverCtx.Done() {
+						t.Errorf("failed server context is completed")
+					}
+					if step == 0 {
+						if serverRes != nil {
+							t.Fatalf("server response = %v, want no server-error before the server-final-message", serverRes)
+						}
+						break
+					}
+					if serverRes == nil || serverRes.String() != "e="+test.err.Error() {
+						t.Fatalf("server response = %v, want e=%s", serverRes, test.err.Error())
+					}
+				}
+
+				if _, ok := serverCtx.(sasl.AuthResultContext).AuthResult(); ok {
+					t.Errorf("failed server context has an authentication result")
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasl/scram"
+	"github.com/cybergarage/go-sasl/sasltest"
+)
+
+// echoExtension sends the value in the specified message and records the values received from the peer.
+type echoExtension struct {
+	name      string
+	mandatory bool
+	sendIn    scram.MessageType
+	value     string
+	received  map[scram.MessageType]string
+}
+
+func newEchoExtension(name string, mandatory bool, sendIn scram.MessageType, value string) *echoExtension {
+	return &echoExtension{
+		name:      name,
+		mandatory: mandatory,
+		sendIn:    sendIn,
+		value:     value,
+		received:  map[scram.MessageType]string{},
+	}
+}
+
+func (ext *echoExtension) Name() string {
+	return ext.name
+}
+
+func (ext *echoExtension) Mandatory() bool {
+	return ext.mandatory
+}
+
+func (ext *echoExtension) Send(ctx scram.ExtensionContext, msgType scram.MessageType) (string, bool, error) {
+	if msgType != ext.sendIn {
+		return "", false, nil
+	}
+	return ext.value, true, nil
+}
+
+func (ext *echoExtension) Receive(ctx scram.ExtensionContext, msgType scram.MessageType, value string, ok bool) error {
+	if ok {
+		ext.received[msgType] = value
+	}
+	return nil
+}
+
+func TestSCRAMExtensions(t *testing.T) {
+	const mechName = "SCRAM-SHA-256"
+
+	client := sasl.NewClient()
+	server := sasltest.NewServer()
+
+	start := func(t *testing.T, clientExts []mech.Option, serverExts []mech.Option) (mech.Context, mech.Context) {
+		t.Helper()
+		clientMech, err := client.Mechanism(mechName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverMech, err := server.Mechanism(mechName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientOpts := append([]mech.Option{mech.Username(sasltest.Username), mech.Password(sasltest.Password)}, clientExts...)
+		clientCtx, err := clientMech.Start(clientOpts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverCtx, err := serverMech.Start(serverExts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return clientCtx, serverCtx
+	}
+
+	t.Run("exchange", func(t *testing.T) {
+		clientExt := newEchoExtension("x", true, scram.ClientFinalMessage, "from-client")
+		serverExt := newEchoExtension("x", false, scram.ServerFinalMessage, "from-server")
+		clientCtx, serverCtx := start(t, []mech.Option{clientExt}, []mech.Option{serverExt})
+		if err := exchange(clientCtx, serverCtx); err != nil {
+			t.Fatal(err)
+		}
+		if v := serverExt.received[scram.ClientFinalMessage]; v != "from-client" {
+			t.Errorf("server received %q", v)
+		}
+		if v := clientExt.received[scram.ServerFinalMessage]; v != "from-server" {
+			t.Errorf("client received %q", v)
+		}
+		result, ok := serverCtx.(sasl.AuthResultContext).AuthResult()
+		if !ok {
+			t.Fatal("no authentication result")
+		}
+		if v, ok := result.Attribute("x"); !ok || v != "from-client" {
+			t.Errorf("result attribute = %q", v)
+		}
+	})
+
+	t.Run("unknown-optional", func(t *testing.T) {
+		clientExt := newEchoExtension("y", false, scram.ClientFirstMessage, "ignored")
+		clientCtx, serverCtx := start(t, []mech.Option{clientExt}, nil)
+		if err := exchange(clientCtx, serverCtx); err != nil {
+			t.Fatal(err)
+		}
+		result, _ := serverCtx.(sasl.AuthResultContext).AuthResult()
+		if _, ok := result.Attribute("y"); ok {
+			t.Errorf("unknown attribute is not ignored")
+		}
+	})
+
+	negotiation := func() mech.Option {
+		return scram.NewExtensionRegistry(scram.WithExtensionNegotiation())
+	}
+
+	t.Run("mandatory-without-negotiation", func(t *testing.T) {
+		clientExt := newEchoExtension("y", true, scram.ClientFirstMessage, "required")
+		clientCtx, serverCtx := start(t, []mech.Option{clientExt}, nil)
+		clientRes, err := clientCtx.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasPrefix(clientRes.String(), "n,,m=") {
+			t.Errorf("client sends the reserved attribute without negotiation : %s", clientRes)
+		}
+		serverRes, err := serverCtx.Next(clientRes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := clientCtx.Next(serverRes); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("reserved-by-server", func(t *testing.T) {
+		clientExt := newEchoExtension("y", true, scram.ClientFirstMessage, "required")
+		clientCtx, serverCtx := start(t, []mech.Option{negotiation(), clientExt}, []mech.Option{newEchoExtension("y", false, scram.ServerFinalMessage, "")})
+		if err := exchange(clientCtx, serverCtx); !errors.Is(err, scram.ErrExtensionsNotSupported) {
+			t.Errorf("error = %v, want %v", err, scram.ErrExtensionsNotSupported)
+		}
+	})
+
+	t.Run("reserved-by-client", func(t *testing.T) {
+		serverExt := newEchoExtension("z", true, scram.ServerFirstMessage, "required")
+		clientCtx, serverCtx := start(t, []mech.Option{newEchoExtension("z", false, scram.ClientFinalMessage, "")}, []mech.Option{negotiation(), serverExt})
+		if err := exchange(clientCtx, serverCtx); !errors.Is(err, scram.ErrExtensionsNotSupported) {
+			t.Errorf("error = %v, want %v", err, scram.ErrExtensionsNotSupported)
+		}
+	})
+
+	t.Run("negotiated", func(t *testing.T) {
+		clientExt := newEchoExtension("x", true, scram.ClientFinalMessage, "from-client")
+		serverExt := newEchoExtension("x", true, scram.ServerFinalMessage, "from-server")
+		clientCtx, serverCtx := start(t, []mech.Option{negotiation(), clientExt}, []mech.Option{negotiation(), serverExt})
+		if err := exchange(clientCtx, serverCtx); err != nil {
+			t.Fatal(err)
+		}
+		if v := serverExt.received[scram.ClientFinalMessage]; v != "from-client" {
+			t.Errorf("server received %q", v)
+		}
+	})
+
+	t.Run("unsupported-by-server", func(t *testing.T) {
+		clientExt := newEchoExtension("y", true, scram.ClientFirstMessage, "required")
+		clientCtx, serverCtx := start(t, []mech.Option{negotiation(), clientExt}, []mech.Option{negotiation()})
+		clientRes, err := clientCtx.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverRes, err := serverCtx.Next(clientRes)
+		if !errors.Is(err, scram.ErrExtensionsNotSupported) {
+			t.Fatalf("server error = %v, want %v", err, scram.ErrExtensionsNotSupported)
+		}
+		if serverRes != nil {
+			t.Errorf("server response = %v, want no server-error before the server-final-message", serverRes)
+		}
+	})
+
+	t.Run("unsupported-by-client", func(t *testing.T) {
+		serverExt := newEchoExtension("z", true, scram.ServerFirstMessage, "required")
+		clientCtx, serverCtx := start(t, []mech.Option{negotiation()}, []mech.Option{negotiation(), serverExt})
+		if err := exchange(clientCtx, serverCtx); !errors.Is(err, scram.ErrExtensionsNotSupported) {
+			t.Errorf("error = %v, want %v", err, scram.ErrExtensionsNotSupported)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasl/scram"
+	"github.com/cybergarage/go-sasl/sasltest"
+)
+
+func TestSCRAMMockSecret(t *testing.T) {
+	const mechName = "SCRAM-SHA-256"
+
+	client := sasl.NewClient()
+	server := sasltest.NewServer()
+	secret := sasl.MockSecret("server-secret")
+
+	type exchangeResult struct {
+		salt           string
+		iterationCount int
+		serverRes      sasl.Response
+		err            error
+		step           int
+	}
+
+	authenticate := func(t *testing.T, username string, password string) exchangeResult {
+		t.Helper()
+		clientMech, err := client.Mechanism(mechName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverMech, err := server.Mechanism(mechName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientCtx, err := clientMech.Start(mech.Username(username), mech.Password(password))
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverCtx, err := serverMech.Start(secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var result exchangeResult
+		var serverRes sasl.Response
+		for !serverCtx.Done() && result.err == nil {
+			clientRes, err := clientCtx.Next(serverRes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result.step = serverCtx.Step()
+			serverRes, result.err = serverCtx.Next(clientRes)
+			if result.step == 0 && result.err == nil {
+				msg, err := scram.NewMessageFrom(serverRes)
+				if err != nil {
+					t.Fatal(err)
+				}
+				salt, _ := msg.Salt()
+				result.salt = string(salt)
+				result.iterationCount, _ = msg.IterationCount()
+			}
+		}
+		result.serverRes = serverRes
+		return result
+	}
+
+	known := authenticate(t, sasltest.Username, "invalid")
+	unknown := authenticate(t, "unknown", sasltest.Password)
+
+	for _, result := range []exchangeResult{known, unknown} {
+		if !errors.Is(result.err, scram.ErrInvalidProof) || result.step != 1 {
+			t.Errorf("error = %v at step %d, want %v at step 1", result.err, result.step, scram.ErrInvalidProof)
+		}
+		if result.serverRes == nil || result.serverRes.String() != "e="+scram.ErrInvalidProof.Error() {
+			t.Errorf("server response = %v", result.serverRes)
+		}
+	}
+
+	if known.iterationCount != unknown.iterationCount {
+		t.Errorf("iteration count %d != %d", known.iterationCount, unknown.iterationCount)
+	}
+	if known.salt == unknown.salt {
+		t.Errorf("salts are same for different users")
+	}
+	if again := authenticate(t, "unknown", sasltest.Password); again.salt != unknown.salt {
+		t.Errorf("mock salt is not deterministic")
+	}
+	if again := authenticate(t, sasltest.Username, "invalid"); again.salt != known.salt {
+		t.Errorf("salt of the known user is not deterministic")
+	}
+
+	if result := authenticate(t, sasltest.Username, sasltest.Password); result.err != nil {
+		t.Errorf("known user is not authenticated : %v", result.err)
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/auth"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasltest"
+)
+
+func TestSCRAMResume(t *testing.T) {
+	sealer, err := sasl.NewTokenSealer(bytes.Repeat([]byte{0x01}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientOpts := []mech.Option{
+		mech.Username(sasltest.Username),
+		mech.Password(sasltest.Password),
+	}
+
+	// resume suspends the context and resumes it on another node which shares the sealer key.
+	resume := func(t *testing.T, ctx mech.Context, m mech.Mechanism, opts ...mech.Option) mech.Context {
+		t.Helper()
+		suspendable, ok := ctx.(sasl.SuspendableContext)
+		if !ok {
+			t.Fatalf("%s context is not suspendable", m.Name())
+		}
+		token, err := suspendable.Suspend(sealer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resumable, ok := m.(sasl.ResumableMechanism)
+		if !ok {
+			t.Fatalf("%s is not resumable", m.Name())
+		}
+		resumed, err := resumable.Resume(sealer, token, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resumed.Step() != ctx.Step() {
+			t.Errorf("resumed step = %d, want %d", resumed.Step(), ctx.Step())
+		}
+		return resumed
+	}
+
+	for _, mechName := range []string{"SCRAM-SHA-1", "SCRAM-SHA-256", "SCRAM-SHA-512"} {
+		t.Run(mechName, func(t *testing.T) {
+			nodes := []*sasltest.Server{sasltest.NewServer(), sasltest.NewServer()}
+			serverMechs := make([]mech.Mechanism, len(nodes))
+			for n, node := range nodes {
+				m, err := node.Mechanism(mechName)
+				if err != nil {
+					t.Fatal(err)
+				}
+				serverMechs[n] = m
+			}
+			clientMech, err := sasl.NewClient().Mechanism(mechName)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			clientCtx, err := clientMech.Start(clientOpts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			serverCtx, err := serverMechs[0].Start()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Every step of the exchange runs on a resumed context.
+
+			var serverRes sasl.Response
+			for !serverCtx.Done() {
+				clientRes, err := clientCtx.Next(serverRes)
+				if err != nil {
+					t.Fatal(err)
+				}
+				clientCtx = resume(t, clientCtx, clientMech, clientOpts...)
+				serverRes, err = serverCtx.Next(clientRes)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !serverCtx.Done() {
+					serverCtx = resume(t, serverCtx, serverMechs[serverCtx.Step()%len(serverMechs)])
+				}
+			}
+			if _, err := clientCtx.Next(serverRes); err != nil {
+				t.Fatal(err)
+			}
+			if !clientCtx.Done() {
+				t.Error("client context is not completed")
+			}
+			result, ok := serverCtx.(sasl.AuthResultContext).AuthResult()
+			if !ok || result.AuthcID() != sasltest.Username {
+				t.Errorf("authentication result = %v", result)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		server := sasltest.NewServer()
+		sha256Mech, err := server.Mechanism("SCRAM-SHA-256")
+		if err != nil {
+			t.Fatal(err)
+		}
+		sha1Mech, err := server.Mechanism("SCRAM-SHA-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		serverCtx, err := sha256Mech.Start()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := serverCtx.(sasl.SuspendableContext).Suspend(sealer); err == nil {
+			t.Error("context is suspended before the first message")
+		}
+
+		clientMech, err := sasl.NewClient().Mechanism("SCRAM-SHA-256")
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientCtx, err := clientMech.Start(clientOpts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientRes, err := clientCtx.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := serverCtx.Next(clientRes); err != nil {
+			t.Fatal(err)
+		}
+		token, err := serverCtx.(sasl.SuspendableContext).Suspend(sealer)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := sha1Mech.(sasl.ResumableMechanism).Resume(sealer, token); !errors.Is(err, sasl.ErrInvalidToken) {
+			t.Errorf("other mechanism : %v", err)
+		}
+		tampered := bytes.Clone(token)
+		tampered[len(tampered)/2] ^= 0x01
+		_, err = sha256Mech.(sasl.ResumableMechanism).Resume(sealer, tampered)
+		if !errors.Is(err, sasl.ErrInvalidToken) {
+			t.Errorf("tampered token : %v", err)
+		}
+		if reason, _ := sasl.ReasonOf(err); reason != sasl.ReasonMalformed {
+			t.Errorf("reason = %v, want %v", reason, sasl.ReasonMalformed)
+		}
+	})
+	// suspended returns the token of the server context which is suspended after the server first message.
+	suspended := func(t *testing.T, serverMech mech.Mechanism, opts ...mech.Option) ([]byte, mech.Context) {
+		t.Helper()
+		clientMech, err := sasl.NewClient().Mechanism(serverMech.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientCtx, err := clientMech.Start(clientOpts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverCtx, err := serverMech.Start(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientRes, err := clientCtx.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverRes, err := serverCtx.Next(clientRes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := clientCtx.Next(serverRes); err != nil {
+			t.Fatal(err)
+		}
+		token, err := serverCtx.(sasl.SuspendableContext).Suspend(sealer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token, clientCtx
+	}
+
+	t.Run("replayed", func(t *testing.T) {
+		serverMech, err := sasltest.NewServer().Mechanism("SCRAM-SHA-256")
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, _ := suspended(t, serverMech)
+		if _, err := serverMech.(sasl.ResumableMechanism).Resume(sealer, token); err != nil {
+			t.Fatal(err)
+		}
+		for range 3 {
+			_, err := serverMech.(sasl.ResumableMechanism).Resume(sealer, token)
+			if !errors.Is(err, sasl.ErrTokenReplayed) {
+				t.Errorf("replayed token : %v", err)
+			}
+		}
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		server := sasltest.NewServer()
+		server.SetLimiter(auth.NewMemoryLimiter(auth.WithLimiterAddrRate(0.001, 1)))
+		serverMech, err := server.Mechanism("SCRAM-SHA-256")
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn := &auditConn{}
+		token, _ := suspended(t, serverMech, conn)
+		_, err = serverMech.(sasl.ResumableMechanism).Resume(sealer, token, conn)
+		if !errors.Is(err, auth.ErrTooManyAttempts) {
+			t.Errorf("resumed attempt over the limit : %v", err)
+		}
+	})
+}
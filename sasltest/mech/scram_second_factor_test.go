@@ -0,0 +1,148 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mech
+
+import (
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cybergarage/go-sasl/sasl"
+	"github.com/cybergarage/go-sasl/sasl/mech"
+	"github.com/cybergarage/go-sasl/sasl/otp"
+	"github.com/cybergarage/go-sasl/sasl/scram"
+	"github.com/cybergarage/go-sasl/sasltest"
+)
+
+func TestSCRAMSecondFactor(t *testing.T) {
+	const mechName = "SCRAM-SHA-256"
+
+	secret := []byte("12345678901234567890")
+	now := time.Unix(1111111111, 0)
+	clock := func() time.Time { return now }
+	code := otp.TOTP(sha1.New, secret, now, otp.DefaultPeriod, otp.DefaultDigits)
+
+	client := sasl.NewClient()
+	server := sasltest.NewServer()
+
+	authenticate := func(t *testing.T, verifier otp.Verifier, clientOpts ...mech.Option) error {
+		t.Helper()
+		clientMech, err := client.Mechanism(mechName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverMech, err := server.Mechanism(mechName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientOpts = append([]mech.Option{mech.Username(sasltest.Username), mech.Password(sasltest.Password)}, clientOpts...)
+		clientCtx, err := clientMech.Start(clientOpts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serverCtx, err := serverMech.Start(verifier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return exchange(clientCtx, serverCtx)
+	}
+
+	t.Run("not-required", func(t *testing.T) {
+		verifier := otp.NewTOTPVerifier(otp.WithVerifierClock(clock))
+		if err := authenticate(t, verifier); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("required", func(t *testing.T) {
+		verifier := otp.NewTOTPVerifier(
+			otp.WithVerifierSecret(sasltest.Username, secret),
+			otp.WithVerifierClock(clock),
+		)
+
+		if err := authenticate(t, verifier); !errors.Is(err, scram.ErrSecondFactorRequired) {
+			t.Errorf("without second factor : %v", err)
+		}
+		if err := authenticate(t, verifier, mech.SecondFactor("000000")); !errors.Is(err, scram.ErrInvalidSecondFactor) {
+			t.Errorf("invalid second factor : %v", err)
+		} else if reason, _ := mech.ReasonOf(err); reason != mech.ReasonInvalidCredentials {
+			t.Errorf("reason = %v, want %v", reason, mech.ReasonInvalidCredentials)
+		}
+		if err := authenticate(t, verifier, mech.SecondFactor(code)); err != nil {
+			t.Fatal(err)
+		}
+		if err := authenticate(t, verifier, mech.SecondFactor(code)); !errors.Is(err, otp.ErrReplayedCode) {
+			t.Errorf("replayed second factor : %v", err)
+		}
+	})
+
+	t.Run("wrong-password", func(t *testing.T) {
+		verifier := otp.NewTOTPVerifier(
+			otp.WithVerifierSecret(sasltest.Username, secret),
+			otp.WithVerifierClock(clock),
+		)
+		// The second factor is not consumed when the password is wrong.
+		err := authenticate(t, verifier, mech.Password("invalid"), mech.SecondFactor(code))
+		if !errors.Is(err, scram.ErrInvalidProof) {
+			t.Errorf("wrong password : %v", err)
+		}
+		if err := authenticate(t, verifier, mech.SecondFactor(code)); err != nil {
+			t.Error(err)
+		}
+	})
+	t.Run("mock-secret", func(t *testing.T) {
+		verifier := otp.NewTOTPVerifier(
+			otp.WithVerifierSecret(sasltest.Username, secret),
+			otp.WithVerifierClock(clock),
+		)
+		serverMech, err := server.Mechanism(mechName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// secondFactor returns true if the server-first-message for the user has the second factor attribute.
+		secondFactor := func(t *testing.T, username string) bool {
+			t.Helper()
+			serverCtx, err := serverMech.Start(verifier, sasl.MockSecret("server-secret"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer serverCtx.Dispose()
+			res, err := serverCtx.Next([]byte("n,,n=" + username + ",r=nonce"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return strings.Contains(res.String(), ","+scram.SecondFactorAttr+"=")
+		}
+
+		if !secondFactor(t, sasltest.Username) {
+			t.Errorf("no second factor for the enrolled user")
+		}
+		counts := map[bool]int{}
+		for i := range 32 {
+			username := fmt.Sprintf("unknown%d", i)
+			required := secondFactor(t, username)
+			if secondFactor(t, username) != required {
+				t.Errorf("second factor of %s is not deterministic", username)
+			}
+			counts[required]++
+		}
+		if counts[true] == 0 || counts[false] == 0 {
+			t.Errorf("second factors of unknown users = %v", counts)
+		}
+	})
+}
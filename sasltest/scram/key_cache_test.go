@@ -0,0 +1,152 @@
+// Copyright (C) 2024 The go-sasl Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scram
+
+import (
+	"encoding/base64"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/cybergarage/go-sasl/sasl/scram"
+)
+
+func TestKeyCache(t *testing.T) {
+	const ic = 4096
+	salt := []byte("key-cache-salt")
+
+	authenticate := func(opts ...scram.ClientOption) (*scram.Client, error) {
+		client, err := scram.NewClient(append([]scram.ClientOption{scram.WithClientUsername(Username)}, opts...)...)
+		if err != nil {
+			return nil, err
+		}
+		server, err := NewServer()
+		if err != nil {
+			return nil, err
+		}
+		err = server.SetOptions(
+			scram.WithServerHashFunc(scram.HashSHA256()),
+			scram.WithServerSaltString(base64.StdEncoding.EncodeToString(salt)),
+			scram.WithServerIterationCount(ic),
+		)
+		if err != nil {
+			return nil, err
+		}
+		clientFirstMsg, err := client.FirstMessage()
+		if err != nil {
+			return nil, err
+		}
+		serverFirstMsg, err := server.FirstMessageFrom(clientFirstMsg)
+		if err != nil {
+			return nil, err
+		}
+		clientFinalMsg, err := client.FinalMessageFrom(serverFirstMsg)
+		if err != nil {
+			return nil, err
+		}
+		serverFinalMsg, err := server.FinalMessageFrom(clientFinalMsg)
+		if err != nil {
+			return nil, err
+		}
+		return client, client.ValidateServerFinalMessage(serverFinalMsg)
+	}
+
+	t.Run("shared", func(t *testing.T) {
+		cache := scram.NewKeyCache()
+		if _, err := authenticate(scram.WithClientPassword(Password), scram.WithClientKeyCache(cache)); err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Go(func() {
+				client, err := authenticate(scram.WithClientPassword(Password), scram.WithClientKeyCache(cache))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if _, ok := client.Value(scram.SaltedPasswordID); ok {
+					t.Errorf("salted password is computed with the cached keys")
+				}
+			})
+		}
+		wg.Wait()
+
+		if _, err := authenticate(scram.WithClientPassword("invalid"), scram.WithClientKeyCache(cache)); err == nil {
+			t.Errorf("invalid password is authenticated with the cached keys")
+		}
+	})
+
+	t.Run("seeded", func(t *testing.T) {
+		hashFunc := scram.HashSHA256()
+		saltedPassword, err := scram.SaltedPassword(hashFunc, Password, salt, ic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cache := scram.NewKeyCache(scram.WithKeyCacheSize(1))
+		cache.SeedKeys(hashFunc, Username, salt, ic, scram.ClientKey(hashFunc, saltedPassword), scram.ServerKey(hashFunc, saltedPassword))
+		cache.SeedKeys(hashFunc, "other", salt, ic, []byte("other"), []byte("other"))
+
+		// Seeded keys are not evicted by the cached keys of other clients.
+		cache.SetKeys(hashFunc, "a", salt, ic, []byte("a"), []byte("a"))
+		cache.SetKeys(hashFunc, "b", salt, ic, []byte("b"), []byte("b"))
+
+		if _, err := authenticate(scram.WithClientKeyCache(cache)); err != nil {
+			t.Fatal(err)
+		}
+		if clientKey, _, ok := cache.SeededKeys(hashFunc, "other", salt, ic); !ok || string(clientKey) != "other" {
+			t.Errorf("seeded keys = %s, %v", clientKey, ok)
+		}
+
+		cache.Clear()
+		if _, err := authenticate(scram.WithClientKeyCache(cache)); !errors.Is(err, scram.ErrNoPassword) {
+			t.Errorf("client without password after the cache is cleared : %v", err)
+		}
+		if _, err := authenticate(); !errors.Is(err, scram.ErrNoPassword) {
+			t.Errorf("client without password and key cache : %v", err)
+		}
+	})
+
+	t.Run("eviction", func(t *testing.T) {
+		hashFunc := scram.HashSHA256()
+		cache := scram.NewKeyCache(scram.WithKeyCacheSize(1))
+		cache.SetKeys(hashFunc, "a", salt, ic, []byte("a"), []byte("a"))
+		cache.SetKeys(hashFunc, "b", salt, ic, []byte("b"), []byte("b"))
+		if _, _, ok := cache.Keys(hashFunc, "a", salt, ic); ok {
+			t.Errorf("least recently used keys are not evicted")
+		}
+		if _, _, ok := cache.Keys(scram.HashSHA1(), "b", salt, ic); ok {
+			t.Errorf("keys are shared between hash functions")
+		}
+		if _, _, ok := cache.Keys(hashFunc, "b", salt, ic+1); ok {
+			t.Errorf("keys are shared between iteration counts")
+		}
+		if clientKey, _, ok := cache.Keys(hashFunc, "b", salt, ic); !ok || string(clientKey) != "b" {
+			t.Errorf("keys = %s, %v", clientKey, ok)
+		}
+
+		cache = scram.NewKeyCache(scram.WithKeyCacheSize(2))
+		cache.SetKeys(hashFunc, "a", salt, ic, []byte("a"), []byte("a"))
+		cache.SetKeys(hashFunc, "b", salt, ic, []byte("b"), []byte("b"))
+		cache.Keys(hashFunc, "a", salt, ic)
+		cache.SetKeys(hashFunc, "c", salt, ic, []byte("c"), []byte("c"))
+		if _, _, ok := cache.Keys(hashFunc, "a", salt, ic); !ok {
+			t.Errorf("recently used keys are evicted")
+		}
+		if _, _, ok := cache.Keys(hashFunc, "b", salt, ic); ok {
+			t.Errorf("least recently used keys are not evicted")
+		}
+	})
+}